@@ -5,16 +5,24 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"goledger-challenge-besu/configs/app"
 	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/configs/cache"
 	"goledger-challenge-besu/configs/db"
 	"goledger-challenge-besu/configs/http"
 	"goledger-challenge-besu/configs/log"
+	"goledger-challenge-besu/configs/tracing"
 )
 
 func main() {
-	logConfig.Config()
+	if err := logConfig.Config(); err != nil {
+		slog.Error("Error loading log settings", "error", err)
+		os.Exit(1)
+	}
 
 	slog.Info("Starting the application...")
 	app, err := appConfig.New()
@@ -24,7 +32,25 @@ func main() {
 	}
 	slog.Info("Application started", "app", app.Name, "env", app.Env)
 
-	ctx := context.Background()
+	// the root context of the background workers (watchers, cleanups, transaction
+	// waits), canceled once the HTTP server is shut down
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracing, err := tracingConfig.New(&ctx)
+	if err != nil {
+		slog.Error("Error initializing tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		// the spans still buffered are exported, after the workers stopped
+		flush, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFlush()
+		if err := tracing.Shutdown(flush); err != nil {
+			slog.Error("Error exporting the last spans", "error", err)
+		}
+	}()
+	slog.Info("Tracing initialized", "enabled", tracing.Enabled())
 
 	slog.Info("Connecting to database...")
 	db, err := dbConfig.New(&ctx)
@@ -43,13 +69,23 @@ func main() {
 	}
 	slog.Info("Database migrated succesfully")
 
-	slog.Info("Connecting to Besu node...")
+	slog.Info("Connecting to Besu nodes...")
 	ethClient, err := besuConfig.New(&ctx)
 	if err != nil {
-		slog.Error("Erro connecting to besu node", "error", err)
+		slog.Error("Error connecting to Besu nodes", "error", err)
+		os.Exit(1)
 	}
 	defer ethClient.Close()
-	slog.Info("Besu node connected succesfully")
+	slog.Info("Besu nodes connected succesfully", "nodes", len(ethClient.Nodes()))
+
+	slog.Info("Connecting to cache...")
+	cache, err := cacheConfig.New(&ctx)
+	if err != nil {
+		slog.Error("Error initializing cache", "error", err)
+		os.Exit(1)
+	}
+	defer cache.Close()
+	slog.Info("Cache connected succesfully", "backend", cache.Stats().Backend)
 
 	slog.Info("Starting the HTTP server...")
 	http, err := httpConfig.New()
@@ -58,18 +94,41 @@ func main() {
 		os.Exit(1)
 	}
 
-	err = http.Route(&ctx, db, ethClient)
+	err = http.Route(&ctx, db, ethClient, cache)
 	if err != nil {
 		slog.Error("Error building the HTTP routes", "error", err)
 		os.Exit(1)
 	}
 
+	scheme := "http"
+	if http.TLS != nil {
+		scheme = "https"
+	}
 	fmt.Println()
-	slog.Info("HTTP Server running 💻", "address", fmt.Sprintf("http://%s", http.Address), "port", http.Port)
+	slog.Info("HTTP Server running 💻", "address", fmt.Sprintf("%s://%s", scheme, http.Address), "port", http.Port, "mtls", http.TLS != nil && http.TLS.ClientCAs != nil)
 	fmt.Println()
-	err = http.Serve()
-	if err != nil {
-		slog.Error("Error starting the HTTP server", "error", err)
-		os.Exit(1)
+
+	signals, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	served := make(chan error, 1)
+	go func() { served <- http.Serve() }()
+
+	select {
+	case err = <-served:
+		if err != nil {
+			slog.Error("Error starting the HTTP server", "error", err)
+			os.Exit(1)
+		}
+	case <-signals.Done():
+		stop() // a second signal kills the process
+		slog.Info("Shutting down the HTTP server...", "timeout", http.ShutdownTimeout)
+		shutdown, cancelShutdown := context.WithTimeout(context.Background(), http.ShutdownDelay+http.ShutdownTimeout)
+		defer cancelShutdown()
+		if err = http.Shutdown(shutdown); err != nil {
+			slog.Error("Error shutting down the HTTP server", "error", err)
+		}
 	}
+	// the workers stop, then the deferred closes run
+	cancel()
+	slog.Info("Application stopped")
 }
@@ -0,0 +1,34 @@
+package besuConfig
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// ReadABI loads the ABI of a contract from a Hardhat artifact (JSON file with an "abi" field).
+func ReadABI(abiPath string) (*abi.ABI, error) {
+	data, err := os.ReadFile(abiPath)
+	if err != nil {
+		slog.Error("Error reading contract abi file", "path", abiPath, "error", err.Error())
+		return nil, err
+	}
+	var contractDefinition struct {
+		Abi any `json:"abi"`
+	}
+	json.Unmarshal(data, &contractDefinition)
+	data, err = json.Marshal(contractDefinition.Abi)
+	if err != nil {
+		slog.Error("Error getting contract abi structure from abi file", "error", err.Error())
+		return nil, err
+	}
+	abi, err := abi.JSON(strings.NewReader(string(data)))
+	if err != nil {
+		slog.Error("Error converting contract abi json to string", "error", err.Error())
+		return nil, err
+	}
+	return &abi, nil
+}
@@ -2,22 +2,608 @@ package besuConfig
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"goledger-challenge-besu/configs/metrics"
+	"goledger-challenge-besu/configs/tracing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	RoutingRoundRobin = "round-robin"
+	RoutingLatency    = "latency"
+)
+
+var (
+	ErrNoHealthyNode    = errors.New("no healthy Besu node available")
+	ErrNodeNotConnected = errors.New("Besu node is not connected")
+	ErrUnknownNode      = errors.New("unknown Besu node")
 )
 
+// EthClient is a pool of Besu nodes. Reads are routed to a healthy node
+// (round-robin or lowest latency) and fail over to the next one when the node
+// itself fails; writes are sent to a single node. Lost connections are redialed
+// in background and subscriptions are re-established on the nodes that are back.
 type EthClient struct {
-	*ethclient.Client
+	nodes               []*Node
+	routing             string
+	maxBlockLag         uint64
+	healthCheckInterval time.Duration
+	requestTimeout      time.Duration
+	next                atomic.Uint64
+	ctx                 context.Context
+	cancel              context.CancelFunc
+}
+
+// Nodes returns a snapshot of the status of every node in the pool.
+func (c *EthClient) Nodes() []NodeStatus {
+	statuses := make([]NodeStatus, len(c.nodes))
+	for i, node := range c.nodes {
+		statuses[i] = node.Status()
+	}
+	return statuses
+}
+
+// Available reports if at least one node can serve requests right now.
+func (c *EthClient) Available() bool {
+	_, err := c.candidates()
+	return err == nil
+}
+
+func (c *EthClient) Close() {
+	c.cancel()
+	for _, node := range c.nodes {
+		node.close()
+	}
+}
+
+// candidates returns the healthy nodes in the order they should be tried.
+func (c *EthClient) candidates() ([]*Node, error) {
+	healthy := make([]*Node, 0, len(c.nodes))
+	for _, node := range c.nodes {
+		if node.isHealthy() {
+			healthy = append(healthy, node)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil, ErrNoHealthyNode
+	}
+
+	if c.routing == RoutingLatency {
+		sort.SliceStable(healthy, func(i, j int) bool {
+			return healthy[i].getLatency() < healthy[j].getLatency()
+		})
+		return healthy, nil
+	}
+	start := int((c.next.Add(1) - 1) % uint64(len(healthy)))
+	ordered := make([]*Node, 0, len(healthy))
+	ordered = append(ordered, healthy[start:]...)
+	return append(ordered, healthy[:start]...), nil
+}
+
+// isNodeFailure tells apart errors caused by the node (connection refused, timeouts, HTTP errors)
+// from errors the node answered with (reverts, not found), which would be the same on any node.
+func isNodeFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
+
+// nodeFailed ejects the node and starts reconnecting to it.
+func (c *EthClient) nodeFailed(node *Node, err error) {
+	node.markFailed(err)
+	c.reconnect(node)
+}
+
+// startRPC starts the span of a JSON-RPC call to a node. The node is identified by
+// its host only, its URL may hold credentials.
+func startRPC(ctx context.Context, method string, node *Node) (context.Context, trace.Span) {
+	host := ""
+	if parsed, err := url.Parse(node.URL); err == nil {
+		host = parsed.Host
+	}
+	return tracingConfig.Start(ctx, method, attribute.String("rpc.system", "jsonrpc"), semconv.RPCMethod(method), semconv.ServerAddress(host))
+}
+
+// observe records a JSON-RPC call in the metrics, with its outcome: ok, error when
+// the node answered with one, or failure when the node itself failed, and ends its span.
+func observe(ctx context.Context, span trace.Span, method string, start time.Time, err error) {
+	tracingConfig.End(span, err)
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+		if isNodeFailure(ctx, err) {
+			outcome = "failure"
+		}
+	}
+	metricsConfig.RPCRequests.WithLabelValues(method, outcome).Inc()
+	metricsConfig.RPCRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// read runs fn against the healthy nodes until one of them doesn't fail.
+// Each attempt is bounded by the request timeout, so a hanging node fails fast.
+func read[T any](ctx context.Context, c *EthClient, method string, fn func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	var result T
+	nodes, err := c.candidates()
+	if err != nil {
+		return result, err
+	}
+	for _, node := range nodes {
+		client := node.getClient()
+		attemptCtx, span := startRPC(ctx, method, node)
+		attemptCtx, cancel := context.WithTimeout(attemptCtx, c.requestTimeout)
+		start := time.Now()
+		result, err = fn(attemptCtx, client)
+		cancel()
+		observe(ctx, span, method, start, err)
+		if err == nil || !isNodeFailure(ctx, err) {
+			return result, err
+		}
+		slog.Warn("Besu node failed, failing over", "method", method, "url", node.URL, "error", err.Error())
+		c.nodeFailed(node, err)
+	}
+	return result, err
+}
+
+// write runs fn against a single healthy node, without failover.
+func write(ctx context.Context, c *EthClient, method string, fn func(context.Context, *ethclient.Client) error) error {
+	nodes, err := c.candidates()
+	if err != nil {
+		return err
+	}
+	attemptCtx, span := startRPC(ctx, method, nodes[0])
+	attemptCtx, cancel := context.WithTimeout(attemptCtx, c.requestTimeout)
+	defer cancel()
+	start := time.Now()
+	err = fn(attemptCtx, nodes[0].getClient())
+	observe(ctx, span, method, start, err)
+	if err != nil && isNodeFailure(ctx, err) {
+		c.nodeFailed(nodes[0], err)
+	}
+	return err
+}
+
+func (c *EthClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return read(ctx, c, "eth_chainId", func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.ChainID(ctx)
+	})
+}
+
+func (c *EthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return read(ctx, c, "eth_blockNumber", func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.BlockNumber(ctx)
+	})
+}
+
+func (c *EthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return read(ctx, c, "eth_getBlockByNumber", func(ctx context.Context, client *ethclient.Client) (*types.Header, error) {
+		return client.HeaderByNumber(ctx, number)
+	})
+}
+
+func (c *EthClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return read(ctx, c, "eth_getCode", func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *EthClient) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return read(ctx, c, "eth_getCode", func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.PendingCodeAt(ctx, account)
+	})
+}
+
+func (c *EthClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return read(ctx, c, "eth_call", func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (c *EthClient) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return read(ctx, c, "eth_call", func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.PendingCallContract(ctx, msg)
+	})
+}
+
+func (c *EthClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return read(ctx, c, "eth_getBalance", func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *EthClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return read(ctx, c, "eth_getTransactionCount", func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *EthClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return read(ctx, c, "eth_getTransactionCount", func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.PendingNonceAt(ctx, account)
+	})
+}
+
+func (c *EthClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return read(ctx, c, "eth_gasPrice", func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasPrice(ctx)
+	})
+}
+
+func (c *EthClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return read(ctx, c, "eth_maxPriorityFeePerGas", func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasTipCap(ctx)
+	})
+}
+
+func (c *EthClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return read(ctx, c, "eth_estimateGas", func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.EstimateGas(ctx, msg)
+	})
+}
+
+func (c *EthClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return read(ctx, c, "eth_getLogs", func(ctx context.Context, client *ethclient.Client) ([]types.Log, error) {
+		return client.FilterLogs(ctx, query)
+	})
+}
+
+func (c *EthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return read(ctx, c, "eth_getTransactionReceipt", func(ctx context.Context, client *ethclient.Client) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (c *EthClient) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
+	type found struct {
+		tx      *types.Transaction
+		pending bool
+	}
+	result, err := read(ctx, c, "eth_getTransactionByHash", func(ctx context.Context, client *ethclient.Client) (found, error) {
+		tx, pending, err := client.TransactionByHash(ctx, txHash)
+		return found{tx, pending}, err
+	})
+	return result.tx, result.pending, err
+}
+
+func (c *EthClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return write(ctx, c, "eth_sendRawTransaction", func(ctx context.Context, client *ethclient.Client) error {
+		return client.SendTransaction(ctx, tx)
+	})
+}
+
+// CallContext performs a raw JSON-RPC call, failing over like the other reads.
+func (c *EthClient) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	_, err := read(ctx, c, method, func(ctx context.Context, client *ethclient.Client) (struct{}, error) {
+		return struct{}{}, client.Client().CallContext(ctx, result, method, args...)
+	})
+	return err
+}
+
+// BatchCallContext sends a raw JSON-RPC batch, failing over like the other reads.
+// Errors of the single calls are reported in each rpc.BatchElem.
+func (c *EthClient) BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error {
+	_, err := read(ctx, c, "batch", func(ctx context.Context, client *ethclient.Client) (struct{}, error) {
+		return struct{}{}, client.Client().BatchCallContext(ctx, batch)
+	})
+	return err
+}
+
+// CallNode performs a raw JSON-RPC call on the node at the given index of the pool
+// (the order of BESU_URL), without failover. The node doesn't need to be healthy,
+// only connected, so it can be used to inspect the node that is lagging behind.
+func (c *EthClient) CallNode(ctx context.Context, index int, result any, method string, args ...any) error {
+	if index < 0 || index >= len(c.nodes) {
+		return ErrUnknownNode
+	}
+	node := c.nodes[index]
+	client := node.getClient()
+	if client == nil || node.isReconnecting() {
+		return ErrNodeNotConnected
+	}
+	callCtx, span := startRPC(ctx, method, node)
+	callCtx, cancel := context.WithTimeout(callCtx, c.requestTimeout)
+	defer cancel()
+	start := time.Now()
+	err := client.Client().CallContext(callCtx, result, method, args...)
+	observe(ctx, span, method, start, err)
+	if err != nil && isNodeFailure(ctx, err) {
+		c.nodeFailed(node, err)
+	}
+	return err
+}
+
+// SubscribeFilterLogs subscribes to the logs matching the query. The subscription
+// survives node restarts (see resubscribe) and requires WebSocket endpoints.
+func (c *EthClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return c.resubscribe(ctx, "logs", func(ctx context.Context, client *ethclient.Client) (ethereum.Subscription, error) {
+		return client.SubscribeFilterLogs(ctx, query, ch)
+	})
+}
+
+// SubscribeNewHead subscribes to the new chain heads. The subscription
+// survives node restarts (see resubscribe) and requires WebSocket endpoints.
+func (c *EthClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return c.resubscribe(ctx, "newHeads", func(ctx context.Context, client *ethclient.Client) (ethereum.Subscription, error) {
+		return client.SubscribeNewHead(ctx, ch)
+	})
+}
+
+// subscribe establishes the subscription on the first healthy node supporting notifications.
+func (c *EthClient) subscribe(ctx context.Context, fn func(context.Context, *ethclient.Client) (ethereum.Subscription, error)) (ethereum.Subscription, error) {
+	nodes, err := c.candidates()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		attemptCtx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+		subscription, subErr := fn(attemptCtx, node.getClient())
+		cancel()
+		if subErr == nil {
+			return subscription, nil
+		}
+		err = subErr
+		if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+			continue
+		}
+		if isNodeFailure(ctx, err) {
+			c.nodeFailed(node, err)
+		}
+	}
+	return nil, err
+}
+
+// resubscribe keeps a subscription established: when the node serving it goes
+// away, the subscription is re-established on a healthy node after a jittered
+// backoff. Only the first attempt is synchronous, so the caller gets an error
+// if the subscription cannot be established at all right now.
+func (c *EthClient) resubscribe(ctx context.Context, name string, fn func(context.Context, *ethclient.Client) (ethereum.Subscription, error)) (ethereum.Subscription, error) {
+	first, err := c.subscribe(ctx, fn)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		current := first
+		for attempt := 0; ; {
+			if current != nil {
+				select {
+				case err := <-current.Err():
+					current.Unsubscribe()
+					slog.Warn("Besu subscription lost, resubscribing", "subscription", name, "error", err)
+				case <-quit:
+					current.Unsubscribe()
+					return nil
+				case <-c.ctx.Done():
+					current.Unsubscribe()
+					return c.ctx.Err()
+				}
+			}
+
+			select {
+			case <-time.After(backoff(attempt)):
+			case <-quit:
+				return nil
+			case <-c.ctx.Done():
+				return c.ctx.Err()
+			}
+			current, err = c.subscribe(c.ctx, fn)
+			if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+				return err
+			}
+			if err != nil {
+				attempt++
+				slog.Warn("Error resubscribing to Besu node", "subscription", name, "attempt", attempt, "error", err.Error())
+				continue
+			}
+			attempt = 0
+			slog.Info("Besu subscription re-established", "subscription", name)
+		}
+	}), nil
+}
+
+// reconnect redials the node with jittered exponential backoff until it answers
+// the health probes again. It does nothing if the node is already reconnecting.
+func (c *EthClient) reconnect(node *Node) {
+	if !node.startReconnecting() {
+		return
+	}
+	slog.Warn("Besu node connection lost, reconnecting", "url", node.URL)
+
+	go func() {
+		for attempt := 0; ; attempt++ {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-time.After(backoff(attempt)):
+			}
+
+			err := node.connect(c.ctx)
+			if err == nil {
+				checkCtx, cancel := context.WithTimeout(c.ctx, c.requestTimeout)
+				err = node.check(checkCtx)
+				cancel()
+			}
+			if err != nil {
+				slog.Debug("Error reconnecting to Besu node", "url", node.URL, "attempt", attempt+1, "error", err.Error())
+				continue
+			}
+
+			node.setState(StateConnected)
+			// healthy again only after the next full check, which also verifies the block lag
+			slog.Info("Besu node reconnected", "url", node.URL, "attempts", attempt+1)
+			return
+		}
+	}()
+}
+
+// checkNodes probes every connected node and ejects the ones that are unreachable,
+// syncing, isolated (no peers in a multi-node pool) or lagging behind the
+// highest block seen by more than maxBlockLag blocks. Unreachable nodes are
+// handed to reconnect.
+func (c *EthClient) checkNodes(ctx context.Context) {
+	errs := make([]error, len(c.nodes))
+	var wg sync.WaitGroup
+	for i, node := range c.nodes {
+		if node.isReconnecting() {
+			errs[i] = ErrNodeNotConnected
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+			defer cancel()
+			errs[i] = node.check(checkCtx)
+		}()
+	}
+	wg.Wait()
+
+	var head uint64
+	for i, node := range c.nodes {
+		if status := node.Status(); errs[i] == nil && status.BlockNumber > head {
+			head = status.BlockNumber
+		}
+	}
+	if head > 0 {
+		metricsConfig.LatestBlock.Set(float64(head))
+	}
+
+	for i, node := range c.nodes {
+		wasHealthy := node.isHealthy()
+		status := node.Status()
+		reason := ""
+		switch {
+		case errs[i] != nil:
+			reason = errs[i].Error()
+		case status.Syncing:
+			reason = "node is syncing"
+		case head-status.BlockNumber > c.maxBlockLag:
+			reason = fmt.Sprintf("node is %d blocks behind", head-status.BlockNumber)
+		case status.PeerCount == 0 && len(c.nodes) > 1:
+			reason = "node has no peers"
+		}
+		node.setHealthy(reason == "", reason)
+
+		if wasHealthy && reason != "" {
+			slog.Warn("Besu node ejected from the pool", "url", node.URL, "reason", reason)
+		} else if !wasHealthy && reason == "" {
+			slog.Info("Besu node healthy in the pool", "url", node.URL, "block", status.BlockNumber, "latency", status.Latency.String())
+		}
+		if errs[i] != nil && isNodeFailure(ctx, errs[i]) {
+			c.reconnect(node)
+		}
+	}
+}
+
+func (c *EthClient) watch() {
+	ticker := time.NewTicker(c.healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkNodes(c.ctx)
+		}
+	}
+}
+
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	env := os.Getenv(key)
+	if env == "" {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(env)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, env)
+	}
+	return duration, nil
 }
 
 func New(ctx *context.Context) (*EthClient, error) {
-	client, err := ethclient.DialContext(*ctx, os.Getenv("BESU_URL"))
+	routing := os.Getenv("BESU_ROUTING")
+	if routing == "" {
+		routing = RoutingRoundRobin
+	}
+	if routing != RoutingRoundRobin && routing != RoutingLatency {
+		return nil, fmt.Errorf("invalid BESU_ROUTING %q (expected %q or %q)", routing, RoutingRoundRobin, RoutingLatency)
+	}
+
+	maxBlockLag := uint64(5)
+	if env := os.Getenv("BESU_MAX_BLOCK_LAG"); env != "" {
+		lag, err := strconv.ParseUint(env, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BESU_MAX_BLOCK_LAG: %w", err)
+		}
+		maxBlockLag = lag
+	}
+
+	healthCheckInterval, err := durationEnv("BESU_HEALTH_CHECK_INTERVAL", 5*time.Second)
 	if err != nil {
 		return nil, err
 	}
+	requestTimeout, err := durationEnv("BESU_REQUEST_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*Node
+	for _, url := range strings.Split(os.Getenv("BESU_URL"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			nodes = append(nodes, &Node{URL: url})
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("no Besu node configured (check BESU_URL)")
+	}
 
-	return &EthClient{
-		client,
-	}, nil
+	watchCtx, cancel := context.WithCancel(*ctx)
+	client := &EthClient{
+		nodes:               nodes,
+		routing:             routing,
+		maxBlockLag:         maxBlockLag,
+		healthCheckInterval: healthCheckInterval,
+		requestTimeout:      requestTimeout,
+		ctx:                 watchCtx,
+		cancel:              cancel,
+	}
+	for _, node := range nodes {
+		dialCtx, cancel := context.WithTimeout(watchCtx, requestTimeout)
+		err := node.connect(dialCtx)
+		cancel()
+		if err != nil {
+			slog.Error("Error dialing Besu node", "url", node.URL, "error", err.Error())
+			client.reconnect(node)
+			continue
+		}
+		node.setState(StateConnected)
+	}
+	client.checkNodes(watchCtx)
+	go client.watch()
+
+	if !client.Available() {
+		slog.Warn("No healthy Besu node at startup, requests will fail until one recovers", "nodes", len(nodes))
+	}
+	return client, nil
 }
@@ -0,0 +1,216 @@
+package besuConfig
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	StateConnected    = "connected"
+	StateReconnecting = "reconnecting"
+)
+
+// Node is one Besu RPC endpoint of the pool, with the state of its connection and last health check.
+type Node struct {
+	URL string
+
+	mu          sync.RWMutex
+	client      *ethclient.Client
+	state       string
+	stateSince  time.Time
+	reconnects  uint64
+	healthy     bool
+	blockNumber uint64
+	peerCount   uint64
+	syncing     bool
+	latency     time.Duration
+	lastError   string
+	checkedAt   time.Time
+}
+
+// NodeStatus is a snapshot of the state of a Node.
+type NodeStatus struct {
+	URL         string
+	State       string
+	StateSince  time.Time
+	Reconnects  uint64
+	Healthy     bool
+	BlockNumber uint64
+	PeerCount   uint64
+	Syncing     bool
+	Latency     time.Duration
+	LastError   string
+	CheckedAt   time.Time
+}
+
+func (n *Node) Status() NodeStatus {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return NodeStatus{
+		URL:         n.URL,
+		State:       n.state,
+		StateSince:  n.stateSince,
+		Reconnects:  n.reconnects,
+		Healthy:     n.healthy,
+		BlockNumber: n.blockNumber,
+		PeerCount:   n.peerCount,
+		Syncing:     n.syncing,
+		Latency:     n.latency,
+		LastError:   n.lastError,
+		CheckedAt:   n.checkedAt,
+	}
+}
+
+func (n *Node) getClient() *ethclient.Client {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.client
+}
+
+func (n *Node) isHealthy() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.healthy
+}
+
+func (n *Node) isReconnecting() bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.state == StateReconnecting
+}
+
+func (n *Node) getLatency() time.Duration {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.latency
+}
+
+func (n *Node) setState(state string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.state != state {
+		if n.state == StateReconnecting && state == StateConnected {
+			n.reconnects++
+		}
+		n.state = state
+		n.stateSince = time.Now()
+	}
+}
+
+// startReconnecting switches the node to the reconnecting state.
+// It returns false if the node was already reconnecting.
+func (n *Node) startReconnecting() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.state == StateReconnecting {
+		return false
+	}
+	n.state = StateReconnecting
+	n.stateSince = time.Now()
+	return true
+}
+
+// connect replaces the client of the node by a newly dialed one, closing the previous client.
+func (n *Node) connect(ctx context.Context) error {
+	client, err := ethclient.DialContext(ctx, n.URL)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	previous := n.client
+	n.client = client
+	n.mu.Unlock()
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+func (n *Node) close() {
+	if client := n.getClient(); client != nil {
+		client.Close()
+	}
+}
+
+// markFailed ejects the node until the next health check confirms it is back.
+func (n *Node) markFailed(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.healthy = false
+	n.lastError = err.Error()
+}
+
+func (n *Node) checkFailed(err error) error {
+	n.markFailed(err)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.checkedAt = time.Now()
+	return err
+}
+
+// check probes the node with eth_blockNumber, net_peerCount and eth_syncing.
+// It only records the probe results; the final health verdict depends on the
+// other nodes (block lag) and is set by setHealthy.
+func (n *Node) check(ctx context.Context) error {
+	client := n.getClient()
+	if client == nil {
+		return n.checkFailed(ErrNodeNotConnected)
+	}
+	start := time.Now()
+	blockNumber, err := client.BlockNumber(ctx)
+	latency := time.Since(start)
+	if err != nil {
+		return n.checkFailed(err)
+	}
+	peerCount, err := client.PeerCount(ctx)
+	if err != nil {
+		return n.checkFailed(err)
+	}
+	progress, err := client.SyncProgress(ctx)
+	if err != nil {
+		return n.checkFailed(err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.blockNumber = blockNumber
+	n.peerCount = peerCount
+	n.syncing = progress != nil
+	// exponentially weighted moving average, so a single slow probe doesn't flip the routing
+	if n.latency == 0 {
+		n.latency = latency
+	} else {
+		n.latency = (n.latency*7 + latency*3) / 10
+	}
+	n.lastError = ""
+	n.checkedAt = time.Now()
+	return nil
+}
+
+func (n *Node) setHealthy(healthy bool, reason string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.healthy = healthy
+	if reason != "" {
+		n.lastError = reason
+	}
+}
+
+// backoff returns the wait before the given (zero based) retry attempt:
+// exponential from 500ms up to 30s, randomized in its upper half so the clients of a
+// restarted node don't all come back at the same instant.
+func backoff(attempt int) time.Duration {
+	const (
+		base    = 500 * time.Millisecond
+		ceiling = 30 * time.Second
+	)
+	wait := ceiling
+	if attempt < 6 {
+		wait = min(base<<attempt, ceiling)
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
@@ -0,0 +1,144 @@
+package cacheConfig
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Store is a cache backend, holding raw values by key.
+type Store interface {
+	// Get returns the value of key, and false if it is missing or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value of key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Close() error
+}
+
+// Stats are the counters of a Cache since the start.
+type Stats struct {
+	Backend  string  `json:"backend"`
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	Errors   uint64  `json:"errors"`
+	HitRatio float64 `json:"hitRatio"`
+}
+
+// Cache is the cache of the on-chain reads, backed by an in-memory LRU or by a
+// Redis-protocol server (shared by the instances of the service).
+// A failing backend never fails a read: errors count as misses and are logged.
+type Cache struct {
+	store   Store
+	backend string
+	ttl     time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+}
+
+// NewCache wraps a Store, whose entries expire after ttl.
+func NewCache(store Store, backend string, ttl time.Duration) *Cache {
+	return &Cache{store: store, backend: backend, ttl: ttl}
+}
+
+// Get returns the cached value of key, and false on a miss.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool) {
+	value, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		slog.Warn("Error reading cache", "backend", c.backend, "key", key, "error", err.Error())
+		c.errors.Add(1)
+	}
+	if !ok || err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return value, true
+}
+
+// Set caches the value of key.
+func (c *Cache) Set(ctx context.Context, key string, value []byte) {
+	if err := c.store.Set(ctx, key, value, c.ttl); err != nil {
+		slog.Warn("Error writing cache", "backend", c.backend, "key", key, "error", err.Error())
+		c.errors.Add(1)
+	}
+}
+
+// Stats returns the hit and miss counters.
+func (c *Cache) Stats() Stats {
+	stats := Stats{
+		Backend: c.backend,
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Errors:  c.errors.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+func (c *Cache) Close() error {
+	return c.store.Close()
+}
+
+// New builds the cache selected by CACHE_URL:
+//   - empty or memory:// for the in-memory LRU of CACHE_SIZE entries (10000 by default);
+//   - redis://[user:password@]host:port[/db] (or rediss:// with TLS) for a Redis-protocol server;
+//   - off to disable the cache.
+//
+// The entries expire after CACHE_TTL (10m by default).
+func New(ctx *context.Context) (*Cache, error) {
+	ttl := 10 * time.Minute
+	if env := os.Getenv("CACHE_TTL"); env != "" {
+		duration, err := time.ParseDuration(env)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid CACHE_TTL %q", env)
+		}
+		ttl = duration
+	}
+
+	url := os.Getenv("CACHE_URL")
+	switch {
+	case url == "" || url == "memory://":
+		size := 10000
+		if env := os.Getenv("CACHE_SIZE"); env != "" {
+			parsed, err := strconv.Atoi(env)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid CACHE_SIZE %q", env)
+			}
+			size = parsed
+		}
+		return NewCache(newMemoryStore(size), "memory", ttl), nil
+	case url == "off":
+		return NewCache(noopStore{}, "off", ttl), nil
+	case strings.HasPrefix(url, "redis://") || strings.HasPrefix(url, "rediss://"):
+		store, err := newRedisStore(*ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		return NewCache(store, "redis", ttl), nil
+	}
+	return nil, fmt.Errorf("unsupported CACHE_URL %q, expected memory://, redis:// or off", url)
+}
+
+// noopStore is the Store of a disabled cache, every read misses.
+type noopStore struct{}
+
+func (noopStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (noopStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+func (noopStore) Close() error {
+	return nil
+}
@@ -0,0 +1,162 @@
+package cacheConfig
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// newCaches returns a cache of each backend, and a function moving their clock forward.
+func newCaches(t *testing.T, ttl time.Duration) map[string]struct {
+	cache   *Cache
+	advance func(time.Duration)
+} {
+	server := miniredis.RunT(t)
+	t.Setenv("CACHE_URL", "redis://"+server.Addr())
+	t.Setenv("CACHE_TTL", ttl.String())
+	ctx := context.Background()
+	redisCache, err := New(&ctx)
+	if err != nil {
+		t.Fatalf("New() redis error = %v", err)
+	}
+	t.Cleanup(func() { redisCache.Close() })
+
+	memory := newMemoryStore(2)
+	return map[string]struct {
+		cache   *Cache
+		advance func(time.Duration)
+	}{
+		"redis": {redisCache, server.FastForward},
+		"memory": {NewCache(memory, "memory", ttl), func(d time.Duration) {
+			// expires the entries by moving them back in time
+			memory.mu.Lock()
+			defer memory.mu.Unlock()
+			for _, key := range memory.entries.Keys() {
+				entry, _ := memory.entries.Peek(key)
+				entry.expiresAt = entry.expiresAt.Add(-d)
+				memory.entries.Add(key, entry)
+			}
+		}},
+	}
+}
+
+func TestCache(t *testing.T) {
+	for name, backend := range newCaches(t, time.Minute) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := backend.cache
+
+			if _, ok := cache.Get(ctx, "a"); ok {
+				t.Fatal("Get() of a missing key hit")
+			}
+			cache.Set(ctx, "a", []byte{1, 2})
+			value, ok := cache.Get(ctx, "a")
+			if !ok || len(value) != 2 || value[1] != 2 {
+				t.Fatalf("Get() = %v, %v, want [1 2]", value, ok)
+			}
+			// the cached value isn't shared with the callers
+			value[1] = 3
+			if value, _ = cache.Get(ctx, "a"); value[1] != 2 {
+				t.Errorf("Get() = %v after changing a returned value, want [1 2]", value)
+			}
+			// empty values are values too (e.g. a call to an account without code)
+			cache.Set(ctx, "empty", []byte{})
+			if _, ok := cache.Get(ctx, "empty"); !ok {
+				t.Error("Get() of an empty value missed")
+			}
+
+			backend.advance(2 * time.Minute)
+			if _, ok := cache.Get(ctx, "a"); ok {
+				t.Error("Get() of an expired key hit")
+			}
+
+			stats := cache.Stats()
+			if stats.Backend != name || stats.Hits != 3 || stats.Misses != 2 || stats.Errors != 0 || stats.HitRatio != 0.6 {
+				t.Errorf("Stats() = %+v, want 3 hits and 2 misses", stats)
+			}
+		})
+	}
+}
+
+func TestMemoryEviction(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCache(newMemoryStore(2), "memory", time.Minute)
+	cache.Set(ctx, "a", []byte{1})
+	cache.Set(ctx, "b", []byte{2})
+	cache.Get(ctx, "a") // b is now the least recently used
+	cache.Set(ctx, "c", []byte{3})
+	if _, ok := cache.Get(ctx, "b"); ok {
+		t.Error("least recently used entry not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(ctx, key); !ok {
+			t.Errorf("entry %s evicted", key)
+		}
+	}
+}
+
+func TestRedisDown(t *testing.T) {
+	server := miniredis.RunT(t)
+	t.Setenv("CACHE_URL", "redis://"+server.Addr())
+	ctx := context.Background()
+	cache, err := New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	server.Close()
+
+	// reads and writes degrade to misses
+	cache.Set(ctx, "a", []byte{1})
+	if _, ok := cache.Get(ctx, "a"); ok {
+		t.Error("Get() hit with the server down")
+	}
+	if stats := cache.Stats(); stats.Errors != 2 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 2 errors and 1 miss", stats)
+	}
+
+	// but the service doesn't start with an unreachable server
+	if _, err = New(&ctx); err == nil {
+		t.Error("New() with the server down, want an error")
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		url, ttl, size string
+		want           string
+		wantErr        bool
+	}{
+		{url: "", want: "memory"},
+		{url: "memory://", size: "10", ttl: "1s", want: "memory"},
+		{url: "off", want: "off"},
+		{url: "memory://", size: "0", wantErr: true},
+		{url: "memory://", ttl: "-1s", wantErr: true},
+		{url: "memcached://localhost", wantErr: true},
+		{url: "redis://localhost:1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Setenv("CACHE_URL", tt.url)
+		t.Setenv("CACHE_TTL", tt.ttl)
+		t.Setenv("CACHE_SIZE", tt.size)
+		ctx := context.Background()
+		cache, err := New(&ctx)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("New(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if err == nil && cache.Stats().Backend != tt.want {
+			t.Errorf("New(%q) backend = %s, want %s", tt.url, cache.Stats().Backend, tt.want)
+		}
+	}
+}
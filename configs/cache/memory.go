@@ -0,0 +1,53 @@
+package cacheConfig
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryStore is an in-memory LRU Store, local to the instance.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries lru.BasicLRU[string, memoryEntry]
+}
+
+func newMemoryStore(size int) *memoryStore {
+	return &memoryStore{entries: lru.NewBasicLRU[string, memoryEntry](size)}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		s.entries.Remove(key)
+		return nil, false, nil
+	}
+	return bytes.Clone(entry.value), true, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries.Add(key, memoryEntry{value: bytes.Clone(value), expiresAt: time.Now().Add(ttl)})
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries.Purge()
+	return nil
+}
@@ -3,34 +3,95 @@ package dbConfig
 import (
 	"context"
 	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-//go:embed migrations/*.sql
+// each backend has its own folder of migrations, as the SQL dialects differ
+//
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
 var migrationsFS embed.FS
 
+// Pool is the part of pgxpool.Pool used by the repositories, so they can run
+// against the SQLite backend and against dbMock.Pool in the tests.
+type Pool interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Ping(ctx context.Context) error
+	Close()
+}
+
+// Backend is a storage engine, chosen by the scheme of DATABASE_URL. The
+// repositories only see its Pool, so they run on every backend as long as
+// their SQL is portable.
+type Backend interface {
+	// Name identifies the backend, and its folder of migrations.
+	Name() string
+	// Open connects to the database of the url.
+	Open(ctx context.Context, url string) (Pool, error)
+	// Placeholder is the format of the query parameters in the backend's SQL.
+	Placeholder() squirrel.PlaceholderFormat
+	// MigrationDriver returns the golang-migrate driver of the database opened by Open.
+	MigrationDriver(url string, pool Pool) (database.Driver, error)
+	// Lock takes a lock on key until the transaction ends, so the transactions of
+	// every instance taking it run one at a time.
+	Lock(ctx context.Context, tx pgx.Tx, key int64) error
+}
+
+// backends maps the DATABASE_URL schemes to their Backend.
+var backends = map[string]Backend{
+	"postgres":   postgresBackend{},
+	"postgresql": postgresBackend{},
+	"sqlite":     sqliteBackend{},
+	"sqlite3":    sqliteBackend{},
+}
+
+// backendOf returns the Backend of a DATABASE_URL.
+func backendOf(url string) (Backend, error) {
+	scheme, _, found := strings.Cut(url, "://")
+	if backend, ok := backends[strings.ToLower(scheme)]; found && ok {
+		return backend, nil
+	}
+	return nil, fmt.Errorf("unsupported DATABASE_URL scheme %q, expected postgres:// or sqlite://", scheme)
+}
+
 type DB struct {
 	URL string
-	*pgxpool.Pool
+	Pool
 	QueryBuilder *squirrel.StatementBuilderType
+	Backend      Backend
 }
 
 func (db *DB) Migrate() error {
-	driver, err := iofs.New(migrationsFS, "migrations")
+	source, err := migrationsSource(db.Backend.Name())
 	if err != nil {
-		slog.Error("Error finding and opening migrations folder", "error", err)
 		return err
 	}
 
-	migrations, err := migrate.NewWithSourceInstance("iofs", driver, db.URL)
+	driver, err := db.Backend.MigrationDriver(db.URL, db.Pool)
+	if err != nil {
+		slog.Error("Error opening migrations database driver", "backend", db.Backend.Name(), "error", err)
+		return err
+	}
+
+	migrations, err := migrate.NewWithInstance("iofs", source, db.Backend.Name(), driver)
 	if err != nil {
 		slog.Error("Error generating migrations instance", "error", err)
 		return err
@@ -43,9 +104,103 @@ func (db *DB) Migrate() error {
 	return nil
 }
 
+// SchemaVersion reads the version of the last migration applied, from the
+// schema_migrations table of golang-migrate.
+// Returns:
+//   - The version, 0 when no migration was applied.
+//   - Whether the migration failed halfway (dirty).
+//   - An error if the table can't be read.
+func (db *DB) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return uint(version), dirty, nil
+}
+
+// LatestMigration returns the version of the last migration embedded in the
+// binary, the one Migrate brings the database to.
+func (db *DB) LatestMigration() (uint, error) {
+	source, err := migrationsSource(db.Backend.Name())
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// migrationsSource opens the migrations of a backend embedded in the binary.
+func migrationsSource(backend string) (source.Driver, error) {
+	driver, err := iofs.New(migrationsFS, path.Join("migrations", backend))
+	if err != nil {
+		slog.Error("Error finding and opening migrations folder", "error", err)
+		return nil, err
+	}
+	return driver, nil
+}
+
+// ErrorCode returns the SQLSTATE code of a Postgres error (e.g. "23505" for
+// unique violations), or an empty string if err doesn't come from Postgres.
+// The SQLite backend reports its constraint violations with the same codes.
 func (db *DB) ErrorCode(err error) string {
-	pgErr := err.(*pgconn.PgError)
-	return pgErr.Code
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// PoolStats are the statistics of the connections of a backend.
+type PoolStats struct {
+	Acquired      int           // connections in use
+	Idle          int           // connections open and idle
+	Total         int           // connections open
+	Max           int           // maximum of connections open, 0 when unlimited
+	EmptyAcquires int64         // acquires that waited for a connection
+	AcquireWait   time.Duration // total time waited by those acquires
+}
+
+// statsPool is implemented by the pools reporting their statistics.
+type statsPool interface {
+	Stats() PoolStats
+}
+
+// Stats returns the statistics of the connections of the pool.
+func (db *DB) Stats() PoolStats {
+	switch pool := db.Pool.(type) {
+	case *pgxpool.Pool:
+		stat := pool.Stat()
+		return PoolStats{
+			Acquired:      int(stat.AcquiredConns()),
+			Idle:          int(stat.IdleConns()),
+			Total:         int(stat.TotalConns()),
+			Max:           int(stat.MaxConns()),
+			EmptyAcquires: stat.EmptyAcquireCount(),
+			AcquireWait:   stat.EmptyAcquireWaitTime(),
+		}
+	case statsPool:
+		return pool.Stats()
+	default:
+		return PoolStats{}
+	}
 }
 
 func (db *DB) Close() {
@@ -55,20 +210,27 @@ func (db *DB) Close() {
 func New(ctx *context.Context) (*DB, error) {
 	url := os.Getenv("DATABASE_URL")
 
-	db, err := pgxpool.New(*ctx, url)
+	backend, err := backendOf(url)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := backend.Open(*ctx, url)
 	if err != nil {
 		return nil, err
 	}
 	err = db.Ping(*ctx)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	queryBuilder := squirrel.StatementBuilder.PlaceholderFormat(backend.Placeholder())
 
 	return &DB{
 		url,
 		db,
-		&psql,
+		&queryBuilder,
+		backend,
 	}, nil
 }
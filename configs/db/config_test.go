@@ -0,0 +1,159 @@
+package dbConfig
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var backendNames = []string{"postgres", "sqlite"}
+
+// migrationFiles returns the up and down migrations of a backend, in version order.
+func migrationFiles(t *testing.T, backend string) (ups, downs []string) {
+	t.Helper()
+	names, err := fs.Glob(migrationsFS, path.Join("migrations", backend, "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		content, err := fs.ReadFile(migrationsFS, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			ups = append(ups, string(content))
+		case strings.HasSuffix(name, ".down.sql"):
+			downs = append(downs, string(content))
+		default:
+			t.Errorf("migration %s is neither .up.sql nor .down.sql", name)
+		}
+	}
+	return ups, downs
+}
+
+// TestMigrateUpDown runs every migration up then down on the stub driver of
+// golang-migrate, which records the executed scripts instead of running them.
+func TestMigrateUpDown(t *testing.T) {
+	var versions []int
+	for _, backend := range backendNames {
+		t.Run(backend, func(t *testing.T) {
+			versions = append(versions, migrateUpDown(t, backend))
+		})
+	}
+	// the backends must stay on the same schema version
+	if len(slices.Compact(slices.Clone(versions))) != 1 {
+		t.Errorf("migrations versions of %v = %v, want the same", backendNames, versions)
+	}
+}
+
+func migrateUpDown(t *testing.T, backend string) int {
+	ups, downs := migrationFiles(t, backend)
+	if len(ups) == 0 || len(ups) != len(downs) {
+		t.Fatalf("%d up and %d down migrations, want the same number", len(ups), len(downs))
+	}
+
+	source, err := migrationsSource(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	driver, err := stub.WithInstance(nil, &stub.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := migrate.NewWithInstance("iofs", source, "stub", driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = migrations.Up(); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if version, dirty, err := migrations.Version(); err != nil || dirty || int(version) != len(ups) {
+		t.Errorf("Version() after Up = %d, dirty %v, %v, want %d", version, dirty, err, len(ups))
+	}
+	if err = migrations.Up(); !errors.Is(err, migrate.ErrNoChange) {
+		t.Errorf("second Up() error = %v, want %v", err, migrate.ErrNoChange)
+	}
+
+	if err = migrations.Down(); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if _, _, err = migrations.Version(); !errors.Is(err, migrate.ErrNilVersion) {
+		t.Errorf("Version() after Down error = %v, want %v", err, migrate.ErrNilVersion)
+	}
+
+	// the ups in order, then the downs in reverse order
+	want := slices.Clone(ups)
+	for i := len(downs) - 1; i >= 0; i-- {
+		want = append(want, downs[i])
+	}
+	if !driver.(*stub.Stub).EqualSequence(want) {
+		t.Errorf("executed migrations = %q, want %q", driver.(*stub.Stub).MigrationSequence, want)
+	}
+	return len(ups)
+}
+
+var (
+	createRegexp  = regexp.MustCompile(`(?i)CREATE\s+(?:OR\s+REPLACE\s+)?(?:UNIQUE\s+)?(TABLE|INDEX|FUNCTION|TRIGGER)\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)`)
+	dropRegexp    = regexp.MustCompile(`(?i)DROP\s+(TABLE|INDEX|FUNCTION|TRIGGER)\s+(?:IF\s+EXISTS\s+)?(\w+)`)
+	rebuildRegexp = regexp.MustCompile(`(?i)ALTER\s+TABLE\s+\w+\s+RENAME\s+TO\s+\w+`)
+)
+
+func objects(re *regexp.Regexp, sql string) []string {
+	var objects []string
+	for _, match := range re.FindAllStringSubmatch(sql, -1) {
+		objects = append(objects, strings.ToUpper(match[1])+" "+strings.ToLower(match[2]))
+	}
+	return objects
+}
+
+// TestMigrationsSymmetry checks that each down migration drops, in reverse order,
+// exactly the objects created by its up migration. A table rebuild (the way SQLite
+// alters a constraint) must be undone by another rebuild of the same objects.
+func TestMigrationsSymmetry(t *testing.T) {
+	for _, backend := range backendNames {
+		ups, downs := migrationFiles(t, backend)
+		for i := range ups {
+			if rebuildRegexp.MatchString(ups[i]) {
+				created, recreated := objects(createRegexp, ups[i]), objects(createRegexp, downs[i])
+				if !rebuildRegexp.MatchString(downs[i]) || !slices.Equal(created, recreated) {
+					t.Errorf("%s migration %d: down rebuilds %v, want %v", backend, i+1, recreated, created)
+				}
+				continue
+			}
+			created := objects(createRegexp, ups[i])
+			dropped := objects(dropRegexp, downs[i])
+			slices.Reverse(created)
+			if !slices.Equal(created, dropped) {
+				t.Errorf("%s migration %d: down drops %v, want %v", backend, i+1, dropped, created)
+			}
+		}
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	db := &DB{}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&pgconn.PgError{Code: "23505"}, "23505"},
+		{errors.Join(errors.New("wrapped"), &pgconn.PgError{Code: "23514"}), "23514"},
+		{errors.New("connection reset by peer"), ""},
+	}
+	for _, tt := range tests {
+		if got := db.ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package dbMock provides an in-process mock of the Postgres pool (dbConfig.Pool),
+// so the repositories can be tested without a live database. Like sqlmock, the
+// test declares the statements it expects, in order, with their arguments and
+// results, and checks at the end that all of them were executed.
+package dbMock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+
+	"goledger-challenge-besu/configs/db"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Arg matches a statement argument, for the arguments that can't be compared with reflect.DeepEqual.
+type Arg func(actual any) bool
+
+// AnyArg matches any argument.
+func AnyArg() Arg {
+	return func(any) bool { return true }
+}
+
+type kind string
+
+const (
+	kindExec     kind = "Exec"
+	kindQuery    kind = "Query"
+	kindBegin    kind = "Begin"
+	kindCommit   kind = "Commit"
+	kindRollback kind = "Rollback"
+)
+
+// Expectation is a statement expected by the Pool.
+type Expectation struct {
+	kind    kind
+	sql     *regexp.Regexp
+	args    []any
+	columns []string
+	rows    [][]any
+	tag     pgconn.CommandTag
+	err     error
+}
+
+// WithArgs sets the expected arguments, compared with reflect.DeepEqual unless they are an Arg.
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.args = args
+	return e
+}
+
+// WillReturnRows sets the rows returned by a query.
+func (e *Expectation) WillReturnRows(columns []string, rows ...[]any) *Expectation {
+	e.columns = columns
+	e.rows = rows
+	return e
+}
+
+// WillReturnResult sets the command tag returned by an exec (e.g. "INSERT 0 1").
+func (e *Expectation) WillReturnResult(tag string) *Expectation {
+	e.tag = pgconn.NewCommandTag(tag)
+	return e
+}
+
+// WillReturnError makes the statement fail with err.
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) String() string {
+	if e.sql == nil {
+		return string(e.kind)
+	}
+	return fmt.Sprintf("%s %q %v", e.kind, e.sql.String(), e.args)
+}
+
+// Pool is a mock of dbConfig.Pool. It is safe for concurrent use, but the
+// statements must arrive in the order of the expectations.
+type Pool struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	errs         []error
+}
+
+// New returns an empty Pool.
+func New() *Pool {
+	return &Pool{}
+}
+
+// NewDB returns a dbConfig.DB backed by the pool, with the query builder of the application.
+func NewDB(pool *Pool) *dbConfig.DB {
+	psql := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar)
+	return &dbConfig.DB{Pool: pool, QueryBuilder: &psql}
+}
+
+func (p *Pool) expect(kind kind, sqlRegexp string) *Expectation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e := &Expectation{kind: kind}
+	if sqlRegexp != "" {
+		e.sql = regexp.MustCompile(sqlRegexp)
+	}
+	p.expectations = append(p.expectations, e)
+	return e
+}
+
+// ExpectExec expects an Exec whose SQL matches the regular expression.
+func (p *Pool) ExpectExec(sqlRegexp string) *Expectation {
+	return p.expect(kindExec, sqlRegexp)
+}
+
+// ExpectQuery expects a Query or QueryRow whose SQL matches the regular expression.
+func (p *Pool) ExpectQuery(sqlRegexp string) *Expectation {
+	return p.expect(kindQuery, sqlRegexp)
+}
+
+// ExpectBegin expects a transaction to be started.
+func (p *Pool) ExpectBegin() *Expectation {
+	return p.expect(kindBegin, "")
+}
+
+// ExpectCommit expects the transaction to be committed.
+func (p *Pool) ExpectCommit() *Expectation {
+	return p.expect(kindCommit, "")
+}
+
+// ExpectRollback expects the transaction to be rolled back.
+func (p *Pool) ExpectRollback() *Expectation {
+	return p.expect(kindRollback, "")
+}
+
+// ExpectationsWereMet returns an error if a statement didn't match its expectation,
+// or if some expectations were not executed.
+func (p *Pool) ExpectationsWereMet() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	errs := p.errs
+	for _, e := range p.expectations {
+		errs = append(errs, fmt.Errorf("expectation not executed: %s", e))
+	}
+	return errors.Join(errs...)
+}
+
+// next pops the next expectation, checking it matches the statement.
+func (p *Pool) next(kind kind, sql string, args []any) (*Expectation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	err := func() error {
+		if len(p.expectations) == 0 {
+			return fmt.Errorf("unexpected %s %q %v", kind, sql, args)
+		}
+		e := p.expectations[0]
+		if e.kind != kind {
+			return fmt.Errorf("unexpected %s %q, expected %s", kind, sql, e)
+		}
+		if e.sql != nil && !e.sql.MatchString(sql) {
+			return fmt.Errorf("%s %q doesn't match the expected %s", kind, sql, e)
+		}
+		if e.args != nil && !matchArgs(e.args, args) {
+			return fmt.Errorf("%s %q with arguments %v, expected %s", kind, sql, args, e)
+		}
+		return nil
+	}()
+	if err != nil {
+		p.errs = append(p.errs, err)
+		return nil, err
+	}
+	e := p.expectations[0]
+	p.expectations = p.expectations[1:]
+	return e, nil
+}
+
+func matchArgs(expected, actual []any) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		if match, ok := expected[i].(Arg); ok {
+			if !match(actual[i]) {
+				return false
+			}
+		} else if !reflect.DeepEqual(expected[i], actual[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Pool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	e, err := p.next(kindExec, sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return e.tag, e.err
+}
+
+func (p *Pool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	e, err := p.next(kindQuery, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &rows{columns: e.columns, rows: e.rows, index: -1}, nil
+}
+
+func (p *Pool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	e, err := p.next(kindQuery, sql, args)
+	if err != nil {
+		return &row{err: err}
+	}
+	if e.err != nil {
+		return &row{err: e.err}
+	}
+	if len(e.rows) == 0 {
+		return &row{err: pgx.ErrNoRows}
+	}
+	return &row{values: e.rows[0]}
+}
+
+func (p *Pool) Begin(ctx context.Context) (pgx.Tx, error) {
+	e, err := p.next(kindBegin, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &tx{pool: p}, nil
+}
+
+func (p *Pool) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (p *Pool) Close() {}
+
+var _ dbConfig.Pool = (*Pool)(nil)
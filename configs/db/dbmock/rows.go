@@ -0,0 +1,160 @@
+package dbMock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// scan assigns the values to the destinations, converting between compatible types
+// (e.g. an int64 column into an uint64), as pgx does.
+func scan(values []any, dest []any) error {
+	if len(values) != len(dest) {
+		return fmt.Errorf("scanning %d values into %d destinations", len(values), len(dest))
+	}
+	for i, value := range values {
+		target := reflect.ValueOf(dest[i])
+		if target.Kind() != reflect.Pointer || target.IsNil() {
+			return fmt.Errorf("destination %d is not a pointer", i)
+		}
+		target = target.Elem()
+		if value == nil {
+			target.SetZero()
+			continue
+		}
+		source := reflect.ValueOf(value)
+		// nullable columns are scanned into pointers
+		if target.Kind() == reflect.Pointer && source.Type() != target.Type() {
+			pointer := reflect.New(target.Type().Elem())
+			if err := scan([]any{value}, []any{pointer.Interface()}); err != nil {
+				return err
+			}
+			target.Set(pointer)
+			continue
+		}
+		switch {
+		case source.Type().AssignableTo(target.Type()):
+			target.Set(source)
+		case source.Type().ConvertibleTo(target.Type()):
+			target.Set(source.Convert(target.Type()))
+		default:
+			return fmt.Errorf("can't scan %T into %s", value, target.Type())
+		}
+	}
+	return nil
+}
+
+type row struct {
+	values []any
+	err    error
+}
+
+func (r *row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	return scan(r.values, dest)
+}
+
+type rows struct {
+	columns []string
+	rows    [][]any
+	index   int
+	err     error
+}
+
+func (r *rows) Close() {}
+
+func (r *rows) Err() error {
+	return r.err
+}
+
+func (r *rows) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag(fmt.Sprintf("SELECT %d", len(r.rows)))
+}
+
+func (r *rows) FieldDescriptions() []pgconn.FieldDescription {
+	fields := make([]pgconn.FieldDescription, len(r.columns))
+	for i, column := range r.columns {
+		fields[i].Name = column
+	}
+	return fields
+}
+
+func (r *rows) Next() bool {
+	r.index++
+	return r.index < len(r.rows)
+}
+
+func (r *rows) Scan(dest ...any) error {
+	if r.index < 0 || r.index >= len(r.rows) {
+		return errors.New("scan called without a row")
+	}
+	if err := scan(r.rows[r.index], dest); err != nil {
+		r.err = err
+		return err
+	}
+	return nil
+}
+
+func (r *rows) Values() ([]any, error) {
+	return r.rows[r.index], nil
+}
+
+func (r *rows) RawValues() [][]byte {
+	return nil
+}
+
+func (r *rows) Conn() *pgx.Conn {
+	return nil
+}
+
+// tx is a transaction of the mock Pool: its statements are checked against the
+// expectations of the pool, in order with the other statements.
+type tx struct {
+	pgx.Tx
+	pool *Pool
+	done bool
+}
+
+func (t *tx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return t.pool.Exec(ctx, sql, args...)
+}
+
+func (t *tx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.pool.Query(ctx, sql, args...)
+}
+
+func (t *tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.pool.QueryRow(ctx, sql, args...)
+}
+
+func (t *tx) Commit(ctx context.Context) error {
+	if t.done {
+		return pgx.ErrTxClosed
+	}
+	t.done = true
+	e, err := t.pool.next(kindCommit, "", nil)
+	if err != nil {
+		return err
+	}
+	return e.err
+}
+
+// Rollback only expects a rollback while the transaction is open, so the usual
+// deferred Rollback after a Commit is a no-op, like in pgx.
+func (t *tx) Rollback(ctx context.Context) error {
+	if t.done {
+		return pgx.ErrTxClosed
+	}
+	t.done = true
+	e, err := t.pool.next(kindRollback, "", nil)
+	if err != nil {
+		return err
+	}
+	return e.err
+}
@@ -0,0 +1,24 @@
+CREATE TABLE signer_transactions (
+    signer_transaction_id BIGSERIAL PRIMARY KEY,
+    hash VARCHAR(66) NOT NULL UNIQUE,
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR(66) NOT NULL,
+    signer VARCHAR(42) NOT NULL,
+    recipient VARCHAR(42), -- null on contract creation
+    nonce BIGINT NOT NULL,
+    value NUMERIC(78, 0) NOT NULL,
+    method VARCHAR(255), -- decoded call (e.g. set(42)) when the ABI is known
+    status SMALLINT NOT NULL,
+    gas_used BIGINT NOT NULL,
+    mined_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_signer_transactions_signer ON signer_transactions(signer, block_number DESC);
+
+-- last block scanned by the signer transactions worker (single row)
+CREATE TABLE signer_scan_state (
+    signer_scan_state_id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (signer_scan_state_id = 1),
+    last_block BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
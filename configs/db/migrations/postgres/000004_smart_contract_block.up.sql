@@ -0,0 +1,4 @@
+-- block of the chain the value was read at, a sync from an older block never
+-- overwrites the value (rows synced before this migration are at block 0)
+ALTER TABLE smart_contracts ADD COLUMN block_number BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE smart_contracts ADD COLUMN block_hash VARCHAR(66) NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- requests counted against the daily quotas, per client, route and day (UTC)
+CREATE TABLE client_quotas (
+    client VARCHAR(255) NOT NULL, -- key:<api key id>, jwt:<subject> or ip:<address>
+    route VARCHAR(255) NOT NULL, -- method and path, e.g. POST /api/v1/smart-contract/set-value
+    day DATE NOT NULL,
+    used BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (client, route, day)
+);
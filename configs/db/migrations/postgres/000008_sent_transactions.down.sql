@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sent_transactions_state;
+DROP TABLE IF EXISTS sent_transactions;
@@ -0,0 +1,4 @@
+DELETE FROM sent_transactions WHERE state = 'dropped';
+ALTER TABLE sent_transactions DROP CONSTRAINT IF EXISTS sent_transactions_state_check;
+ALTER TABLE sent_transactions ADD CONSTRAINT sent_transactions_state_check
+    CHECK (state IN ('pending', 'mined', 'reverted'));
@@ -0,0 +1,42 @@
+-- append-only log of the state-changing operations, for compliance. Each event holds
+-- the hash of the previous one and its own, so a change to the table breaks the chain
+CREATE TABLE audit_events (
+    audit_event_id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    action VARCHAR(64) NOT NULL, -- e.g. smart-contract.set-value
+    actor VARCHAR(255) NOT NULL, -- api key name or token subject, empty for anonymous callers
+    auth_method VARCHAR(16) NOT NULL, -- api_key, jwt or anonymous
+    role VARCHAR(16) NOT NULL,
+    client_ip VARCHAR(64) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    status_code INTEGER NOT NULL,
+    contract VARCHAR(42) NOT NULL DEFAULT '',
+    value TEXT NOT NULL DEFAULT '',
+    signer VARCHAR(42) NOT NULL DEFAULT '',
+    tx_hash VARCHAR(66) NOT NULL DEFAULT '',
+    details TEXT NOT NULL DEFAULT '{}', -- JSON object of the other attributes of the action
+    prev_hash VARCHAR(64) NOT NULL UNIQUE, -- empty for the first event; unique, so the chain can't fork
+    hash VARCHAR(64) NOT NULL UNIQUE -- SHA-256 of prev_hash and of the event
+);
+
+CREATE INDEX idx_audit_events_occurred_at ON audit_events(occurred_at);
+CREATE INDEX idx_audit_events_actor ON audit_events(actor);
+CREATE INDEX idx_audit_events_contract ON audit_events(contract);
+
+CREATE OR REPLACE FUNCTION reject_audit_events_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_audit_events_change();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION reject_audit_events_change();
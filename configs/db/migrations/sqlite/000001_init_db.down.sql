@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_smart_contracts_updated_at;
+DROP INDEX IF EXISTS idx_smart_contracts_created_at;
+DROP INDEX IF EXISTS idx_smart_contracts_address;
+DROP TABLE IF EXISTS smart_contracts;
@@ -0,0 +1,11 @@
+CREATE TABLE smart_contracts (
+    smart_contract_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    address VARCHAR(255) NOT NULL UNIQUE,
+    value TEXT NOT NULL, -- uint256 as text, NUMERIC would lose precision in sqlite
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_smart_contracts_address ON smart_contracts(address);
+CREATE INDEX idx_smart_contracts_created_at ON smart_contracts(created_at);
+CREATE INDEX idx_smart_contracts_updated_at ON smart_contracts(updated_at);
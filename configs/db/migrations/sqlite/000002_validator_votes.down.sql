@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_validator_votes_created_at;
+DROP INDEX IF EXISTS idx_validator_votes_validator;
+DROP TABLE IF EXISTS validator_votes;
@@ -0,0 +1,13 @@
+CREATE TABLE validator_votes (
+    validator_vote_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    action VARCHAR(32) NOT NULL, -- propose_add, propose_remove or discard
+    validator VARCHAR(42) NOT NULL,
+    node VARCHAR(255) NOT NULL,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    requested_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_validator_votes_validator ON validator_votes(validator);
+CREATE INDEX idx_validator_votes_created_at ON validator_votes(created_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS signer_scan_state;
+DROP INDEX IF EXISTS idx_signer_transactions_signer;
+DROP TABLE IF EXISTS signer_transactions;
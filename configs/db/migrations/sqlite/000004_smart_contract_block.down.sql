@@ -0,0 +1,2 @@
+ALTER TABLE smart_contracts DROP COLUMN block_hash;
+ALTER TABLE smart_contracts DROP COLUMN block_number;
@@ -0,0 +1,9 @@
+CREATE TABLE api_keys (
+    api_key_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL, -- first characters of the key, to tell the keys apart
+    key_hash VARCHAR(64) NOT NULL UNIQUE, -- SHA-256 of the key, which is never stored
+    role VARCHAR(16) NOT NULL CHECK (role IN ('reader', 'writer', 'admin')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
@@ -0,0 +1,16 @@
+-- requests made with an Idempotency-Key header, to replay their response on retries
+CREATE TABLE idempotency_keys (
+    client VARCHAR(255) NOT NULL, -- key:<api key id>, jwt:<subject> or ip:<address>
+    idempotency_key VARCHAR(255) NOT NULL,
+    route VARCHAR(255) NOT NULL, -- method and path, e.g. POST /api/v1/smart-contract/set-value
+    fingerprint VARCHAR(64) NOT NULL, -- SHA-256 of the method, path and body, which is never stored
+    state VARCHAR(16) NOT NULL CHECK (state IN ('in_progress', 'completed')),
+    status_code INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (client, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,13 @@
+-- transactions sent by the service, tracked until they are mined, across restarts
+CREATE TABLE sent_transactions (
+    hash VARCHAR(66) PRIMARY KEY,
+    signer VARCHAR(42) NOT NULL,
+    nonce BIGINT NOT NULL,
+    method VARCHAR(255) NOT NULL, -- e.g. set(42)
+    state VARCHAR(16) NOT NULL CHECK (state IN ('pending', 'mined', 'reverted')),
+    block_number BIGINT, -- null while pending
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sent_transactions_state ON sent_transactions(state);
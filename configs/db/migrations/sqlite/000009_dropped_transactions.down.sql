@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_sent_transactions_state;
+ALTER TABLE sent_transactions RENAME TO sent_transactions_old;
+
+CREATE TABLE sent_transactions (
+    hash VARCHAR(66) PRIMARY KEY,
+    signer VARCHAR(42) NOT NULL,
+    nonce BIGINT NOT NULL,
+    method VARCHAR(255) NOT NULL, -- e.g. set(42)
+    state VARCHAR(16) NOT NULL CHECK (state IN ('pending', 'mined', 'reverted')),
+    block_number BIGINT, -- null while pending
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO sent_transactions SELECT * FROM sent_transactions_old WHERE state <> 'dropped';
+DROP TABLE sent_transactions_old;
+
+CREATE INDEX idx_sent_transactions_state ON sent_transactions(state);
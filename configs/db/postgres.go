@@ -0,0 +1,43 @@
+package dbConfig
+
+import (
+	"context"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresBackend stores the data on a Postgres server, through a pgx pool.
+type postgresBackend struct{}
+
+func (postgresBackend) Name() string {
+	return "postgres"
+}
+
+func (postgresBackend) Open(ctx context.Context, url string) (Pool, error) {
+	config, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	config.ConnConfig.Tracer = postgresTracer
+	return pgxpool.NewWithConfig(ctx, config)
+}
+
+func (postgresBackend) Placeholder() squirrel.PlaceholderFormat {
+	return squirrel.Dollar
+}
+
+// MigrationDriver opens a connection of its own (lib/pq), as golang-migrate
+// doesn't run over a pgx pool.
+func (postgresBackend) MigrationDriver(url string, pool Pool) (database.Driver, error) {
+	return (&postgres.Postgres{}).Open(url)
+}
+
+// Lock takes a transaction-level advisory lock, released on commit or rollback.
+func (postgresBackend) Lock(ctx context.Context, tx pgx.Tx, key int64) error {
+	_, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", key)
+	return err
+}
@@ -0,0 +1,336 @@
+package dbConfig
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/golang-migrate/migrate/v4/database"
+	sqliteMigrate "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteBackend stores the data on an embedded SQLite file, so the service runs
+// without a database server (edge deployments and local development). The driver
+// (modernc.org/sqlite) is pure Go, so the binary still builds without cgo.
+// The url is sqlite://<path>[?<driver options>], e.g. sqlite://./data/app.db
+// or sqlite://:memory:.
+type sqliteBackend struct{}
+
+func (sqliteBackend) Name() string {
+	return "sqlite"
+}
+
+func (sqliteBackend) Open(ctx context.Context, url string) (Pool, error) {
+	_, dsn, _ := strings.Cut(url, "://")
+	file, _, _ := strings.Cut(dsn, "?")
+	if file == "" {
+		return nil, errors.New("missing the database file in the sqlite DATABASE_URL")
+	}
+	if file != ":memory:" && !strings.HasPrefix(file, "file:") {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open("sqlite", sqliteDSN(dsn))
+	if err != nil {
+		return nil, err
+	}
+	// SQLite has a single writer, so a single connection serializes the writes
+	// instead of failing them with SQLITE_BUSY (and keeps :memory: databases alive).
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+	return &sqlitePool{db}, nil
+}
+
+// sqliteDSN adds the defaults of the service to the driver options of a dsn, unless
+// they are set:
+//   - the transactions take the write lock of the database when they begin, rather
+//     than on their first write, so Lock has nothing left to do;
+//   - the ones of other processes wait 5s for it instead of failing with SQLITE_BUSY;
+//   - the times are written in the format of SQLite, which sorts as they do.
+func sqliteDSN(dsn string) string {
+	_, options, _ := strings.Cut(dsn, "?")
+	defaults := []struct{ set, option string }{
+		{"_txlock=", "_txlock=immediate"},
+		{"busy_timeout", "_pragma=busy_timeout(5000)"},
+		{"_time_format=", "_time_format=sqlite"},
+	}
+	for _, d := range defaults {
+		if strings.Contains(options, d.set) {
+			continue
+		}
+		if strings.Contains(dsn, "?") {
+			dsn += "&" + d.option
+		} else {
+			dsn += "?" + d.option
+		}
+	}
+	return dsn
+}
+
+func (sqliteBackend) Placeholder() squirrel.PlaceholderFormat {
+	return squirrel.Question
+}
+
+func (sqliteBackend) MigrationDriver(url string, pool Pool) (database.Driver, error) {
+	sqlite, ok := pool.(*sqlitePool)
+	if !ok {
+		return nil, fmt.Errorf("sqlite migrations can't run on %T", pool)
+	}
+	return sqliteMigrate.WithInstance(sqlite.db, &sqliteMigrate.Config{})
+}
+
+// Lock has nothing to do: the transactions begin immediate, holding the lock of
+// the whole database.
+func (sqliteBackend) Lock(ctx context.Context, tx pgx.Tx, key int64) error {
+	return nil
+}
+
+// sqlStates maps the SQLite constraint violations (extended result codes) to the
+// SQLSTATE codes of Postgres, so the repositories handle them the same way on both
+// backends.
+var sqlStates = map[int]string{
+	sqlite3.SQLITE_CONSTRAINT_UNIQUE:     "23505",
+	sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY: "23505",
+	sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY: "23503",
+	sqlite3.SQLITE_CONSTRAINT_NOTNULL:    "23502",
+	sqlite3.SQLITE_CONSTRAINT_CHECK:      "23514",
+}
+
+// sqliteError converts the errors of database/sql and of the SQLite driver to
+// the ones pgx returns in the same situations.
+func sqliteError(err error) error {
+	var sqliteErr *sqlite.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return pgx.ErrNoRows
+	case errors.Is(err, sql.ErrTxDone):
+		return pgx.ErrTxClosed
+	case errors.As(err, &sqliteErr):
+		if code, ok := sqlStates[sqliteErr.Code()]; ok {
+			return &pgconn.PgError{Severity: "ERROR", Code: code, Message: sqliteErr.Error()}
+		}
+	}
+	return err
+}
+
+// commandTag builds the pgx command tag of a statement (e.g. "INSERT 0 2").
+func commandTag(query string, result sql.Result) pgconn.CommandTag {
+	affected, _ := result.RowsAffected()
+	verb, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	verb = strings.ToUpper(verb)
+	if verb == "INSERT" {
+		return pgconn.NewCommandTag(fmt.Sprintf("INSERT 0 %d", affected))
+	}
+	return pgconn.NewCommandTag(fmt.Sprintf("%s %d", verb, affected))
+}
+
+// sqliteConn is what the pool and its transactions share from database/sql.
+type sqliteConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func exec(ctx context.Context, conn sqliteConn, query string, args ...any) (pgconn.CommandTag, error) {
+	ctx = sqliteTracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: query, Args: args})
+	result, err := conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		err = sqliteError(err)
+		sqliteTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: err})
+		return pgconn.CommandTag{}, err
+	}
+	tag := commandTag(query, result)
+	sqliteTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: tag})
+	return tag, nil
+}
+
+// query runs a query, traced until its rows are closed (like in pgx).
+func query(ctx context.Context, conn sqliteConn, query string, args ...any) (pgx.Rows, error) {
+	ctx = sqliteTracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: query, Args: args})
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		err = sqliteError(err)
+		sqliteTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: err})
+		return nil, err
+	}
+	return &sqliteRows{rows: rows, ctx: ctx}, nil
+}
+
+func queryRow(ctx context.Context, conn sqliteConn, query string, args ...any) pgx.Row {
+	ctx = sqliteTracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: query, Args: args})
+	row := conn.QueryRowContext(ctx, query, args...)
+	sqliteTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: sqliteError(row.Err())})
+	return &sqliteRow{row}
+}
+
+// sqlitePool adapts a database/sql SQLite database to the Pool of the repositories.
+type sqlitePool struct {
+	db *sql.DB
+}
+
+func (p *sqlitePool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return exec(ctx, p.db, sql, args...)
+}
+
+func (p *sqlitePool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return query(ctx, p.db, sql, args...)
+}
+
+func (p *sqlitePool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return queryRow(ctx, p.db, sql, args...)
+}
+
+func (p *sqlitePool) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, sqliteError(err)
+	}
+	return &sqliteTx{tx: tx}, nil
+}
+
+func (p *sqlitePool) Ping(ctx context.Context) error {
+	return sqliteError(p.db.PingContext(ctx))
+}
+
+func (p *sqlitePool) Close() {
+	p.db.Close()
+}
+
+func (p *sqlitePool) Stats() PoolStats {
+	stats := p.db.Stats()
+	return PoolStats{
+		Acquired:      stats.InUse,
+		Idle:          stats.Idle,
+		Total:         stats.OpenConnections,
+		Max:           stats.MaxOpenConnections,
+		EmptyAcquires: stats.WaitCount,
+		AcquireWait:   stats.WaitDuration,
+	}
+}
+
+type sqliteRow struct {
+	row *sql.Row
+}
+
+func (r *sqliteRow) Scan(dest ...any) error {
+	return sqliteError(r.row.Scan(dest...))
+}
+
+type sqliteRows struct {
+	rows   *sql.Rows
+	values []any
+	err    error
+
+	ctx    context.Context // holding the span of the query, ended on Close
+	closed bool
+}
+
+func (r *sqliteRows) Close() {
+	r.rows.Close()
+	if !r.closed {
+		r.closed = true
+		sqliteTracer.TraceQueryEnd(r.ctx, nil, pgx.TraceQueryEndData{CommandTag: r.CommandTag(), Err: r.Err()})
+	}
+}
+
+func (r *sqliteRows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return sqliteError(r.rows.Err())
+}
+
+func (r *sqliteRows) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag("SELECT")
+}
+
+func (r *sqliteRows) FieldDescriptions() []pgconn.FieldDescription {
+	columns, _ := r.rows.Columns()
+	fields := make([]pgconn.FieldDescription, len(columns))
+	for i, column := range columns {
+		fields[i].Name = column
+	}
+	return fields
+}
+
+func (r *sqliteRows) Next() bool {
+	r.values = nil
+	return r.rows.Next()
+}
+
+func (r *sqliteRows) Scan(dest ...any) error {
+	if err := r.rows.Scan(dest...); err != nil {
+		r.err = sqliteError(err)
+		return r.err
+	}
+	return nil
+}
+
+func (r *sqliteRows) Values() ([]any, error) {
+	if r.values != nil {
+		return r.values, nil
+	}
+	columns, err := r.rows.Columns()
+	if err != nil {
+		return nil, sqliteError(err)
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err = r.rows.Scan(dest...); err != nil {
+		return nil, sqliteError(err)
+	}
+	r.values = values
+	return values, nil
+}
+
+func (r *sqliteRows) RawValues() [][]byte {
+	return nil
+}
+
+func (r *sqliteRows) Conn() *pgx.Conn {
+	return nil
+}
+
+// sqliteTx is a transaction of the SQLite backend. Like in pgx, Rollback after
+// Commit is a no-op returning pgx.ErrTxClosed, so it can always be deferred.
+// Savepoints, batches and copies aren't supported.
+type sqliteTx struct {
+	pgx.Tx
+	tx *sql.Tx
+}
+
+func (t *sqliteTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return exec(ctx, t.tx, sql, args...)
+}
+
+func (t *sqliteTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return query(ctx, t.tx, sql, args...)
+}
+
+func (t *sqliteTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return queryRow(ctx, t.tx, sql, args...)
+}
+
+func (t *sqliteTx) Commit(ctx context.Context) error {
+	return sqliteError(t.tx.Commit())
+}
+
+func (t *sqliteTx) Rollback(ctx context.Context) error {
+	return sqliteError(t.tx.Rollback())
+}
+
+var _ Pool = (*sqlitePool)(nil)
@@ -0,0 +1,42 @@
+package dbConfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"goledger-challenge-besu/configs/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracer traces the statements of the repositories, each in a span child of
+// the span of its context. It is the pgx tracer of the Postgres pool, and the SQLite
+// pool calls it around its statements. The arguments aren't recorded, only the SQL.
+type queryTracer struct {
+	system attribute.KeyValue
+}
+
+var (
+	postgresTracer = queryTracer{semconv.DBSystemPostgreSQL}
+	sqliteTracer   = queryTracer{semconv.DBSystemSqlite}
+)
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation, _, _ := strings.Cut(strings.TrimSpace(data.SQL), " ")
+	operation = strings.ToUpper(operation)
+	ctx, _ = tracingConfig.Start(ctx, operation, t.system, semconv.DBOperationName(operation), semconv.DBQueryText(data.SQL))
+	return ctx
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		// a lookup finding nothing isn't a failure
+		err = nil
+	}
+	tracingConfig.End(trace.SpanFromContext(ctx), err)
+}
@@ -2,35 +2,68 @@ package httpConfig
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
-	"strings"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/configs/cache"
 	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/configs/metrics"
+	"goledger-challenge-besu/internal/app/account"
+	"goledger-challenge-besu/internal/app/audit"
+	"goledger-challenge-besu/internal/app/auth"
+	"goledger-challenge-besu/internal/app/cache"
+	"goledger-challenge-besu/internal/app/explorer"
+	"goledger-challenge-besu/internal/app/health"
+	"goledger-challenge-besu/internal/app/network"
 	"goledger-challenge-besu/internal/app/smart-contract"
+	"goledger-challenge-besu/internal/domain/account"
+	"goledger-challenge-besu/internal/domain/audit"
+	"goledger-challenge-besu/internal/domain/auth"
+	"goledger-challenge-besu/internal/domain/explorer"
+	"goledger-challenge-besu/internal/domain/health"
+	"goledger-challenge-besu/internal/domain/idempotency"
+	"goledger-challenge-besu/internal/domain/network"
+	"goledger-challenge-besu/internal/domain/quota"
 	"goledger-challenge-besu/internal/domain/smart-contract"
 
 	"github.com/gin-contrib/cors"
-	"github.com/gin-contrib/timeout"
 	"github.com/gin-gonic/gin"
 	sloggin "github.com/samber/slog-gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type HTTP struct {
 	*gin.Engine
-	Host           string
-	Port           string
-	Address        string
-	AllowedOrigins string
+	Settings
+	AdminAPIKey   string          // bootstrap admin API key, not stored
+	AnonymousRole authDomain.Role // role of the requests without credentials, none when empty
+
+	server       *http.Server
+	ready        atomic.Bool // false once the shutdown started
+	transactions *smartContractApp.SmartContractService
 }
 
-func (r *HTTP) Route(ctx *context.Context, db *dbConfig.DB, ethClient *besuConfig.EthClient) error {
+func (r *HTTP) Route(ctx *context.Context, db *dbConfig.DB, ethClient *besuConfig.EthClient, cache *cacheConfig.Cache) error {
 	// (DI) Dependency Injection
-	smartContractRepoBesu, err := smartContractDomain.NewRepositoryBesu(ctx, ethClient)
+	networkRepoBesu, err := networkDomain.NewRepositoryBesu(ctx, ethClient)
+	if err != nil {
+		slog.Error("Error building NetworkRepositoryBesu", "error", err)
+		return err
+	}
+	cachedClient, err := smartContractDomain.NewCachedBackend(ctx, ethClient, cache)
+	if err != nil {
+		slog.Error("Error building CachedBackend", "error", err)
+		return err
+	}
+	cachedClient.Watch()
+	smartContractRepoBesu, err := smartContractDomain.NewRepositoryBesu(ctx, cachedClient)
 	if err != nil {
 		slog.Error("Error building SmartContractRepositoryBesu", "error", err)
 		return err
@@ -40,25 +73,258 @@ func (r *HTTP) Route(ctx *context.Context, db *dbConfig.DB, ethClient *besuConfi
 		slog.Error("Error building SmartContractRepositoryDB", "error", err)
 		return err
 	}
-	smartContractService := smartContractApp.NewService(smartContractRepoDB, smartContractRepoBesu)
+	smartContractService := smartContractApp.NewService(smartContractRepoDB, smartContractRepoDB, smartContractRepoBesu, smartContractRepoBesu, networkRepoBesu)
+	// the transactions left pending by the previous shutdown are waited for again
+	if resumed, err := smartContractService.Track(); err == nil && resumed > 0 {
+		slog.Info("Tracking pending transactions", "transactions", resumed)
+	}
+	r.transactions = smartContractService
 	smartContractHandler := smartContractApp.NewHandler(smartContractService)
 
+	networkRepoDB, err := networkDomain.NewRepositoryDB(ctx, db)
+	if err != nil {
+		slog.Error("Error building NetworkRepositoryDB", "error", err)
+		return err
+	}
+	networkService := networkApp.NewService(networkRepoDB, networkRepoBesu)
+	networkHandler := networkApp.NewHandler(networkService)
+
+	explorerRepoBesu, err := explorerDomain.NewRepositoryBesu(ctx, ethClient)
+	if err != nil {
+		slog.Error("Error building ExplorerRepositoryBesu", "error", err)
+		return err
+	}
+	explorerService := explorerApp.NewService(explorerRepoBesu)
+	explorerHandler := explorerApp.NewHandler(explorerService)
+
+	accountRepoBesu, err := accountDomain.NewRepositoryBesu(ctx, ethClient)
+	if err != nil {
+		slog.Error("Error building AccountRepositoryBesu", "error", err)
+		return err
+	}
+	accountRepoDB, err := accountDomain.NewRepositoryDB(ctx, db)
+	if err != nil {
+		slog.Error("Error building AccountRepositoryDB", "error", err)
+		return err
+	}
+	accountService, err := accountApp.NewService(ctx, accountRepoDB, accountRepoBesu, explorerRepoBesu)
+	if err != nil {
+		slog.Error("Error building AccountService", "error", err)
+		return err
+	}
+	accountService.Watch()
+	accountHandler := accountApp.NewHandler(accountService)
+
+	cacheHandler := cacheApp.NewHandler(cache)
+
+	authRepoDB, err := authDomain.NewRepositoryDB(ctx, db)
+	if err != nil {
+		slog.Error("Error building AuthRepositoryDB", "error", err)
+		return err
+	}
+	authRepoJWT, err := authDomain.NewRepositoryJWT()
+	if err != nil {
+		slog.Error("Error building AuthRepositoryJWT", "error", err)
+		return err
+	}
+	authService := authApp.NewService(authRepoDB, authRepoJWT, r.AdminAPIKey)
+	authHandler := authApp.NewHandler(authService)
+
+	quotaRepoDB, err := quotaDomain.NewRepositoryDB(ctx, db)
+	if err != nil {
+		slog.Error("Error building QuotaRepositoryDB", "error", err)
+		return err
+	}
+	rateLimiter, err := NewRateLimiter(ctx, quotaRepoDB)
+	if err != nil {
+		slog.Error("Error building RateLimiter", "error", err)
+		return err
+	}
+
+	idempotencyRepoDB, err := idempotencyDomain.NewRepositoryDB(ctx, db)
+	if err != nil {
+		slog.Error("Error building IdempotencyRepositoryDB", "error", err)
+		return err
+	}
+	idempotency, err := NewIdempotency(ctx, idempotencyRepoDB)
+	if err != nil {
+		slog.Error("Error building Idempotency", "error", err)
+		return err
+	}
+
+	auditRepoDB, err := auditDomain.NewRepositoryDB(ctx, db)
+	if err != nil {
+		slog.Error("Error building AuditRepositoryDB", "error", err)
+		return err
+	}
+	auditService := auditApp.NewService(auditRepoDB)
+	auditHandler := auditApp.NewHandler(auditService)
+
+	healthRepoDB, err := healthDomain.NewRepositoryDB(ctx, db)
+	if err != nil {
+		slog.Error("Error building HealthRepositoryDB", "error", err)
+		return err
+	}
+	healthRepoBesu, err := healthDomain.NewRepositoryBesu(ctx, ethClient)
+	if err != nil {
+		slog.Error("Error building HealthRepositoryBesu", "error", err)
+		return err
+	}
+	healthService, err := healthApp.NewService(healthRepoDB, healthRepoBesu)
+	if err != nil {
+		slog.Error("Error building HealthService", "error", err)
+		return err
+	}
+	healthHandler := healthApp.NewHandler(healthService, r.ready.Load)
+
+	metricsConfig.Register(metricsConfig.NewDBCollector(db), metricsConfig.NewCacheCollector(cache))
+
 	// Routes and Middlewares (for specifics groups or routes)
-	v1 := r.Group("/api/v1")
+	// the probes of the orchestrator and the scrapes of Prometheus need no credentials
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+	r.GET("/metrics", gin.WrapH(metricsConfig.Handler()))
+
+	// every route needs the reader role, the writes need the writer or admin roles,
+	// the retried POST requests with an Idempotency-Key are replayed (before the rate
+	// limiter, so the replays don't count against the quotas), and the requests of
+	// each client are rate limited. The state-changing routes are audited, the
+	// replays aren't since they change nothing
+	v1 := r.Group("/api/v1",
+		authenticate(authService, r.AnonymousRole),
+		authorize(authDomain.RoleReader),
+		idempotency.Middleware(),
+		rateLimiter.Middleware(),
+	)
+	writer := authorize(authDomain.RoleWriter)
+	admin := authorize(authDomain.RoleAdmin)
+	audit := audited(auditService)
 	{
 		smartContract := v1.Group("/smart-contract")
 		{
 			smartContract.GET("", smartContractHandler.GetValue)
 			smartContract.GET("/check-value/:value", smartContractHandler.CheckValue)
-			smartContract.POST("/set-value", smartContractHandler.SetValue)
-			smartContract.POST("/sync", smartContractHandler.SyncValue)
+			smartContract.POST("/set-value", writer, audit(auditDomain.ActionSetValue), smartContractHandler.SetValue)
+			smartContract.POST("/sync", writer, audit(auditDomain.ActionSync), smartContractHandler.SyncValue)
+		}
+		smartContracts := v1.Group("/smart-contracts")
+		{
+			smartContracts.POST("/values", smartContractHandler.GetValues)
+		}
+		network := v1.Group("/network")
+		{
+			network.GET("/status", networkHandler.GetStatus)
+			network.GET("/validators", networkHandler.GetValidators)
+			network.GET("/validators/metrics", networkHandler.GetSignerMetrics)
+			network.GET("/peers", networkHandler.GetPeers)
+			network.GET("/peers/count", networkHandler.GetPeerCount)
+			network.GET("/syncing", networkHandler.GetSyncStatus)
+			network.GET("/txpool", networkHandler.GetTxPoolStatistics)
+
+			votes := network.Group("/validators/votes", admin)
+			{
+				votes.GET("", networkHandler.GetValidatorVotes)
+				votes.POST("", audit(auditDomain.ActionProposeValidatorVote), networkHandler.ProposeValidatorVote)
+				votes.DELETE("", audit(auditDomain.ActionDiscardValidatorVote), networkHandler.DiscardValidatorVotes)
+			}
+
+			permissioning := network.Group("/permissioning")
+			{
+				permissioning.GET("/accounts", networkHandler.GetAccountsAllowlists)
+				permissioning.POST("/accounts", admin, audit(auditDomain.ActionAddAccounts), networkHandler.AddAccountsToAllowlist)
+				permissioning.DELETE("/accounts", admin, audit(auditDomain.ActionRemoveAccounts), networkHandler.RemoveAccountsFromAllowlist)
+				permissioning.GET("/nodes", networkHandler.GetNodesAllowlists)
+				permissioning.POST("/nodes", admin, audit(auditDomain.ActionAddNodes), networkHandler.AddNodesToAllowlist)
+				permissioning.DELETE("/nodes", admin, audit(auditDomain.ActionRemoveNodes), networkHandler.RemoveNodesFromAllowlist)
+			}
+		}
+
+		blocks := v1.Group("/blocks")
+		{
+			blocks.GET("/latest", explorerHandler.GetLatestBlock)
+			blocks.GET("/:numberOrHash", explorerHandler.GetBlock)
+		}
+
+		transactions := v1.Group("/transactions")
+		{
+			transactions.GET("/:hash", explorerHandler.GetTransaction)
+			transactions.GET("/:hash/receipt", explorerHandler.GetReceipt)
+		}
+
+		accounts := v1.Group("/accounts")
+		{
+			accounts.GET("/signers", accountHandler.GetSigners)
+			accounts.GET("/:address", accountHandler.GetAccount)
+			accounts.GET("/:address/transactions", accountHandler.GetTransactions)
+		}
+
+		v1.GET("/cache/stats", cacheHandler.GetStats)
+
+		auth := v1.Group("/auth")
+		{
+			auth.GET("/me", authHandler.GetMe)
+
+			apiKeys := auth.Group("/api-keys", admin)
+			{
+				apiKeys.GET("", authHandler.ListAPIKeys)
+				apiKeys.POST("", audit(auditDomain.ActionCreateAPIKey), authHandler.CreateAPIKey)
+				apiKeys.DELETE("/:id", audit(auditDomain.ActionRevokeAPIKey), authHandler.RevokeAPIKey)
+			}
+		}
+
+		auditLog := v1.Group("/audit", admin)
+		{
+			auditLog.GET("", auditHandler.GetEvents)
+			auditLog.GET("/verify", auditHandler.VerifyEvents)
 		}
 	}
 	return nil
 }
 
+// Serve listens on the address of the settings, with HTTPS when they have a TLS config,
+// until Shutdown.
+// Returns:
+//   - nil once shut down, or an error if the server can't listen.
 func (r *HTTP) Serve() error {
-	return r.Run(r.Address)
+	var err error
+	if r.TLS != nil {
+		// the certificates are in the TLSConfig
+		err = r.server.ListenAndServeTLS("", "")
+	} else {
+		err = r.server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops the server gracefully: the readiness fails first, for the
+// ShutdownDelay, then the server stops accepting connections and waits for the
+// requests in progress, and for the transactions sent being mined.
+// Parameters:
+//   - ctx: The deadline of the shutdown. The transactions still pending at the
+//     deadline are handed off to the tracker, which resumes them on the next start.
+//
+// Returns:
+//   - An error if the requests in progress didn't finish before the deadline.
+func (r *HTTP) Shutdown(ctx context.Context) error {
+	r.ready.Store(false)
+	if r.ShutdownDelay > 0 {
+		slog.Info("Failing readiness before shutting down", "delay", r.ShutdownDelay)
+		select {
+		case <-time.After(r.ShutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	err := r.server.Shutdown(ctx)
+	if r.transactions != nil {
+		if pending := r.transactions.Drain(ctx); pending > 0 {
+			slog.Warn("Pending transactions handed off to the tracker", "transactions", pending)
+		}
+	}
+	return err
 }
 
 func New() (*HTTP, error) {
@@ -66,30 +332,65 @@ func New() (*HTTP, error) {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	host := os.Getenv("APP_DOMAIN")
-	port := os.Getenv("APP_PORT")
-	allowedOrigins := "*"
-	address := fmt.Sprintf("%s:%s", host, port)
+	settings, err := LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	var anonymousRole authDomain.Role
+	if role := os.Getenv("AUTH_ANONYMOUS_ROLE"); role != "" {
+		anonymousRole, err = authDomain.ParseRole(role)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTH_ANONYMOUS_ROLE %q: %w", role, err)
+		}
+	}
+
+	corsConfig := cors.Config{
+		AllowMethods:     settings.AllowedMethods,
+		AllowHeaders:     settings.AllowedHeaders,
+		ExposeHeaders:    []string{"Idempotent-Replayed", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"},
+		AllowCredentials: settings.AllowCredentials,
+		MaxAge:           settings.CORSMaxAge,
+		AllowWildcard:    true,
+	}
+	if slices.Contains(settings.AllowedOrigins, "*") {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = settings.AllowedOrigins
+	}
+	if err = corsConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid CORS settings: %w", err)
+	}
 
-	ginConfig := cors.DefaultConfig()
-	ginConfig.AllowOrigins = strings.Split(allowedOrigins, ",")
 	router := gin.New()
+	// the client IP (of the logs and the rate limits) is only read from the
+	// X-Forwarded-For header of the trusted proxies
+	if err = router.SetTrustedProxies(settings.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid HTTP_TRUSTED_PROXIES: %w", err)
+	}
 
 	// Global Middlewares
-	router.Use(sloggin.New(slog.Default()), gin.Recovery(), cors.New(ginConfig))
-	router.Use(timeout.New(
-		timeout.WithTimeout(12*time.Second),
-		timeout.WithResponse(func(c *gin.Context) {
-			c.JSON(http.StatusServiceUnavailable, "Request Timed Out")
-		}),
-	))
+	// the span of the request comes first, so the request log holds its trace ID
+	router.Use(otelgin.Middleware(os.Getenv("APP_NAME"), otelgin.WithGinFilter(traced)))
+	router.Use(sloggin.New(slog.Default()), observeRequests(), gin.Recovery(), cors.New(corsConfig))
+	router.Use(limitBody(settings.MaxBodyBytes), requestTimeout(settings))
 	// ...it would be possible, for example, to add middleware to strip slashes
 
-	return &HTTP{
-		router,
-		host,
-		port,
-		address,
-		allowedOrigins,
-	}, nil
+	r := &HTTP{
+		Engine:        router,
+		Settings:      *settings,
+		AdminAPIKey:   os.Getenv("ADMIN_API_KEY"),
+		AnonymousRole: anonymousRole,
+	}
+	r.server = &http.Server{
+		Addr:              settings.Address,
+		Handler:           router,
+		ReadTimeout:       settings.ReadTimeout,
+		ReadHeaderTimeout: settings.ReadHeaderTimeout,
+		WriteTimeout:      settings.WriteTimeout,
+		IdleTimeout:       settings.IdleTimeout,
+		TLSConfig:         settings.TLS,
+	}
+	r.ready.Store(true)
+	return r, nil
 }
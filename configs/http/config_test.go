@@ -0,0 +1,95 @@
+package httpConfig
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/internal/app/health"
+	"goledger-challenge-besu/internal/domain/health/fake"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	t.Setenv("APP_DOMAIN", "127.0.0.1")
+	t.Setenv("APP_PORT", strconv.Itoa(port))
+	t.Setenv("HTTP_SHUTDOWN_DELAY", "50ms")
+	r, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	health, err := healthApp.NewService(&healthFake.Database{Version: 1, Latest: 1}, healthFake.NewNode(1337, 1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.GET("/readyz", healthApp.NewHandler(health, r.ready.Load).Readiness)
+	started, release := make(chan struct{}), make(chan struct{})
+	r.GET("/slow", func(ctx *gin.Context) {
+		close(started)
+		<-release
+		ctx.String(http.StatusOK, "done")
+	})
+
+	served := make(chan error, 1)
+	go func() { served <- r.Serve() }()
+	url := "http://" + r.Address
+	var res *http.Response
+	for range 100 {
+		if res, err = http.Get(url + "/readyz"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("GET /readyz = %v, %v, want 200", res, err)
+	}
+	res.Body.Close()
+
+	slow := make(chan string, 1)
+	go func() {
+		res, err := http.Get(url + "/slow")
+		if err != nil {
+			slow <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		slow <- string(body)
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- r.Shutdown(context.Background()) }()
+	// the readiness fails first, while the requests are still served
+	time.Sleep(10 * time.Millisecond)
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz while shutting down = %d, want 503", recorder.Code)
+	}
+
+	// the request in progress finishes
+	close(release)
+	if body := <-slow; body != "done" {
+		t.Errorf("request in progress = %q, want done", body)
+	}
+	if err = <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err = <-served; err != nil {
+		t.Errorf("Serve() error = %v, want nil once shut down", err)
+	}
+}
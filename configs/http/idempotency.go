@@ -0,0 +1,218 @@
+package httpConfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotency replays the response of the POST requests retried with the same
+// Idempotency-Key header, instead of running them again (e.g. sending a second
+// transaction when a client retries set-value after a timeout). The keys are per
+// client and kept in the database, so every instance of the service shares them.
+type Idempotency struct {
+	ctx   *context.Context
+	store idempotencyDomain.IdempotencyStore
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// NewIdempotency builds the Idempotency middleware, the keys kept for IDEMPOTENCY_KEY_TTL
+// (a duration, 24h by default).
+// Parameters:
+//   - ctx: The context of the expired keys cleanup.
+//   - store: The IdempotencyStore of the keys.
+//
+// Returns:
+//   - A pointer to Idempotency if successful.
+//   - An error if IDEMPOTENCY_KEY_TTL is invalid.
+func NewIdempotency(ctx *context.Context, store idempotencyDomain.IdempotencyStore) (*Idempotency, error) {
+	ttl := 24 * time.Hour
+	if env := os.Getenv("IDEMPOTENCY_KEY_TTL"); env != "" {
+		var err error
+		if ttl, err = time.ParseDuration(env); err != nil || ttl < time.Minute {
+			return nil, fmt.Errorf("invalid IDEMPOTENCY_KEY_TTL %q, want a duration of 1m at least", env)
+		}
+	}
+	idempotency := &Idempotency{ctx: ctx, store: store, ttl: ttl, now: time.Now}
+	go idempotency.purge()
+	return idempotency, nil
+}
+
+// purge deletes the expired keys every hour.
+func (i *Idempotency) purge() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if purged, err := i.store.Purge(i.now()); err == nil && purged > 0 {
+				slog.Info("Expired idempotency keys purged", "count", purged)
+			}
+		case <-(*i.ctx).Done():
+			return
+		}
+	}
+}
+
+// leaseGrace is added to the lease of the keys, for the handlers to answer once the
+// context of their request is canceled.
+const leaseGrace = 5 * time.Second
+
+// validIdempotencyKey accepts 1 to 255 printable ASCII characters, e.g. a UUID.
+func validIdempotencyKey(key string) bool {
+	if len(key) > 255 {
+		return false
+	}
+	for _, c := range []byte(key) {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// recordingWriter keeps a copy of the response written by the handlers.
+type recordingWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+func (w *recordingWriter) Status() int {
+	if w.status != 0 {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+// replayable tells whether the response of a request is stored and replayed: the
+// server errors, the rate limited and the unauthorized requests may succeed if
+// retried, so their keys are released instead.
+func replayable(status int) bool {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return false
+	case status == http.StatusTooManyRequests, status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return false
+	default:
+		return true
+	}
+}
+
+// Middleware handles the Idempotency-Key header of the POST requests of the routes
+// it is installed on. It must follow authenticate, which identifies the clients.
+//   - The first request with a key runs, and its response is stored.
+//   - The retries with the same key and body get the stored response, with the
+//     Idempotent-Replayed header, or 409 while the first one is still running.
+//   - The requests reusing a key with another route or body get 422.
+//   - A key whose request panicked, or is still in progress past its timeout, is
+//     claimed again by a retry.
+func (i *Idempotency) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader("Idempotency-Key")
+		if ctx.Request.Method != http.MethodPost || key == "" {
+			ctx.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, domain.ErrInvalidIdempotencyKey.Error())
+			return
+		}
+		body, err := io.ReadAll(ctx.Request.Body)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, domain.ErrRequestTooLarge.Error())
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		route := ctx.Request.Method + " " + ctx.FullPath()
+		caller := client(ctx)
+		fingerprint := idempotencyDomain.Fingerprint(ctx.Request.Method, ctx.Request.URL.Path, body)
+		// the key is leased until the timeout of the request (see requestTimeout), so a
+		// request whose instance stopped doesn't hold it until it expires
+		var lease time.Duration
+		if deadline, ok := ctx.Request.Context().Deadline(); ok {
+			lease = time.Until(deadline) + leaseGrace
+		}
+		now := i.now()
+		record, claimed, err := i.store.Begin(idempotencyDomain.Record{
+			Client:      caller,
+			Key:         key,
+			Route:       route,
+			Fingerprint: fingerprint,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(i.ttl),
+			Lease:       lease,
+		})
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, domain.ErrInternal.Error())
+			return
+		}
+		if !claimed {
+			switch {
+			case record.Fingerprint != fingerprint:
+				slog.Warn("Idempotency key reused with a different request", "route", route, "client", caller)
+				ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, domain.ErrIdempotencyKeyReused.Error())
+			case record.State == idempotencyDomain.StateInProgress:
+				ctx.Header("Retry-After", "1")
+				ctx.AbortWithStatusJSON(http.StatusConflict, domain.ErrIdempotencyInProgress.Error())
+			default:
+				slog.Info("Idempotent request replayed", "route", route, "client", caller, "status", record.StatusCode)
+				ctx.Header("Idempotent-Replayed", "true")
+				ctx.Data(record.StatusCode, record.ContentType, record.ResponseBody)
+				ctx.Abort()
+			}
+			return
+		}
+
+		// a panic of the handlers is answered 500 by Recovery, so the key is released
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				i.store.Release(caller, key)
+				panic(recovered)
+			}
+		}()
+		writer := &recordingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+
+		status := writer.Status()
+		if !replayable(status) {
+			i.store.Release(caller, key)
+			return
+		}
+		i.store.Complete(caller, key, status, writer.Header().Get("Content-Type"), writer.body.Bytes())
+	}
+}
@@ -0,0 +1,318 @@
+package httpConfig
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/internal/app/auth"
+	"goledger-challenge-besu/internal/app/smart-contract"
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/auth"
+	"goledger-challenge-besu/internal/domain/auth/fake"
+	"goledger-challenge-besu/internal/domain/idempotency"
+	"goledger-challenge-besu/internal/domain/idempotency/fake"
+	"goledger-challenge-besu/internal/domain/network/fake"
+	"goledger-challenge-besu/internal/domain/smart-contract"
+	"goledger-challenge-besu/internal/domain/smart-contract/fake"
+
+	"github.com/gin-gonic/gin"
+)
+
+type idempotencyFixture struct {
+	router *gin.Engine
+	store  *idempotencyFake.Store
+	clock  time.Time
+	calls  atomic.Int32
+	block  chan struct{} // when set, the handler waits for it to be closed
+}
+
+// newIdempotencyFixture mounts a POST route, counting its calls and answering with the
+// status of its query, and a GET route behind the middleware. The token "alice" is a
+// writer, the requests without credentials are anonymous readers.
+func newIdempotencyFixture(t *testing.T) *idempotencyFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	f := &idempotencyFixture{store: idempotencyFake.NewStore(), clock: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	idempotency, err := NewIdempotency(&ctx, f.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idempotency.now = func() time.Time { return f.clock }
+
+	tokens := authFake.Tokens{"alice": {Subject: "alice", Role: authDomain.RoleWriter, Method: "jwt"}}
+	service := authApp.NewService(authFake.NewKeyStore(), tokens, "")
+	f.router = gin.New()
+	v1 := f.router.Group("/api/v1", authenticate(service, authDomain.RoleReader), idempotency.Middleware())
+	handler := func(ctx *gin.Context) {
+		call := f.calls.Add(1)
+		if f.block != nil {
+			<-f.block
+		}
+		status, _ := strconv.Atoi(ctx.DefaultQuery("status", "200"))
+		ctx.JSON(status, "call "+strconv.Itoa(int(call)))
+	}
+	v1.POST("/write", handler)
+	v1.POST("/other", handler)
+	v1.GET("/read", handler)
+	return f
+}
+
+func (f *idempotencyFixture) do(method string, url string, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer alice")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	recorder := httptest.NewRecorder()
+	f.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func wantBody(t *testing.T, res *httptest.ResponseRecorder, status int, body string, replayed bool) {
+	t.Helper()
+	if res.Code != status || res.Body.String() != body || (res.Header().Get("Idempotent-Replayed") == "true") != replayed {
+		t.Errorf("response = %d %s (replayed %q), want %d %s (replayed %v)",
+			res.Code, res.Body.String(), res.Header().Get("Idempotent-Replayed"), status, body, replayed)
+	}
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	f := newIdempotencyFixture(t)
+
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write", "k1", `{"value": 1}`), http.StatusOK, `"call 1"`, false)
+	// a retry gets the stored response, even with the JSON serialized differently
+	res := f.do(http.MethodPost, "/api/v1/write", "k1", `{"value":1}`)
+	wantBody(t, res, http.StatusOK, `"call 1"`, true)
+	if contentType := res.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("replayed Content-Type = %q, want application/json", contentType)
+	}
+	// the key can't be reused for another request
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write", "k1", `{"value": 2}`), http.StatusUnprocessableEntity,
+		`"Idempotency-Key Already Used with a Different Request"`, false)
+	wantBody(t, f.do(http.MethodPost, "/api/v1/other", "k1", `{"value": 1}`), http.StatusUnprocessableEntity,
+		`"Idempotency-Key Already Used with a Different Request"`, false)
+
+	// without a key, or on other methods, the requests always run
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write", "", `{"value": 1}`), http.StatusOK, `"call 2"`, false)
+	wantBody(t, f.do(http.MethodGet, "/api/v1/read", "k1", ""), http.StatusOK, `"call 3"`, false)
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write", "bad\nkey", `{"value": 1}`), http.StatusBadRequest,
+		`"Invalid Idempotency-Key (1 to 255 printable characters)"`, false)
+
+	// the client errors are replayed, the server errors can be retried
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write?status=422", "k2", `{}`), http.StatusUnprocessableEntity, `"call 4"`, false)
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write?status=422", "k2", `{}`), http.StatusUnprocessableEntity, `"call 4"`, true)
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write?status=503", "k3", `{}`), http.StatusServiceUnavailable, `"call 5"`, false)
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write?status=503", "k3", `{}`), http.StatusServiceUnavailable, `"call 6"`, false)
+
+	// the keys are per client
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/write", strings.NewReader(`{"value": 1}`))
+	req.Header.Set("Idempotency-Key", "k1")
+	anonymous := httptest.NewRecorder()
+	f.router.ServeHTTP(anonymous, req)
+	wantBody(t, anonymous, http.StatusOK, `"call 7"`, false)
+
+	// and expire
+	f.clock = f.clock.Add(25 * time.Hour)
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write", "k1", `{"value": 2}`), http.StatusOK, `"call 8"`, false)
+	if record, _ := f.store.Get("jwt:alice", "k1"); record.State != idempotencyDomain.StateCompleted {
+		t.Errorf("record state = %q, want %q", record.State, idempotencyDomain.StateCompleted)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	f := newIdempotencyFixture(t)
+	f.block = make(chan struct{})
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- f.do(http.MethodPost, "/api/v1/write", "k1", `{}`) }()
+	for f.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	res := f.do(http.MethodPost, "/api/v1/write", "k1", `{}`)
+	wantBody(t, res, http.StatusConflict, `"A Request with the Same Idempotency-Key is in Progress"`, false)
+	if retry := res.Header().Get("Retry-After"); retry != "1" {
+		t.Errorf("Retry-After = %q, want 1", retry)
+	}
+	close(f.block)
+	wantBody(t, <-first, http.StatusOK, `"call 1"`, false)
+	wantBody(t, f.do(http.MethodPost, "/api/v1/write", "k1", `{}`), http.StatusOK, `"call 1"`, true)
+	if calls := f.calls.Load(); calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
+
+// TestIdempotencyPanic checks the key of a request whose handler panicked is released.
+func TestIdempotencyPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := idempotencyFake.NewStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idempotency, _ := NewIdempotency(&ctx, store)
+	tokens := authFake.Tokens{"alice": {Subject: "alice", Role: authDomain.RoleWriter, Method: "jwt"}}
+	service := authApp.NewService(authFake.NewKeyStore(), tokens, "")
+
+	var calls atomic.Int32
+	router := gin.New()
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, gin.RecoveryFunc(func(ctx *gin.Context, err any) {
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+	})))
+	router.POST("/api/v1/write", authenticate(service, ""), idempotency.Middleware(), func(ctx *gin.Context) {
+		if calls.Add(1) == 1 {
+			panic("handler failed")
+		}
+		ctx.JSON(http.StatusOK, "sent")
+	})
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/write", strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer alice")
+		req.Header.Set("Idempotency-Key", "k1")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	if first := do(); first.Code != http.StatusInternalServerError {
+		t.Fatalf("first response = %d, want 500", first.Code)
+	}
+	wantBody(t, do(), http.StatusOK, `"sent"`, false)
+}
+
+// TestIdempotencyLease checks a key still in progress past the timeout of its route,
+// e.g. left by an instance that stopped, is claimed again by a retry.
+func TestIdempotencyLease(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := idempotencyFake.NewStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idempotency, _ := NewIdempotency(&ctx, store)
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	idempotency.now = func() time.Time { return clock }
+	tokens := authFake.Tokens{"alice": {Subject: "alice", Role: authDomain.RoleWriter, Method: "jwt"}}
+	service := authApp.NewService(authFake.NewKeyStore(), tokens, "")
+
+	router := gin.New()
+	router.Use(requestTimeout(&Settings{RequestTimeout: time.Minute}))
+	router.POST("/api/v1/write", authenticate(service, ""), idempotency.Middleware(), func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, "sent")
+	})
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/write", strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer alice")
+		req.Header.Set("Idempotency-Key", "k1")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// the request of another instance, which stopped while running it
+	store.Begin(idempotencyDomain.Record{Client: "jwt:alice", Key: "k1", Route: "POST /api/v1/write",
+		Fingerprint: idempotencyDomain.Fingerprint(http.MethodPost, "/api/v1/write", []byte(`{}`)),
+		CreatedAt:   clock, ExpiresAt: clock.Add(24 * time.Hour)})
+
+	wantBody(t, do(), http.StatusConflict, `"A Request with the Same Idempotency-Key is in Progress"`, false)
+	clock = clock.Add(2 * time.Minute)
+	wantBody(t, do(), http.StatusOK, `"sent"`, false)
+	wantBody(t, do(), http.StatusOK, `"sent"`, true)
+}
+
+func TestNewIdempotency(t *testing.T) {
+	for ttl, valid := range map[string]bool{"": true, "1h": true, "1s": false, "day": false} {
+		t.Setenv("IDEMPOTENCY_KEY_TTL", ttl)
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := NewIdempotency(&ctx, idempotencyFake.NewStore())
+		cancel()
+		if (err == nil) != valid {
+			t.Errorf("NewIdempotency() with IDEMPOTENCY_KEY_TTL %q error = %v, want valid %v", ttl, err, valid)
+		}
+	}
+}
+
+// TestIdempotencyAfterTimeout checks the response of a handler that timed out is
+// still kept for the retries, the case of a set-value retried after its timeout.
+func TestIdempotencyAfterTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := idempotencyFake.NewStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idempotency, _ := NewIdempotency(&ctx, store)
+	tokens := authFake.Tokens{"alice": {Subject: "alice", Role: authDomain.RoleWriter, Method: "jwt"}}
+	service := authApp.NewService(authFake.NewKeyStore(), tokens, "")
+
+	router := gin.New()
+	router.Use(requestTimeout(&Settings{RequestTimeout: 20 * time.Millisecond}))
+	router.POST("/api/v1/write", authenticate(service, ""), idempotency.Middleware(), func(ctx *gin.Context) {
+		time.Sleep(50 * time.Millisecond)
+		ctx.JSON(http.StatusOK, "sent")
+	})
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/write", strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer alice")
+		req.Header.Set("Idempotency-Key", "k1")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	wantBody(t, do(), http.StatusServiceUnavailable, `"Request Timed Out"`, false)
+	wantBody(t, do(), http.StatusOK, `"sent"`, true)
+}
+
+// TestIdempotencySetValueAfterTimeout runs set-value past its timeout: the request
+// context is canceled while the transaction is mined, and the retry must get the
+// hash of that transaction instead of sending another one.
+func TestIdempotencySetValueAfterTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idempotency, _ := NewIdempotency(&ctx, idempotencyFake.NewStore())
+	tokens := authFake.Tokens{"alice": {Subject: "alice", Role: authDomain.RoleWriter, Method: "jwt"}}
+	service := authApp.NewService(authFake.NewKeyStore(), tokens, "")
+	contract := smartContractFake.NewContract(big.NewInt(7), nil)
+	contract.Mining = make(chan struct{}) // never mined while the request runs
+	store := smartContractFake.NewStore()
+	setter := smartContractApp.NewService(store, store, contract, contract, &networkFake.Allowlist{})
+	handler := smartContractApp.NewHandler(setter)
+
+	router := gin.New()
+	router.Use(requestTimeout(&Settings{RequestTimeout: 20 * time.Millisecond}))
+	router.POST("/api/v1/smart-contract/set-value", authenticate(service, ""), idempotency.Middleware(), handler.SetValue)
+	do := func() *httptest.ResponseRecorder {
+		body := `{"value": 8, "privateKey": "8f2a55949038a9610f50fb23b5883af3b4ecb3c3bb792cbcefbd1542c692be63"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/smart-contract/set-value", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer alice")
+		req.Header.Set("Idempotency-Key", "k1")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// the timeout and the handler, whose request context is canceled by it, race to answer
+	if first := do(); first.Code != http.StatusServiceUnavailable && first.Code != http.StatusAccepted {
+		t.Fatalf("first response = %d %s, want 503 or 202", first.Code, first.Body)
+	}
+	retry := do()
+	txs := store.Transactions()
+	if len(contract.SetValues) != 1 || len(txs) != 1 {
+		t.Fatalf("transactions sent = %v, want 1", contract.SetValues)
+	}
+	wantBody(t, retry, http.StatusAccepted, `{"hash":"`+txs[0].Hash+`","message":"`+domain.ErrTransactionPending.Error()+`"}`, true)
+
+	// the transaction is still waited for after the request, until it's mined
+	close(contract.Mining)
+	if pending := setter.Drain(context.Background()); pending != 0 {
+		t.Fatalf("Drain() = %d, want 0", pending)
+	}
+	if txs = store.Transactions(); txs[0].State != smartContractDomain.TransactionMined {
+		t.Errorf("transaction state = %s, want mined", txs[0].State)
+	}
+}
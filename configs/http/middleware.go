@@ -0,0 +1,136 @@
+package httpConfig
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"goledger-challenge-besu/configs/metrics"
+	"goledger-challenge-besu/internal/app/audit"
+	"goledger-challenge-besu/internal/app/auth"
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/audit"
+	"goledger-challenge-besu/internal/domain/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authenticate identifies the caller by the API key of the X-API-Key header or the
+// bearer token of the Authorization header. Requests without credentials get the
+// anonymous role, or are rejected when it is empty; invalid credentials are always
+// rejected, they never fall back to the anonymous role.
+func authenticate(service *authApp.AuthService, anonymous authDomain.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		apiKey := ctx.GetHeader("X-API-Key")
+		authorization := ctx.GetHeader("Authorization")
+		if apiKey == "" && authorization == "" && anonymous != "" {
+			authApp.SetPrincipal(ctx, &authDomain.Principal{Subject: "anonymous", Role: anonymous, Method: "anonymous"})
+			ctx.Next()
+			return
+		}
+
+		principal, err := service.Authenticate(apiKey, authorization)
+		if err == domain.ErrUnauthorized {
+			slog.Warn("Unauthorized request", "path", ctx.FullPath(), "ip", ctx.ClientIP())
+			ctx.Header("WWW-Authenticate", `Bearer realm="api"`)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, domain.ErrUnauthorized.Error())
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, domain.ErrInternal.Error())
+			return
+		}
+		authApp.SetPrincipal(ctx, principal)
+		ctx.Next()
+	}
+}
+
+// authorize only lets through the callers whose role includes the required one.
+func authorize(required authDomain.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := authApp.PrincipalOf(ctx)
+		if principal == nil || !principal.Role.Allows(required) {
+			if principal != nil {
+				slog.Warn("Forbidden request", "path", ctx.FullPath(), "subject", principal.Subject, "role", principal.Role, "required", required)
+			}
+			ctx.AbortWithStatusJSON(http.StatusForbidden, domain.ErrForbidden.Error())
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// limitBody rejects the request bodies larger than max bytes with 413, right away
+// when their Content-Length tells it, or when the handlers read past it.
+func limitBody(max int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.ContentLength > max {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, domain.ErrRequestTooLarge.Error())
+			return
+		}
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, max)
+		ctx.Next()
+	}
+}
+
+// observeRequests records the duration of the requests by their route template, so
+// the paths with parameters (e.g. a block hash) don't each get their own series.
+// The requests matching no route are recorded as "unmatched".
+func observeRequests() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metricsConfig.HTTPRequestDuration.
+			WithLabelValues(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// traced tells whether a request is traced: the probes and the scrapes of the
+// metrics aren't, they would drown the traces of the API.
+func traced(ctx *gin.Context) bool {
+	switch ctx.FullPath() {
+	case "/healthz", "/readyz", "/metrics":
+		return false
+	}
+	return true
+}
+
+// audited returns the middleware recording an audit event of each request of a
+// state-changing route, the action, once its handlers answered: the caller, its IP,
+// the route and the status, plus what the services did (see auditDomain.Annotate).
+// It follows authorize, so the requests denied aren't recorded, but the failed ones
+// are, a panic as a 500.
+func audited(service *auditApp.AuditService) func(action string) gin.HandlerFunc {
+	return func(action string) gin.HandlerFunc {
+		return func(ctx *gin.Context) {
+			event := &auditDomain.Event{
+				OccurredAt: time.Now(),
+				Action:     action,
+				ClientIP:   ctx.ClientIP(),
+				Method:     ctx.Request.Method,
+				Route:      ctx.FullPath(),
+			}
+			if principal := authApp.PrincipalOf(ctx); principal != nil {
+				event.Actor, event.AuthMethod, event.Role = principal.Subject, principal.Method, string(principal.Role)
+			}
+			ctx.Request = ctx.Request.WithContext(auditDomain.NewContext(ctx.Request.Context(), event))
+
+			panicked := true
+			defer func() {
+				event.StatusCode = ctx.Writer.Status()
+				if panicked {
+					event.StatusCode = http.StatusInternalServerError
+				}
+				service.Record(*event)
+			}()
+			ctx.Next()
+			panicked = false
+		}
+	}
+}
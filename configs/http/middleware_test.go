@@ -0,0 +1,232 @@
+package httpConfig
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"goledger-challenge-besu/configs/metrics"
+	"goledger-challenge-besu/configs/tracing"
+	"goledger-challenge-besu/internal/app/audit"
+	"goledger-challenge-besu/internal/app/auth"
+	"goledger-challenge-besu/internal/domain/audit"
+	"goledger-challenge-besu/internal/domain/audit/fake"
+	"goledger-challenge-besu/internal/domain/auth"
+	"goledger-challenge-besu/internal/domain/auth/fake"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// newAuthRouter mounts a route of each role, as Route does.
+func newAuthRouter(anonymous authDomain.Role) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	tokens := authFake.Tokens{}
+	for _, role := range []authDomain.Role{authDomain.RoleReader, authDomain.RoleWriter, authDomain.RoleAdmin} {
+		tokens[string(role)] = authDomain.Principal{Subject: string(role), Role: role, Method: "jwt"}
+	}
+	service := authApp.NewService(authFake.NewKeyStore(), tokens, "bootstrap")
+
+	router := gin.New()
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+	v1 := router.Group("/api/v1", authenticate(service, anonymous), authorize(authDomain.RoleReader))
+	v1.GET("/read", ok)
+	v1.POST("/write", authorize(authDomain.RoleWriter), ok)
+	v1.POST("/admin", authorize(authDomain.RoleAdmin), ok)
+	return router
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		anonymous authDomain.Role
+		token     string
+		apiKey    string
+		want      map[string]int // path -> status
+	}{
+		{
+			name: "no credentials",
+			want: map[string]int{"/read": 401, "/write": 401, "/admin": 401},
+		},
+		{
+			name: "anonymous reader", anonymous: authDomain.RoleReader,
+			want: map[string]int{"/read": 200, "/write": 403, "/admin": 403},
+		},
+		{
+			name: "invalid credentials don't fall back to anonymous", anonymous: authDomain.RoleReader, token: "unknown",
+			want: map[string]int{"/read": 401, "/write": 401, "/admin": 401},
+		},
+		{
+			name: "reader", token: "reader",
+			want: map[string]int{"/read": 200, "/write": 403, "/admin": 403},
+		},
+		{
+			name: "writer", token: "writer",
+			want: map[string]int{"/read": 200, "/write": 200, "/admin": 403},
+		},
+		{
+			name: "admin", token: "admin",
+			want: map[string]int{"/read": 200, "/write": 200, "/admin": 200},
+		},
+		{
+			name: "bootstrap api key", apiKey: "bootstrap",
+			want: map[string]int{"/read": 200, "/write": 200, "/admin": 200},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newAuthRouter(tt.anonymous)
+			for path, want := range tt.want {
+				method := http.MethodPost
+				if path == "/read" {
+					method = http.MethodGet
+				}
+				req := httptest.NewRequest(method, "/api/v1"+path, nil)
+				if tt.token != "" {
+					req.Header.Set("Authorization", "Bearer "+tt.token)
+				}
+				if tt.apiKey != "" {
+					req.Header.Set("X-API-Key", tt.apiKey)
+				}
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, req)
+				if recorder.Code != want {
+					t.Errorf("%s %s = %d, want %d", method, path, recorder.Code, want)
+				}
+				if want == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+					t.Errorf("%s %s without WWW-Authenticate header", method, path)
+				}
+			}
+		})
+	}
+}
+
+// requestsObserved returns the number of requests recorded for a route and status.
+func requestsObserved(t *testing.T, route, status string) uint64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	histogram := metricsConfig.HTTPRequestDuration.WithLabelValues(http.MethodGet, route, status).(prometheus.Histogram)
+	if err := histogram.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestTraceRequests(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracingConfig.Install(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(otelgin.Middleware("test", otelgin.WithGinFilter(traced)))
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+	router.GET("/api/v1/traced/:id", ok)
+	router.GET("/healthz", ok)
+	router.GET("/metrics", ok)
+
+	// the trace of the caller is continued
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/traced/1", nil)
+	req.Header.Set("traceparent", parent)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	for _, path := range []string{"/healthz", "/metrics"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("%d spans exported, want only the one of the API request", len(spans))
+	}
+	if spans[0].Name != "/api/v1/traced/:id" {
+		t.Errorf("span name = %s, want the route template", spans[0].Name)
+	}
+	if got := spans[0].SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the one of the traceparent header", got)
+	}
+}
+
+func TestObserveRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(observeRequests())
+	router.GET("/observed/:id", func(ctx *gin.Context) { ctx.Status(http.StatusTeapot) })
+	unmatched := requestsObserved(t, "unmatched", "404")
+
+	for _, path := range []string{"/observed/1", "/observed/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// the two ids share the series of the route template
+	if got := requestsObserved(t, "/observed/:id", "418"); got != 2 {
+		t.Errorf("requests observed of /observed/:id = %d, want 2", got)
+	}
+	if got := requestsObserved(t, "unmatched", "404") - unmatched; got != 1 {
+		t.Errorf("requests observed unmatched = %d, want 1", got)
+	}
+}
+
+func TestAudited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := authFake.Tokens{
+		"reader": {Subject: "dashboard", Role: authDomain.RoleReader, Method: "jwt"},
+		"writer": {Subject: "billing", Role: authDomain.RoleWriter, Method: "jwt"},
+	}
+	store := auditFake.NewStore()
+	audit := audited(auditApp.NewService(store))
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	v1 := router.Group("/api/v1", authenticate(authApp.NewService(authFake.NewKeyStore(), tokens, ""), ""))
+	v1.POST("/set/:id", authorize(authDomain.RoleWriter), audit(auditDomain.ActionSetValue), func(ctx *gin.Context) {
+		auditDomain.Annotate(ctx.Request.Context(), func(event *auditDomain.Event) {
+			event.Value = "42"
+			event.Detail("state", "mined")
+		})
+		ctx.Status(http.StatusCreated)
+	})
+	v1.POST("/panic", authorize(authDomain.RoleWriter), audit(auditDomain.ActionSync), func(ctx *gin.Context) {
+		panic("boom")
+	})
+	post := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	if status := post("/api/v1/set/1", "writer"); status != http.StatusCreated {
+		t.Fatalf("POST /set/1 = %d, want 201", status)
+	}
+	// the denied requests don't reach the audit
+	if status := post("/api/v1/set/1", "reader"); status != http.StatusForbidden {
+		t.Fatalf("POST /set/1 as reader = %d, want 403", status)
+	}
+	if status := post("/api/v1/panic", "writer"); status != http.StatusInternalServerError {
+		t.Fatalf("POST /panic = %d, want 500", status)
+	}
+
+	if len(store.Events) != 2 {
+		t.Fatalf("events recorded = %+v, want 2", store.Events)
+	}
+	set, panicked := store.Events[0], store.Events[1]
+	if set.Action != auditDomain.ActionSetValue || set.Actor != "billing" || set.AuthMethod != "jwt" || set.Role != "writer" ||
+		set.Method != http.MethodPost || set.Route != "/api/v1/set/:id" || set.StatusCode != http.StatusCreated ||
+		set.Value != "42" || set.Details["state"] != "mined" || set.OccurredAt.IsZero() {
+		t.Errorf("event recorded = %+v, want the request, principal and annotations", set)
+	}
+	if panicked.Action != auditDomain.ActionSync || panicked.StatusCode != http.StatusInternalServerError || panicked.PrevHash != set.Hash {
+		t.Errorf("event of the panic = %+v, want it recorded as 500", panicked)
+	}
+}
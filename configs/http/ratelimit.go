@@ -0,0 +1,314 @@
+package httpConfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"goledger-challenge-besu/internal/app/auth"
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/quota"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Budget is a token bucket: Rate requests per second on average, up to Burst at once.
+// A zero Rate doesn't limit the requests.
+type Budget struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// RoutePolicy overrides the limits of a route (e.g. "POST /api/v1/smart-contract/set-value").
+type RoutePolicy struct {
+	// Class is the budget the route shares, read or write (by the method when empty).
+	Class string `json:"class,omitempty"`
+	// Rate and Burst, when set, give the route its own bucket instead of the class one.
+	Rate  float64 `json:"rate,omitempty"`
+	Burst int     `json:"burst,omitempty"`
+	// DailyQuota, when set, limits the requests of each client per day (UTC).
+	DailyQuota uint64 `json:"dailyQuota,omitempty"`
+}
+
+const (
+	classRead  = "read"
+	classWrite = "write"
+)
+
+// bucket is the token bucket of a client on a class or a route.
+type bucket struct {
+	budget Budget
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.budget.Burst), b.tokens+now.Sub(b.last).Seconds()*b.budget.Rate)
+	b.last = now
+}
+
+// RateLimiter limits the requests of each client (API key, token subject or IP)
+// with token buckets, one for the read routes and one for the write routes unless
+// a route has its own, and counts the requests of the routes with a daily quota
+// in the database. The buckets live in memory, so each instance of the service has
+// its own; the quotas are shared.
+type RateLimiter struct {
+	ctx     *context.Context
+	classes map[string]Budget
+	routes  map[string]RoutePolicy
+	quotas  quotaDomain.QuotaStore
+	now     func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewRateLimiter builds the RateLimiter from RATE_LIMIT_READ_RATE, RATE_LIMIT_READ_BURST,
+// RATE_LIMIT_WRITE_RATE, RATE_LIMIT_WRITE_BURST, TX_DAILY_QUOTA (of set-value) and the
+// route policies of the RATE_LIMIT_ROUTES_PATH JSON file.
+// Parameters:
+//   - ctx: The context of the idle buckets cleanup.
+//   - quotas: The QuotaStore of the daily quotas.
+//
+// Returns:
+//   - A pointer to RateLimiter if successful.
+//   - An error if a variable or the routes file is invalid.
+func NewRateLimiter(ctx *context.Context, quotas quotaDomain.QuotaStore) (*RateLimiter, error) {
+	read, err := budgetEnv("RATE_LIMIT_READ", Budget{Rate: 20, Burst: 40})
+	if err != nil {
+		return nil, err
+	}
+	write, err := budgetEnv("RATE_LIMIT_WRITE", Budget{Rate: 1, Burst: 5})
+	if err != nil {
+		return nil, err
+	}
+	txQuota := uint64(1000)
+	if env := os.Getenv("TX_DAILY_QUOTA"); env != "" {
+		if txQuota, err = strconv.ParseUint(env, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid TX_DAILY_QUOTA %q", env)
+		}
+	}
+
+	routes := map[string]RoutePolicy{
+		"POST /api/v1/smart-contract/set-value": {DailyQuota: txQuota},
+		"POST /api/v1/smart-contracts/values":   {Class: classRead},
+	}
+	if path := os.Getenv("RATE_LIMIT_ROUTES_PATH"); path != "" {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var policies map[string]RoutePolicy
+		if err = json.Unmarshal(file, &policies); err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_ROUTES_PATH file: %w", err)
+		}
+		for route, policy := range policies {
+			if err = validatePolicy(route, policy); err != nil {
+				return nil, err
+			}
+			if policy.Rate > 0 && policy.Burst == 0 {
+				policy.Burst = int(math.Ceil(policy.Rate))
+			}
+			routes[route] = policy
+		}
+	}
+
+	limiter := &RateLimiter{
+		ctx:     ctx,
+		classes: map[string]Budget{classRead: read, classWrite: write},
+		routes:  routes,
+		quotas:  quotas,
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+	go limiter.cleanup()
+	return limiter, nil
+}
+
+// budgetEnv reads the <prefix>_RATE and <prefix>_BURST variables.
+func budgetEnv(prefix string, budget Budget) (Budget, error) {
+	if env := os.Getenv(prefix + "_RATE"); env != "" {
+		rate, err := strconv.ParseFloat(env, 64)
+		if err != nil || rate < 0 || math.IsInf(rate, 0) {
+			return budget, fmt.Errorf("invalid %s_RATE %q", prefix, env)
+		}
+		budget.Rate = rate
+	}
+	if env := os.Getenv(prefix + "_BURST"); env != "" {
+		burst, err := strconv.Atoi(env)
+		if err != nil || burst < 1 {
+			return budget, fmt.Errorf("invalid %s_BURST %q", prefix, env)
+		}
+		budget.Burst = burst
+	}
+	return budget, nil
+}
+
+func validatePolicy(route string, policy RoutePolicy) error {
+	if !validRoute(route) {
+		return fmt.Errorf("invalid route %q, want \"<METHOD> <path>\"", route)
+	}
+	if policy.Class != "" && policy.Class != classRead && policy.Class != classWrite {
+		return fmt.Errorf("invalid class %q of route %q, want read or write", policy.Class, route)
+	}
+	if policy.Rate < 0 || policy.Burst < 0 {
+		return fmt.Errorf("invalid rate or burst of route %q", route)
+	}
+	return nil
+}
+
+// cleanup drops the buckets that refilled, they are the same as new ones.
+func (l *RateLimiter) cleanup() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			now := l.now()
+			l.mu.Lock()
+			for key, b := range l.buckets {
+				if b.refill(now); b.tokens >= float64(b.budget.Burst) {
+					delete(l.buckets, key)
+				}
+			}
+			l.mu.Unlock()
+		case <-(*l.ctx).Done():
+			return
+		}
+	}
+}
+
+// take takes a token of a bucket.
+// Returns:
+//   - Whether the request is allowed.
+//   - The tokens left.
+//   - The time until the bucket is full again, or until the next token when denied.
+func (l *RateLimiter) take(key string, budget Budget) (bool, int, time.Duration) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{budget: budget, tokens: float64(budget.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		return false, 0, seconds((1 - b.tokens) / budget.Rate)
+	}
+	b.tokens--
+	return true, int(b.tokens), seconds((float64(budget.Burst) - b.tokens) / budget.Rate)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// client identifies the caller of a request: its API key, its token subject, or its IP.
+func client(ctx *gin.Context) string {
+	principal := authApp.PrincipalOf(ctx)
+	switch {
+	case principal == nil || principal.Method == "anonymous":
+		return "ip:" + ctx.ClientIP()
+	case principal.KeyID != 0:
+		return fmt.Sprintf("key:%d", principal.KeyID)
+	default:
+		return principal.Method + ":" + principal.Subject
+	}
+}
+
+// setRateLimitHeaders sets the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers (IETF draft), the reset in seconds.
+func setRateLimitHeaders(ctx *gin.Context, limit uint64, remaining uint64, reset time.Duration) {
+	ctx.Header("RateLimit-Limit", strconv.FormatUint(limit, 10))
+	ctx.Header("RateLimit-Remaining", strconv.FormatUint(remaining, 10))
+	ctx.Header("RateLimit-Reset", strconv.FormatInt(int64(math.Ceil(reset.Seconds())), 10))
+}
+
+func tooManyRequests(ctx *gin.Context, retryAfter time.Duration, err error) {
+	ctx.Header("Retry-After", strconv.FormatInt(max(1, int64(math.Ceil(retryAfter.Seconds()))), 10))
+	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, err.Error())
+}
+
+// transactionSent tells whether a response counts against the daily quota: its
+// transaction was sent, and mined (200), still pending (202), dropped (409) or
+// reverted (422). The requests rejected before reaching the chain (4xx), or failed
+// (5xx), are given back.
+func transactionSent(status int) bool {
+	switch status {
+	case http.StatusOK, http.StatusAccepted, http.StatusConflict, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
+
+// Middleware limits the requests of the routes it is installed on. It must follow
+// authenticate, which identifies the clients. The RateLimit-* headers describe the
+// bucket, or the daily quota when it is closer to being used up.
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.Request.Method + " " + ctx.FullPath()
+		policy := l.routes[route]
+		caller := client(ctx)
+
+		class := policy.Class
+		if class == "" {
+			class = classWrite
+			if method := ctx.Request.Method; method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+				class = classRead
+			}
+		}
+		budget, key := l.classes[class], caller+"|"+class
+		if policy.Rate > 0 {
+			budget, key = Budget{Rate: policy.Rate, Burst: policy.Burst}, caller+"|"+route
+		}
+
+		remaining := uint64(math.MaxUint64)
+		if budget.Rate > 0 {
+			allowed, tokens, reset := l.take(key, budget)
+			setRateLimitHeaders(ctx, uint64(budget.Burst), uint64(tokens), reset)
+			if !allowed {
+				slog.Warn("Rate limited request", "route", route, "client", caller)
+				tooManyRequests(ctx, reset, domain.ErrRateLimited)
+				return
+			}
+			remaining = uint64(tokens)
+		}
+		if policy.DailyQuota == 0 {
+			ctx.Next()
+			return
+		}
+
+		now := l.now()
+		day := quotaDomain.Day(now)
+		untilTomorrow := day.AddDate(0, 0, 1).Sub(now)
+		used, err := l.quotas.Consume(caller, route, day, policy.DailyQuota)
+		if err == domain.ErrQuotaExceeded {
+			slog.Warn("Daily quota exceeded", "route", route, "client", caller, "quota", policy.DailyQuota)
+			setRateLimitHeaders(ctx, policy.DailyQuota, 0, untilTomorrow)
+			tooManyRequests(ctx, untilTomorrow, domain.ErrQuotaExceeded)
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, domain.ErrInternal.Error())
+			return
+		}
+		if left := policy.DailyQuota - used; left < remaining {
+			setRateLimitHeaders(ctx, policy.DailyQuota, left, untilTomorrow)
+		}
+
+		ctx.Next()
+
+		if !transactionSent(ctx.Writer.Status()) {
+			l.quotas.Release(caller, route, day)
+		}
+	}
+}
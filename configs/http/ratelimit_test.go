@@ -0,0 +1,201 @@
+package httpConfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/internal/app/auth"
+	"goledger-challenge-besu/internal/domain/auth"
+	"goledger-challenge-besu/internal/domain/auth/fake"
+	"goledger-challenge-besu/internal/domain/quota"
+	"goledger-challenge-besu/internal/domain/quota/fake"
+
+	"github.com/gin-gonic/gin"
+)
+
+const setValueRoute = "POST /api/v1/smart-contract/set-value"
+
+type limiterFixture struct {
+	router *gin.Engine
+	quotas *quotaFake.Store
+	clock  time.Time
+}
+
+// newLimiterFixture mounts a read route, a write route and the set-value route behind
+// the limiter, on a clock moved by the tests. The tokens "alice" and "bob" are writers,
+// the requests without credentials are anonymous readers.
+func newLimiterFixture(t *testing.T) *limiterFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	f := &limiterFixture{quotas: quotaFake.NewStore(), clock: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	limiter, err := NewRateLimiter(&ctx, f.quotas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter.now = func() time.Time { return f.clock }
+
+	tokens := authFake.Tokens{
+		"alice": {Subject: "alice", Role: authDomain.RoleWriter, Method: "jwt"},
+		"bob":   {Subject: "bob", Role: authDomain.RoleWriter, Method: "jwt"},
+	}
+	service := authApp.NewService(authFake.NewKeyStore(), tokens, "")
+	f.router = gin.New()
+	v1 := f.router.Group("/api/v1", authenticate(service, authDomain.RoleReader), limiter.Middleware())
+	v1.GET("/read", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	v1.POST("/write", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	v1.POST("/smart-contract/set-value", func(ctx *gin.Context) {
+		status, _ := strconv.Atoi(ctx.DefaultQuery("status", "200"))
+		ctx.Status(status)
+	})
+	return f
+}
+
+func (f *limiterFixture) do(method string, url string, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	f.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func wantResponse(t *testing.T, res *httptest.ResponseRecorder, status int, limit string, remaining string) {
+	t.Helper()
+	if res.Code != status || res.Header().Get("RateLimit-Limit") != limit || res.Header().Get("RateLimit-Remaining") != remaining {
+		t.Errorf("response = %d with limit %q and remaining %q, want %d with %s and %s",
+			res.Code, res.Header().Get("RateLimit-Limit"), res.Header().Get("RateLimit-Remaining"), status, limit, remaining)
+	}
+}
+
+func TestRateLimiterBuckets(t *testing.T) {
+	t.Setenv("RATE_LIMIT_READ_RATE", "1")
+	t.Setenv("RATE_LIMIT_READ_BURST", "2")
+	t.Setenv("RATE_LIMIT_WRITE_RATE", "0.1")
+	t.Setenv("RATE_LIMIT_WRITE_BURST", "1")
+	f := newLimiterFixture(t)
+
+	wantResponse(t, f.do(http.MethodGet, "/api/v1/read", "alice"), http.StatusOK, "2", "1")
+	wantResponse(t, f.do(http.MethodGet, "/api/v1/read", "alice"), http.StatusOK, "2", "0")
+	res := f.do(http.MethodGet, "/api/v1/read", "alice")
+	wantResponse(t, res, http.StatusTooManyRequests, "2", "0")
+	if retry := res.Header().Get("Retry-After"); retry != "1" {
+		t.Errorf("Retry-After = %q, want 1", retry)
+	}
+
+	// the budgets are per client, and the writes have their own
+	wantResponse(t, f.do(http.MethodGet, "/api/v1/read", "bob"), http.StatusOK, "2", "1")
+	wantResponse(t, f.do(http.MethodGet, "/api/v1/read", ""), http.StatusOK, "2", "1")
+	wantResponse(t, f.do(http.MethodPost, "/api/v1/write", "alice"), http.StatusOK, "1", "0")
+	res = f.do(http.MethodPost, "/api/v1/write", "alice")
+	wantResponse(t, res, http.StatusTooManyRequests, "1", "0")
+	if retry := res.Header().Get("Retry-After"); retry != "10" {
+		t.Errorf("Retry-After = %q, want 10", retry)
+	}
+
+	// the buckets refill with time
+	f.clock = f.clock.Add(time.Second)
+	wantResponse(t, f.do(http.MethodGet, "/api/v1/read", "alice"), http.StatusOK, "2", "0")
+	f.clock = f.clock.Add(time.Minute)
+	wantResponse(t, f.do(http.MethodGet, "/api/v1/read", "alice"), http.StatusOK, "2", "1")
+}
+
+func TestRateLimiterRoutes(t *testing.T) {
+	routes := filepath.Join(t.TempDir(), "routes.json")
+	os.WriteFile(routes, []byte(`{
+		"GET /api/v1/read": {"rate": 0.5},
+		"POST /api/v1/write": {"class": "read"}
+	}`), 0o600)
+	t.Setenv("RATE_LIMIT_ROUTES_PATH", routes)
+	t.Setenv("RATE_LIMIT_WRITE_RATE", "0") // unlimited
+	t.Setenv("TX_DAILY_QUOTA", "0")        // disabled
+	f := newLimiterFixture(t)
+
+	// its own bucket, burst defaulting to the rate rounded up
+	wantResponse(t, f.do(http.MethodGet, "/api/v1/read", "alice"), http.StatusOK, "1", "0")
+	wantResponse(t, f.do(http.MethodGet, "/api/v1/read", "alice"), http.StatusTooManyRequests, "1", "0")
+	// a write counted as a read, in the default read budget
+	wantResponse(t, f.do(http.MethodPost, "/api/v1/write", "alice"), http.StatusOK, "40", "39")
+	// no budget
+	for range 10 {
+		wantResponse(t, f.do(http.MethodPost, "/api/v1/smart-contract/set-value", "alice"), http.StatusOK, "", "")
+	}
+}
+
+func TestRateLimiterQuota(t *testing.T) {
+	t.Setenv("TX_DAILY_QUOTA", "4")
+	t.Setenv("RATE_LIMIT_WRITE_RATE", "0")
+	f := newLimiterFixture(t)
+	day := quotaDomain.Day(f.clock)
+
+	// the quota is closer to being used up than the (unlimited) bucket
+	wantResponse(t, f.do(http.MethodPost, "/api/v1/smart-contract/set-value", "alice"), http.StatusOK, "4", "3")
+	// the requests rejected before reaching the chain, or failed, don't count; the
+	// transactions sent do: pending, dropped or reverted
+	for _, status := range []int{400, 500, 503, 202, 409, 422} {
+		f.do(http.MethodPost, "/api/v1/smart-contract/set-value?status="+strconv.Itoa(status), "alice")
+	}
+	if used := f.quotas.Used("jwt:alice", setValueRoute, day); used != 4 {
+		t.Fatalf("%d requests counted, want 4", used)
+	}
+
+	res := f.do(http.MethodPost, "/api/v1/smart-contract/set-value", "alice")
+	wantResponse(t, res, http.StatusTooManyRequests, "4", "0")
+	if retry := res.Header().Get("Retry-After"); retry != strconv.Itoa(12*3600) {
+		t.Errorf("Retry-After = %q, want the seconds until midnight UTC", retry)
+	}
+	// other clients have their own quota, and it starts over the next day
+	wantResponse(t, f.do(http.MethodPost, "/api/v1/smart-contract/set-value", "bob"), http.StatusOK, "4", "3")
+	f.clock = f.clock.Add(12 * time.Hour)
+	wantResponse(t, f.do(http.MethodPost, "/api/v1/smart-contract/set-value", "alice"), http.StatusOK, "4", "3")
+
+	f.quotas.Err = os.ErrDeadlineExceeded
+	if res = f.do(http.MethodPost, "/api/v1/smart-contract/set-value", "alice"); res.Code != http.StatusInternalServerError {
+		t.Errorf("response with the quotas unavailable = %d, want 500", res.Code)
+	}
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	routes := func(content string) string {
+		path := filepath.Join(t.TempDir(), "routes.json")
+		os.WriteFile(path, []byte(content), 0o600)
+		return path
+	}
+	tests := []struct {
+		name  string
+		env   map[string]string
+		valid bool
+	}{
+		{"defaults", nil, true},
+		{"read rate", map[string]string{"RATE_LIMIT_READ_RATE": "fast"}, false},
+		{"negative rate", map[string]string{"RATE_LIMIT_WRITE_RATE": "-1"}, false},
+		{"zero burst", map[string]string{"RATE_LIMIT_WRITE_BURST": "0"}, false},
+		{"quota", map[string]string{"TX_DAILY_QUOTA": "-1"}, false},
+		{"routes file missing", map[string]string{"RATE_LIMIT_ROUTES_PATH": "missing.json"}, false},
+		{"routes file invalid", map[string]string{"RATE_LIMIT_ROUTES_PATH": routes(`[]`)}, false},
+		{"route without method", map[string]string{"RATE_LIMIT_ROUTES_PATH": routes(`{"/api/v1/read": {"rate": 1}}`)}, false},
+		{"route class", map[string]string{"RATE_LIMIT_ROUTES_PATH": routes(`{"GET /api/v1/read": {"class": "admin"}}`)}, false},
+		{"route quota", map[string]string{"RATE_LIMIT_ROUTES_PATH": routes(`{"POST /api/v1/smart-contract/sync": {"dailyQuota": 100}}`)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			_, err := NewRateLimiter(&ctx, quotaFake.NewStore())
+			if (err == nil) != tt.valid {
+				t.Errorf("NewRateLimiter() error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
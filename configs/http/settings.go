@@ -0,0 +1,272 @@
+package httpConfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings are the settings of the HTTP server, read from the environment by
+// LoadSettings.
+type Settings struct {
+	Host    string
+	Port    string
+	Address string
+
+	// CORS
+	AllowedOrigins   []string // "*" for any origin, or origins like https://app.example.com or https://*.example.com
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	CORSMaxAge       time.Duration
+
+	// RequestTimeout is the time the handlers have to answer, unless their route
+	// has its own in RouteTimeouts ("POST /api/v1/smart-contract/set-value").
+	RequestTimeout    time.Duration
+	RouteTimeouts     map[string]time.Duration
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// ShutdownTimeout is the time the shutdown has to close the connections and wait
+	// for the pending transactions, after ShutdownDelay with the readiness failing so
+	// the load balancers stop sending requests.
+	ShutdownTimeout time.Duration
+	ShutdownDelay   time.Duration
+
+	MaxBodyBytes   int64
+	TrustedProxies []string // IPs or CIDRs, the client IP is only read from the X-Forwarded-For header of these
+
+	// TLS is nil when the server runs plain HTTP. With a client CA, the clients must
+	// present a certificate signed by it (mTLS).
+	TLS *tls.Config
+}
+
+// envParser reads the environment variables, collecting every invalid one so they
+// are all reported at once.
+type envParser struct {
+	errs []error
+}
+
+func (p *envParser) fail(name string, value string, want string) {
+	p.errs = append(p.errs, fmt.Errorf("invalid %s %q, want %s", name, value, want))
+}
+
+func (p *envParser) duration(name string, fallback time.Duration) time.Duration {
+	env := os.Getenv(name)
+	if env == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(env)
+	if err != nil || duration <= 0 {
+		p.fail(name, env, "a positive duration such as 15s")
+		return fallback
+	}
+	return duration
+}
+
+func (p *envParser) list(name string, fallback []string) []string {
+	env := os.Getenv(name)
+	if env == "" {
+		return fallback
+	}
+	var values []string
+	for _, value := range strings.Split(env, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// validRoute tells whether a route is written as "<METHOD> <path>".
+func validRoute(route string) bool {
+	method, path, ok := strings.Cut(route, " ")
+	return ok && method != "" && method == strings.ToUpper(method) && strings.HasPrefix(path, "/")
+}
+
+// validOrigin accepts the origins of the CORS spec (scheme and host, no path), with
+// at most one * in the host for the subdomains.
+func validOrigin(origin string) bool {
+	parsed, err := url.Parse(strings.Replace(origin, "*", "wildcard", 1))
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" &&
+		parsed.Path == "" && parsed.RawQuery == "" && parsed.User == nil && strings.Count(origin, "*") <= 1
+}
+
+// validHeader tells whether a header name is an HTTP token.
+func validHeader(header string) bool {
+	return header != "" && !strings.ContainsFunc(header, func(c rune) bool {
+		return c <= ' ' || c >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, c)
+	})
+}
+
+var httpMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodOptions,
+}
+
+// LoadSettings reads the settings of the HTTP server from the environment and
+// validates them:
+//   - APP_DOMAIN and APP_PORT (8080 by default), the address the server listens on;
+//   - HTTP_CORS_ALLOWED_ORIGINS (* by default), HTTP_CORS_ALLOWED_METHODS,
+//     HTTP_CORS_ALLOWED_HEADERS, HTTP_CORS_ALLOW_CREDENTIALS and HTTP_CORS_MAX_AGE;
+//   - HTTP_REQUEST_TIMEOUT (12s by default) and HTTP_ROUTE_TIMEOUTS, a comma-separated
+//     list of "<METHOD> <path>=<duration>";
+//   - HTTP_READ_TIMEOUT, HTTP_READ_HEADER_TIMEOUT, HTTP_WRITE_TIMEOUT (the longest
+//     request timeout plus 5s by default) and HTTP_IDLE_TIMEOUT;
+//   - HTTP_SHUTDOWN_TIMEOUT (30s by default) and HTTP_SHUTDOWN_DELAY (0s by default);
+//   - HTTP_MAX_BODY_BYTES (1 MiB by default);
+//   - HTTP_TRUSTED_PROXIES, the IPs or CIDRs of the proxies (none by default);
+//   - HTTP_TLS_CERT_PATH and HTTP_TLS_KEY_PATH to serve HTTPS, and HTTP_TLS_CLIENT_CA_PATH
+//     to require client certificates.
+//
+// Returns:
+//   - A pointer to Settings if successful.
+//   - An error listing every invalid variable.
+func LoadSettings() (*Settings, error) {
+	p := &envParser{}
+	s := &Settings{
+		Host: os.Getenv("APP_DOMAIN"),
+		Port: os.Getenv("APP_PORT"),
+	}
+	if s.Port == "" {
+		s.Port = "8080"
+	}
+	if port, err := strconv.Atoi(s.Port); err != nil || port < 1 || port > 65535 {
+		p.fail("APP_PORT", s.Port, "a port between 1 and 65535")
+	}
+	s.Address = net.JoinHostPort(s.Host, s.Port)
+
+	s.AllowedOrigins = p.list("HTTP_CORS_ALLOWED_ORIGINS", []string{"*"})
+	if len(s.AllowedOrigins) == 0 {
+		p.fail("HTTP_CORS_ALLOWED_ORIGINS", os.Getenv("HTTP_CORS_ALLOWED_ORIGINS"), "* or a comma-separated list of origins")
+	}
+	for _, origin := range s.AllowedOrigins {
+		if origin == "*" && len(s.AllowedOrigins) > 1 || origin != "*" && !validOrigin(origin) {
+			p.fail("HTTP_CORS_ALLOWED_ORIGINS origin", origin, "* alone, or origins like https://app.example.com or https://*.example.com")
+		}
+	}
+	s.AllowedMethods = p.list("HTTP_CORS_ALLOWED_METHODS", httpMethods)
+	for _, method := range s.AllowedMethods {
+		if !slices.Contains(httpMethods, method) {
+			p.fail("HTTP_CORS_ALLOWED_METHODS method", method, strings.Join(httpMethods, ", "))
+		}
+	}
+	s.AllowedHeaders = p.list("HTTP_CORS_ALLOWED_HEADERS",
+		[]string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-API-Key", "Idempotency-Key"})
+	for _, header := range s.AllowedHeaders {
+		if !validHeader(header) {
+			p.fail("HTTP_CORS_ALLOWED_HEADERS header", header, "a header name")
+		}
+	}
+	if env := os.Getenv("HTTP_CORS_ALLOW_CREDENTIALS"); env != "" {
+		var err error
+		if s.AllowCredentials, err = strconv.ParseBool(env); err != nil {
+			p.fail("HTTP_CORS_ALLOW_CREDENTIALS", env, "true or false")
+		}
+	}
+	if s.AllowCredentials && slices.Contains(s.AllowedOrigins, "*") {
+		p.errs = append(p.errs, errors.New("HTTP_CORS_ALLOW_CREDENTIALS needs the HTTP_CORS_ALLOWED_ORIGINS to be listed, not *"))
+	}
+	s.CORSMaxAge = p.duration("HTTP_CORS_MAX_AGE", 12*time.Hour)
+
+	s.RequestTimeout = p.duration("HTTP_REQUEST_TIMEOUT", 12*time.Second)
+	s.RouteTimeouts = map[string]time.Duration{}
+	longest := s.RequestTimeout
+	for _, entry := range p.list("HTTP_ROUTE_TIMEOUTS", nil) {
+		separator := strings.LastIndex(entry, "=")
+		if separator < 0 || !validRoute(entry[:separator]) {
+			p.fail("HTTP_ROUTE_TIMEOUTS entry", entry, "\"<METHOD> <path>=<duration>\"")
+			continue
+		}
+		timeout, err := time.ParseDuration(entry[separator+1:])
+		if err != nil || timeout <= 0 {
+			p.fail("HTTP_ROUTE_TIMEOUTS entry", entry, "a positive duration such as 30s")
+			continue
+		}
+		s.RouteTimeouts[entry[:separator]] = timeout
+		longest = max(longest, timeout)
+	}
+	s.ReadTimeout = p.duration("HTTP_READ_TIMEOUT", 15*time.Second)
+	s.ReadHeaderTimeout = p.duration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second)
+	s.WriteTimeout = p.duration("HTTP_WRITE_TIMEOUT", longest+5*time.Second)
+	if s.WriteTimeout <= longest {
+		// the server would close the connections before the handlers answer
+		p.errs = append(p.errs, fmt.Errorf("HTTP_WRITE_TIMEOUT %s must be longer than the longest request timeout %s", s.WriteTimeout, longest))
+	}
+	s.IdleTimeout = p.duration("HTTP_IDLE_TIMEOUT", 60*time.Second)
+	s.ShutdownTimeout = p.duration("HTTP_SHUTDOWN_TIMEOUT", 30*time.Second)
+	if env := os.Getenv("HTTP_SHUTDOWN_DELAY"); env != "" {
+		var err error
+		if s.ShutdownDelay, err = time.ParseDuration(env); err != nil || s.ShutdownDelay < 0 {
+			p.fail("HTTP_SHUTDOWN_DELAY", env, "a duration such as 5s, or 0s")
+		}
+	}
+
+	s.MaxBodyBytes = 1 << 20
+	if env := os.Getenv("HTTP_MAX_BODY_BYTES"); env != "" {
+		var err error
+		if s.MaxBodyBytes, err = strconv.ParseInt(env, 10, 64); err != nil || s.MaxBodyBytes <= 0 {
+			p.fail("HTTP_MAX_BODY_BYTES", env, "a positive number of bytes")
+		}
+	}
+
+	s.TrustedProxies = p.list("HTTP_TRUSTED_PROXIES", nil)
+	for _, proxy := range s.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			p.fail("HTTP_TRUSTED_PROXIES proxy", proxy, "an IP or a CIDR")
+		}
+	}
+
+	tlsConfig, err := loadTLS(os.Getenv("HTTP_TLS_CERT_PATH"), os.Getenv("HTTP_TLS_KEY_PATH"), os.Getenv("HTTP_TLS_CLIENT_CA_PATH"))
+	if err != nil {
+		p.errs = append(p.errs, err)
+	}
+	s.TLS = tlsConfig
+
+	if err := errors.Join(p.errs...); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadTLS loads the certificate of the server and, if any, the CA of the clients.
+func loadTLS(certPath string, keyPath string, clientCAPath string) (*tls.Config, error) {
+	if certPath == "" && keyPath == "" {
+		if clientCAPath != "" {
+			return nil, errors.New("HTTP_TLS_CLIENT_CA_PATH needs HTTP_TLS_CERT_PATH and HTTP_TLS_KEY_PATH")
+		}
+		return nil, nil
+	}
+	if certPath == "" || keyPath == "" {
+		return nil, errors.New("HTTP_TLS_CERT_PATH and HTTP_TLS_KEY_PATH must be set together")
+	}
+	certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP_TLS_CERT_PATH or HTTP_TLS_KEY_PATH: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+	if clientCAPath == "" {
+		return config, nil
+	}
+	pem, err := os.ReadFile(clientCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP_TLS_CLIENT_CA_PATH: %w", err)
+	}
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("invalid HTTP_TLS_CLIENT_CA_PATH %q, no PEM certificate found", clientCAPath)
+	}
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	return config, nil
+}
@@ -0,0 +1,215 @@
+package httpConfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeCertificate writes a self-signed certificate and its key, in PEM.
+func writeCertificate(t *testing.T) (certPath string, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certPath, keyPath = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certPath, keyPath
+}
+
+func TestLoadSettings(t *testing.T) {
+	certPath, keyPath := writeCertificate(t)
+	tests := []struct {
+		name  string
+		env   map[string]string
+		valid bool
+	}{
+		{"defaults", nil, true},
+		{"port", map[string]string{"APP_PORT": "http"}, false},
+		{"origins", map[string]string{"HTTP_CORS_ALLOWED_ORIGINS": "https://app.example.com, https://*.example.com,http://localhost:3000"}, true},
+		{"origin with a path", map[string]string{"HTTP_CORS_ALLOWED_ORIGINS": "https://app.example.com/"}, false},
+		{"origin without scheme", map[string]string{"HTTP_CORS_ALLOWED_ORIGINS": "app.example.com"}, false},
+		{"any origin among others", map[string]string{"HTTP_CORS_ALLOWED_ORIGINS": "*,https://app.example.com"}, false},
+		{"no origins", map[string]string{"HTTP_CORS_ALLOWED_ORIGINS": " , "}, false},
+		{"methods", map[string]string{"HTTP_CORS_ALLOWED_METHODS": "GET,POST"}, true},
+		{"unknown method", map[string]string{"HTTP_CORS_ALLOWED_METHODS": "GET,get"}, false},
+		{"header", map[string]string{"HTTP_CORS_ALLOWED_HEADERS": "X-API-Key,Bad Header"}, false},
+		{"credentials with any origin", map[string]string{"HTTP_CORS_ALLOW_CREDENTIALS": "true"}, false},
+		{"credentials", map[string]string{"HTTP_CORS_ALLOW_CREDENTIALS": "true", "HTTP_CORS_ALLOWED_ORIGINS": "https://app.example.com"}, true},
+		{"max age", map[string]string{"HTTP_CORS_MAX_AGE": "-1h"}, false},
+		{"request timeout", map[string]string{"HTTP_REQUEST_TIMEOUT": "12"}, false},
+		{"route timeouts", map[string]string{"HTTP_ROUTE_TIMEOUTS": "POST /api/v1/smart-contract/set-value=30s, GET /api/v1/network/status=2s"}, true},
+		{"route timeout without method", map[string]string{"HTTP_ROUTE_TIMEOUTS": "/api/v1/network/status=2s"}, false},
+		{"route timeout duration", map[string]string{"HTTP_ROUTE_TIMEOUTS": "GET /api/v1/network/status=0s"}, false},
+		{"write timeout under a route timeout", map[string]string{"HTTP_ROUTE_TIMEOUTS": "POST /api/v1/smart-contract/set-value=30s", "HTTP_WRITE_TIMEOUT": "20s"}, false},
+		{"shutdown timeout", map[string]string{"HTTP_SHUTDOWN_TIMEOUT": "0s"}, false},
+		{"shutdown delay", map[string]string{"HTTP_SHUTDOWN_DELAY": "0s"}, true},
+		{"negative shutdown delay", map[string]string{"HTTP_SHUTDOWN_DELAY": "-5s"}, false},
+		{"body size", map[string]string{"HTTP_MAX_BODY_BYTES": "1MB"}, false},
+		{"trusted proxies", map[string]string{"HTTP_TRUSTED_PROXIES": "10.0.0.0/8,127.0.0.1,::1"}, true},
+		{"trusted proxy", map[string]string{"HTTP_TRUSTED_PROXIES": "10.0.0.0/33"}, false},
+		{"tls", map[string]string{"HTTP_TLS_CERT_PATH": certPath, "HTTP_TLS_KEY_PATH": keyPath}, true},
+		{"tls without key", map[string]string{"HTTP_TLS_CERT_PATH": certPath}, false},
+		{"tls key mismatch", map[string]string{"HTTP_TLS_CERT_PATH": keyPath, "HTTP_TLS_KEY_PATH": keyPath}, false},
+		{"mtls", map[string]string{"HTTP_TLS_CERT_PATH": certPath, "HTTP_TLS_KEY_PATH": keyPath, "HTTP_TLS_CLIENT_CA_PATH": certPath}, true},
+		{"mtls without tls", map[string]string{"HTTP_TLS_CLIENT_CA_PATH": certPath}, false},
+		{"mtls ca", map[string]string{"HTTP_TLS_CERT_PATH": certPath, "HTTP_TLS_KEY_PATH": keyPath, "HTTP_TLS_CLIENT_CA_PATH": keyPath}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			_, err := LoadSettings()
+			if (err == nil) != tt.valid {
+				t.Errorf("LoadSettings() error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestLoadSettingsValues(t *testing.T) {
+	t.Setenv("APP_DOMAIN", "::1")
+	t.Setenv("HTTP_ROUTE_TIMEOUTS", "POST /api/v1/smart-contract/set-value=30s")
+	settings, err := LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Address != "[::1]:8080" {
+		t.Errorf("Address = %q, want [::1]:8080", settings.Address)
+	}
+	if settings.RequestTimeout != 12*time.Second || settings.RouteTimeouts["POST /api/v1/smart-contract/set-value"] != 30*time.Second {
+		t.Errorf("timeouts = %s and %v, want 12s and set-value 30s", settings.RequestTimeout, settings.RouteTimeouts)
+	}
+	// long enough for the slowest route
+	if settings.WriteTimeout != 35*time.Second {
+		t.Errorf("WriteTimeout = %s, want 35s", settings.WriteTimeout)
+	}
+	if settings.TLS != nil || settings.MaxBodyBytes != 1<<20 {
+		t.Errorf("TLS = %v and MaxBodyBytes = %d, want none and 1 MiB", settings.TLS, settings.MaxBodyBytes)
+	}
+
+	// every invalid variable is reported at once
+	t.Setenv("APP_PORT", "0")
+	t.Setenv("HTTP_IDLE_TIMEOUT", "forever")
+	_, err = LoadSettings()
+	if err == nil || !strings.Contains(err.Error(), "APP_PORT") || !strings.Contains(err.Error(), "HTTP_IDLE_TIMEOUT") {
+		t.Errorf("LoadSettings() error = %v, want both variables reported", err)
+	}
+}
+
+func TestLoadTLSClientCA(t *testing.T) {
+	certPath, keyPath := writeCertificate(t)
+	config, err := loadTLS(certPath, keyPath, certPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ClientAuth != tls.RequireAndVerifyClientCert || config.ClientCAs == nil {
+		t.Errorf("ClientAuth = %v, want the client certificates required and verified", config.ClientAuth)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestTimeout(&Settings{
+		RequestTimeout: 50 * time.Millisecond,
+		RouteTimeouts:  map[string]time.Duration{"GET /slow": time.Second},
+	}))
+	var canceled atomic.Bool
+	sleep := func(ctx *gin.Context) {
+		time.Sleep(100 * time.Millisecond)
+		canceled.Store(ctx.Request.Context().Err() != nil)
+		ctx.Header("X-Handler", "done")
+		ctx.JSON(http.StatusOK, "done")
+	}
+	router.GET("/fast", sleep)
+	router.GET("/slow", sleep)
+
+	// the handler's response is dropped, and its request context canceled
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Body.String() != `"Request Timed Out"` || recorder.Header().Get("X-Handler") != "" {
+		t.Errorf("GET /fast = %d %s, want 503 with the timeout response only", recorder.Code, recorder.Body.String())
+	}
+	if !canceled.Load() {
+		t.Error("the request context of a timed out handler isn't canceled")
+	}
+
+	// the route has its own timeout
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != `"done"` || recorder.Header().Get("X-Handler") != "done" {
+		t.Errorf("GET /slow = %d %s, want 200 with the handler's response", recorder.Code, recorder.Body.String())
+	}
+	if canceled.Load() {
+		t.Error("the request context of a handler in time is canceled")
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(limitBody(8))
+	router.POST("/", func(ctx *gin.Context) {
+		if _, err := io.ReadAll(ctx.Request.Body); err != nil {
+			ctx.Status(http.StatusBadRequest)
+			return
+		}
+		ctx.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		want          int
+	}{
+		{"small", "12345678", 8, http.StatusOK},
+		{"large", "123456789", 9, http.StatusRequestEntityTooLarge},
+		{"large without content length", "123456789", -1, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		req.ContentLength = tt.contentLength
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		if recorder.Code != tt.want {
+			t.Errorf("%s body = %d, want %d", tt.name, recorder.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package httpConfig
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timeoutWriter holds the response of the handlers until they finish, like
+// http.TimeoutHandler, so the timeout response can be written in their place.
+type timeoutWriter struct {
+	gin.ResponseWriter // the response of the client, only written under mu
+	mu                 sync.Mutex
+	done               bool // the response was written, by the handlers or the timeout
+
+	header  http.Header
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *timeoutWriter) Header() http.Header { return w.header }
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	if !w.written {
+		w.status = status
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() { w.written = true }
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(data string) (int, error) {
+	w.written = true
+	return w.body.WriteString(data)
+}
+
+func (w *timeoutWriter) Status() int { return w.status }
+
+func (w *timeoutWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool { return w.written }
+
+// Flush is a no-op, the response is only sent when the handlers finish.
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, http.ErrNotSupported
+}
+
+// finish sends the response of the handlers, unless the timeout was sent already.
+func (w *timeoutWriter) finish() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done {
+		return
+	}
+	w.done = true
+	for key, values := range w.header {
+		w.ResponseWriter.Header()[key] = values
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.written {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+// timeout sends the timeout response, unless the handlers finished already. The
+// handlers go on, their response is dropped.
+func (w *timeoutWriter) timeout(route string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done {
+		return
+	}
+	w.done = true
+	body := []byte(`"` + domain.ErrRequestTimedOut.Error() + `"`)
+	header := w.ResponseWriter.Header()
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set("Content-Length", fmt.Sprint(len(body)))
+	w.ResponseWriter.WriteHeader(http.StatusServiceUnavailable)
+	w.ResponseWriter.Write(body)
+	// the client gets the whole response now, not when the handlers finish
+	w.ResponseWriter.Flush()
+	slog.Warn("Request timed out", "route", route)
+}
+
+// requestTimeout answers 503 when the handlers of a route take longer than its
+// timeout, the one of RouteTimeouts or the default RequestTimeout. The handlers run
+// on the goroutine of the request until they finish, only their response is dropped,
+// so the gin context is never used after the request ends. Their request context is
+// canceled at the timeout.
+func requestTimeout(settings *Settings) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.Request.Method + " " + ctx.FullPath()
+		duration, ok := settings.RouteTimeouts[route]
+		if !ok {
+			duration = settings.RequestTimeout
+		}
+		requestCtx, cancel := context.WithTimeout(ctx.Request.Context(), duration)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+
+		writer := &timeoutWriter{ResponseWriter: ctx.Writer, header: http.Header{}, status: http.StatusOK}
+		timer := time.AfterFunc(duration, func() { writer.timeout(route) })
+		ctx.Writer = writer
+		defer func() {
+			// on a panic, the timeout response isn't sent either, Recovery answers
+			timer.Stop()
+			writer.mu.Lock()
+			writer.done = true
+			writer.mu.Unlock()
+			ctx.Writer = writer.ResponseWriter
+		}()
+
+		ctx.Next()
+		writer.finish()
+	}
+}
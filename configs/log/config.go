@@ -1,75 +1,107 @@
 package logConfig
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
-	"log"
 	"log/slog"
 	"os"
-
-	"github.com/fatih/color"
+	"strconv"
+	"strings"
 )
 
-type HandlerOptions struct {
-	slog.HandlerOptions
-}
+// The formats of the logs.
+const (
+	FormatJSON   = "json"   // one JSON object per record, for the log collectors
+	FormatLogfmt = "logfmt" // key=value pairs
+	FormatPretty = "pretty" // coloured, for a terminal
+)
 
-type Handler struct {
-	slog.Handler
-	logger *log.Logger
+// Settings are the settings of the logs, read from the environment by LoadSettings.
+type Settings struct {
+	Level     slog.Level
+	Format    string
+	AddSource bool     // adds the file:line of the call to each record
+	Redact    []string // keys redacted on top of the default sensitive keys
 }
 
-func (handler *Handler) Handle(ctx context.Context, record slog.Record) error {
-	level := record.Level.String() + ":"
-	switch record.Level {
-	case slog.LevelDebug:
-		level = color.MagentaString(level)
-	case slog.LevelInfo:
-		level = color.BlueString(level)
-	case slog.LevelWarn:
-		level = color.YellowString(level)
-	case slog.LevelError:
-		level = color.RedString(level)
+// LoadSettings reads the settings of the logs from the environment:
+//   - LOG_LEVEL: debug, info, warn or error (debug in development, info otherwise).
+//   - LOG_FORMAT: json, logfmt or pretty (pretty in development, json otherwise).
+//   - LOG_SOURCE: true to add the source location of the records.
+//   - LOG_REDACT_KEYS: comma-separated keys to redact, e.g. mnemonic,seed.
+//
+// Returns:
+//   - A pointer to Settings if successful.
+//   - An error listing every invalid variable.
+func LoadSettings() (*Settings, error) {
+	var errs []error
+	s := &Settings{Level: slog.LevelInfo, Format: FormatJSON}
+	if os.Getenv("APP_ENV") == "development" {
+		s.Level, s.Format = slog.LevelDebug, FormatPretty
 	}
 
-	timeStr := record.Time.Format("[15:05:05.000]")
-	msg := color.CyanString(record.Message)
-
-	if record.NumAttrs() > 0 {
-		fields := make(map[string]any, record.NumAttrs())
-		record.Attrs(func(a slog.Attr) bool {
-			fields[a.Key] = a.Value.Any()
-			return true
-		})
-		attrs, err := json.MarshalIndent(fields, "", "  ")
-		if err != nil {
-			return err
+	if env := os.Getenv("LOG_LEVEL"); env != "" {
+		if err := s.Level.UnmarshalText([]byte(env)); err != nil {
+			errs = append(errs, fmt.Errorf("invalid LOG_LEVEL %q, want debug, info, warn or error", env))
 		}
-		handler.logger.Println(timeStr, level, msg, "\n"+string(attrs))
-	} else {
-		handler.logger.Println(timeStr, level, msg)
 	}
-
-	return nil
+	if env := os.Getenv("LOG_FORMAT"); env != "" {
+		switch s.Format = strings.ToLower(env); s.Format {
+		case FormatJSON, FormatLogfmt, FormatPretty:
+		default:
+			errs = append(errs, fmt.Errorf("invalid LOG_FORMAT %q, want json, logfmt or pretty", env))
+		}
+	}
+	if env := os.Getenv("LOG_SOURCE"); env != "" {
+		var err error
+		if s.AddSource, err = strconv.ParseBool(env); err != nil {
+			errs = append(errs, fmt.Errorf("invalid LOG_SOURCE %q, want true or false", env))
+		}
+	}
+	for _, key := range strings.Split(os.Getenv("LOG_REDACT_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			s.Redact = append(s.Redact, key)
+		}
+	}
+	return s, errors.Join(errs...)
 }
 
-func NewHandler(
-	out io.Writer,
-	opts HandlerOptions,
-) *Handler {
-	return &Handler{
-		Handler: slog.NewJSONHandler(out, &opts.HandlerOptions),
-		logger:  log.New(out, "", 0),
+// NewHandler creates the handler of the logs with the given settings. The secrets
+// are redacted (see Redact), and the records logged with a traced context carry
+// its trace and span IDs.
+// Parameters:
+//   - out: The writer of the logs.
+//   - settings: The level, format, source and redacted keys.
+//
+// Returns:
+//   - The slog.Handler writing the logs to out.
+func NewHandler(out io.Writer, settings *Settings) slog.Handler {
+	opts := &slog.HandlerOptions{
+		AddSource:   settings.AddSource,
+		Level:       settings.Level,
+		ReplaceAttr: Redact(settings.Redact...),
+	}
+	var handler slog.Handler
+	switch settings.Format {
+	case FormatLogfmt:
+		handler = slog.NewTextHandler(out, opts)
+	case FormatPretty:
+		handler = NewPrettyHandler(out, opts)
+	default:
+		handler = slog.NewJSONHandler(out, opts)
 	}
+	return TraceHandler{handler}
 }
 
-func Config() {
-	opts := HandlerOptions{
-		slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		},
+// Config makes the logger of the settings of the environment the default one.
+// Returns:
+//   - An error listing the invalid variables, the default logger is then unchanged.
+func Config() error {
+	settings, err := LoadSettings()
+	if err != nil {
+		return err
 	}
-	handler := NewHandler(os.Stdout, opts)
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(NewHandler(os.Stdout, settings)))
+	return nil
 }
@@ -0,0 +1,78 @@
+package logConfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestLoadSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    Settings
+		wantErr bool
+	}{
+		{name: "production defaults", env: map[string]string{"APP_ENV": "production"}, want: Settings{Level: slog.LevelInfo, Format: FormatJSON}},
+		{name: "development defaults", env: map[string]string{"APP_ENV": "development"}, want: Settings{Level: slog.LevelDebug, Format: FormatPretty}},
+		{
+			name: "overridden",
+			env:  map[string]string{"APP_ENV": "development", "LOG_LEVEL": "WARN", "LOG_FORMAT": "logfmt", "LOG_SOURCE": "true", "LOG_REDACT_KEYS": "seed, iban,"},
+			want: Settings{Level: slog.LevelWarn, Format: FormatLogfmt, AddSource: true, Redact: []string{"seed", "iban"}},
+		},
+		{name: "invalid level", env: map[string]string{"LOG_LEVEL": "verbose"}, wantErr: true},
+		{name: "invalid format", env: map[string]string{"LOG_FORMAT": "xml"}, wantErr: true},
+		{name: "invalid source", env: map[string]string{"LOG_SOURCE": "maybe"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"APP_ENV", "LOG_LEVEL", "LOG_FORMAT", "LOG_SOURCE", "LOG_REDACT_KEYS"} {
+				t.Setenv(name, tt.env[name])
+			}
+			got, err := LoadSettings()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Level != tt.want.Level || got.Format != tt.want.Format || got.AddSource != tt.want.AddSource || !slices.Equal(got.Redact, tt.want.Redact) {
+				t.Errorf("LoadSettings() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	color.NoColor = true
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatJSON, `"msg":"Transaction sent","tx":{"hash":"0x01","privateKey":"[REDACTED]"}`},
+		{FormatLogfmt, `msg="Transaction sent" tx.hash=0x01 tx.privateKey=[REDACTED]`},
+		{FormatPretty, `Transaction sent tx.hash=0x01 tx.privateKey=[REDACTED]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(NewHandler(&buf, &Settings{Level: slog.LevelInfo, Format: tt.format}))
+
+			logger.Debug("Filtered out")
+			logger.WithGroup("tx").Info("Transaction sent", "hash", "0x01", "privateKey", "8f2a5594")
+
+			line := buf.String()
+			if strings.Count(line, "\n") != 1 || !strings.Contains(line, tt.want) {
+				t.Errorf("log = %q, want one line with %q", line, tt.want)
+			}
+			if tt.format == FormatJSON && !json.Valid(buf.Bytes()) {
+				t.Errorf("log = %q, want JSON", line)
+			}
+		})
+	}
+}
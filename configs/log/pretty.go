@@ -0,0 +1,115 @@
+package logConfig
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// PrettyHandler writes each record on a line for a terminal: its time, coloured
+// level and message, then its attributes as key=value, those of the groups prefixed
+// by the group (request.status=200). The attributes are formatted by a text handler,
+// so WithAttrs, WithGroup and ReplaceAttr behave as in the standard handlers.
+type PrettyHandler struct {
+	attrs slog.Handler // writes the attributes only, to buf
+	level slog.Leveler
+	out   io.Writer
+
+	// shared by the handlers derived with WithAttrs and WithGroup, which write to
+	// the same out
+	mu  *sync.Mutex
+	buf *bytes.Buffer
+}
+
+// NewPrettyHandler creates a PrettyHandler.
+// Parameters:
+//   - out: The writer of the logs.
+//   - opts: The level, source and ReplaceAttr of the handler, nil for the defaults.
+//
+// Returns:
+//   - A pointer to the PrettyHandler.
+func NewPrettyHandler(out io.Writer, opts *slog.HandlerOptions) *PrettyHandler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+	buf := &bytes.Buffer{}
+	replace := opts.ReplaceAttr
+	attrs := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		AddSource: opts.AddSource,
+		Level:     slog.LevelDebug, // Enabled is checked by the PrettyHandler
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 {
+				switch a.Key {
+				case slog.TimeKey, slog.LevelKey, slog.MessageKey:
+					// written by the PrettyHandler
+					return slog.Attr{}
+				case slog.SourceKey:
+					if source, ok := a.Value.Any().(*slog.Source); ok {
+						return slog.String(slog.SourceKey, filepath.Base(source.File)+":"+strconv.Itoa(source.Line))
+					}
+				}
+			}
+			if replace != nil {
+				return replace(groups, a)
+			}
+			return a
+		},
+	})
+	level := opts.Level
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &PrettyHandler{attrs: attrs, level: level, out: out, mu: &sync.Mutex{}, buf: buf}
+}
+
+func (h *PrettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *PrettyHandler) Handle(ctx context.Context, record slog.Record) error {
+	level := record.Level.String() + ":"
+	switch {
+	case record.Level < slog.LevelInfo:
+		level = color.MagentaString(level)
+	case record.Level < slog.LevelWarn:
+		level = color.BlueString(level)
+	case record.Level < slog.LevelError:
+		level = color.YellowString(level)
+	default:
+		level = color.RedString(level)
+	}
+	line := level + " " + color.CyanString(record.Message)
+	if !record.Time.IsZero() {
+		line = record.Time.Format("15:04:05.000") + " " + line
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.buf.Reset()
+	if err := h.attrs.Handle(ctx, record); err != nil {
+		return err
+	}
+	if attrs := bytes.TrimSpace(h.buf.Bytes()); len(attrs) > 0 {
+		line += " " + string(attrs)
+	}
+	_, err := io.WriteString(h.out, line+"\n")
+	return err
+}
+
+func (h *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *h
+	derived.attrs = h.attrs.WithAttrs(attrs)
+	return &derived
+}
+
+func (h *PrettyHandler) WithGroup(name string) slog.Handler {
+	derived := *h
+	derived.attrs = h.attrs.WithGroup(name)
+	return &derived
+}
@@ -0,0 +1,107 @@
+package logConfig
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestPrettyHandler(t *testing.T) {
+	color.NoColor = true
+	tests := []struct {
+		name string
+		opts *slog.HandlerOptions
+		log  func(logger *slog.Logger)
+		want string // regexp of the line
+	}{
+		{
+			name: "message only",
+			log:  func(logger *slog.Logger) { logger.Info("Application started") },
+			want: `^\d{2}:\d{2}:\d{2}\.\d{3} INFO: Application started\n$`,
+		},
+		{
+			name: "attributes and groups",
+			log: func(logger *slog.Logger) {
+				logger.With("app", "besu").WithGroup("request").With("method", "GET").Warn("Slow request", slog.Int("status", 200), slog.Group("node", "url", "http://localhost:8545"))
+			},
+			want: `^\S+ WARN: Slow request app=besu request.method=GET request.status=200 request.node.url=http://localhost:8545\n$`,
+		},
+		{
+			name: "level",
+			opts: &slog.HandlerOptions{Level: slog.LevelError},
+			log: func(logger *slog.Logger) {
+				logger.Warn("Filtered out")
+				logger.Error("Kept")
+			},
+			want: `^\S+ ERROR: Kept\n$`,
+		},
+		{
+			name: "source",
+			opts: &slog.HandlerOptions{AddSource: true},
+			log:  func(logger *slog.Logger) { logger.Info("Located") },
+			want: `^\S+ INFO: Located source=pretty_test\.go:\d+\n$`,
+		},
+		{
+			name: "replaced",
+			opts: &slog.HandlerOptions{ReplaceAttr: Redact()},
+			log:  func(logger *slog.Logger) { logger.Info("Signed", "privateKey", "8f2a5594") },
+			want: `^\S+ INFO: Signed privateKey=\[REDACTED\]\n$`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(slog.New(NewPrettyHandler(&buf, tt.opts)))
+			if !regexp.MustCompile(tt.want).Match(buf.Bytes()) {
+				t.Errorf("log = %q, want %s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPrettyHandlerTime(t *testing.T) {
+	color.NoColor = true
+	var buf bytes.Buffer
+	handler := NewPrettyHandler(&buf, nil)
+
+	record := slog.NewRecord(time.Date(2025, 1, 2, 15, 4, 5, 6e6, time.UTC), slog.LevelInfo, "At", 0)
+	if err := handler.Handle(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+	// the minutes were printed as the seconds
+	if got := buf.String(); got != "15:04:05.006 INFO: At\n" {
+		t.Errorf("log = %q, want the hour, minutes, seconds and milliseconds", got)
+	}
+}
+
+func TestPrettyHandlerConcurrency(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewPrettyHandler(&buf, nil))
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.With("worker", i).Info("Working", "step", i)
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 20 {
+		t.Fatalf("%d lines logged, want 20", len(lines))
+	}
+	for _, line := range lines {
+		if !regexp.MustCompile(`worker=(\d+) step=(\d+)$`).MatchString(line) {
+			t.Errorf("line %q is mixed with another", line)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package logConfig
+
+import (
+	"crypto/ecdsa"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// redacted replaces the value of a redacted attribute.
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are the parts of the keys of the attributes holding secrets. The
+// keys are compared in lower case without separators, so privateKey, private_key
+// and X-API-Key all match.
+var sensitiveKeys = []string{"privatekey", "password", "passwd", "secret", "token", "apikey", "authorization", "cookie", "mnemonic"}
+
+// urlPassword matches the password of the URLs, e.g. of DATABASE_URL or of a Besu
+// node behind basic auth, also within an error message.
+var urlPassword = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^:/@\s]*):[^@\s]*@`)
+
+// Redact returns the ReplaceAttr of the handlers hiding the secrets: the values of
+// the sensitive keys, the private keys, and the passwords of the URLs. The signing
+// options of a transaction are replaced by their signer.
+// Parameters:
+//   - keys: The keys to redact on top of the default sensitive keys.
+//
+// Returns:
+//   - The ReplaceAttr function of slog.HandlerOptions.
+func Redact(keys ...string) func(groups []string, a slog.Attr) slog.Attr {
+	sensitive := append([]string{}, sensitiveKeys...)
+	for _, key := range keys {
+		sensitive = append(sensitive, normalize(key))
+	}
+	return func(_ []string, a slog.Attr) slog.Attr {
+		key := normalize(a.Key)
+		for _, part := range sensitive {
+			if strings.Contains(key, part) {
+				return slog.String(a.Key, redacted)
+			}
+		}
+		switch a.Value.Kind() {
+		case slog.KindString:
+			a.Value = slog.StringValue(redactURLs(a.Value.String()))
+		case slog.KindAny:
+			switch value := a.Value.Any().(type) {
+			case *ecdsa.PrivateKey, ecdsa.PrivateKey:
+				return slog.String(a.Key, redacted)
+			case *bind.TransactOpts:
+				return slog.String(a.Key, value.From.Hex())
+			case error:
+				if message := redactURLs(value.Error()); message != value.Error() {
+					a.Value = slog.StringValue(message)
+				}
+			}
+		}
+		return a
+	}
+}
+
+func normalize(key string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "", " ", "").Replace(strings.ToLower(key))
+}
+
+func redactURLs(value string) string {
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	return urlPassword.ReplaceAllString(value, "${1}:xxxxx@")
+}
@@ -0,0 +1,52 @@
+package logConfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTraceHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(TraceHandler{slog.NewJSONHandler(&buf, nil)}).With("app", "test").WithGroup("request")
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "span")
+	defer span.End()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantTrace bool
+	}{
+		{name: "without span", ctx: context.Background()},
+		{name: "with span", ctx: ctx, wantTrace: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			logger.InfoContext(tt.ctx, "message", "key", "value")
+
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			request, _ := record["request"].(map[string]any)
+			if record["app"] != "test" || request["key"] != "value" {
+				t.Errorf("record = %v, want the attributes of the logger", record)
+			}
+			traceID, spanID := request["trace_id"], request["span_id"]
+			if !tt.wantTrace {
+				if traceID != nil || spanID != nil {
+					t.Errorf("record = %v, want no trace", record)
+				}
+				return
+			}
+			if traceID != span.SpanContext().TraceID().String() || spanID != span.SpanContext().SpanID().String() {
+				t.Errorf("record = %v, want the trace %s and the span %s", record, span.SpanContext().TraceID(), span.SpanContext().SpanID())
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package metricsConfig
+
+import (
+	"goledger-challenge-besu/configs/cache"
+	"goledger-challenge-besu/configs/db"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dbCollector exports the statistics of the connections of the database pool,
+// read on each scrape.
+type dbCollector struct {
+	db *dbConfig.DB
+
+	acquired      *prometheus.Desc
+	idle          *prometheus.Desc
+	total         *prometheus.Desc
+	max           *prometheus.Desc
+	emptyAcquires *prometheus.Desc
+	acquireWait   *prometheus.Desc
+}
+
+// NewDBCollector builds the collector of the database pool statistics.
+func NewDBCollector(db *dbConfig.DB) prometheus.Collector {
+	labels := prometheus.Labels{"backend": db.Backend.Name()}
+	return &dbCollector{
+		db:            db,
+		acquired:      prometheus.NewDesc("db_pool_acquired_connections", "Connections of the database pool in use.", nil, labels),
+		idle:          prometheus.NewDesc("db_pool_idle_connections", "Connections of the database pool open and idle.", nil, labels),
+		total:         prometheus.NewDesc("db_pool_total_connections", "Connections of the database pool open.", nil, labels),
+		max:           prometheus.NewDesc("db_pool_max_connections", "Maximum of connections of the database pool, 0 when unlimited.", nil, labels),
+		emptyAcquires: prometheus.NewDesc("db_pool_empty_acquires_total", "Acquires that waited for a connection of the database pool.", nil, labels),
+		acquireWait:   prometheus.NewDesc("db_pool_acquire_wait_seconds_total", "Time waited for a connection of the database pool.", nil, labels),
+	}
+}
+
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.emptyAcquires
+	ch <- c.acquireWait
+}
+
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stats.Acquired))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stats.Total))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stats.Max))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stats.EmptyAcquires))
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, stats.AcquireWait.Seconds())
+}
+
+// cacheCollector exports the counters of the cache of the on-chain reads.
+type cacheCollector struct {
+	cache  *cacheConfig.Cache
+	hits   *prometheus.Desc
+	misses *prometheus.Desc
+	errors *prometheus.Desc
+}
+
+// NewCacheCollector builds the collector of the cache counters.
+func NewCacheCollector(cache *cacheConfig.Cache) prometheus.Collector {
+	labels := prometheus.Labels{"backend": cache.Stats().Backend}
+	return &cacheCollector{
+		cache:  cache,
+		hits:   prometheus.NewDesc("cache_hits_total", "Reads served by the cache of the on-chain reads.", nil, labels),
+		misses: prometheus.NewDesc("cache_misses_total", "Reads missing the cache of the on-chain reads, failed ones included.", nil, labels),
+		errors: prometheus.NewDesc("cache_errors_total", "Failed reads and writes of the cache of the on-chain reads.", nil, labels),
+	}
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.errors
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.cache.Stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(stats.Errors))
+}
@@ -0,0 +1,84 @@
+package metricsConfig
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goledger-challenge-besu/configs/cache"
+	"goledger-challenge-besu/configs/db"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+func TestDBCollector(t *testing.T) {
+	t.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "app.db"))
+	ctx := context.Background()
+	db, err := dbConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err = db.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the connection of the ping is back in the pool, and SQLite has a single one
+	want := `
+# HELP db_pool_acquired_connections Connections of the database pool in use.
+# TYPE db_pool_acquired_connections gauge
+db_pool_acquired_connections{backend="sqlite"} 0
+# HELP db_pool_idle_connections Connections of the database pool open and idle.
+# TYPE db_pool_idle_connections gauge
+db_pool_idle_connections{backend="sqlite"} 1
+# HELP db_pool_max_connections Maximum of connections of the database pool, 0 when unlimited.
+# TYPE db_pool_max_connections gauge
+db_pool_max_connections{backend="sqlite"} 1
+`
+	collector := NewDBCollector(db)
+	if err = testutil.CollectAndCompare(collector, strings.NewReader(want),
+		"db_pool_acquired_connections", "db_pool_idle_connections", "db_pool_max_connections"); err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(collector); count != 6 {
+		t.Errorf("collected %d metrics, want 6", count)
+	}
+}
+
+func TestCacheCollector(t *testing.T) {
+	t.Setenv("CACHE_URL", "memory://")
+	ctx := context.Background()
+	cache, err := cacheConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.Close()
+	cache.Set(ctx, "key", []byte("value"))
+	cache.Get(ctx, "key")
+	cache.Get(ctx, "key")
+	cache.Get(ctx, "missing")
+
+	want := `
+# HELP cache_errors_total Failed reads and writes of the cache of the on-chain reads.
+# TYPE cache_errors_total counter
+cache_errors_total{backend="memory"} 0
+# HELP cache_hits_total Reads served by the cache of the on-chain reads.
+# TYPE cache_hits_total counter
+cache_hits_total{backend="memory"} 2
+# HELP cache_misses_total Reads missing the cache of the on-chain reads, failed ones included.
+# TYPE cache_misses_total counter
+cache_misses_total{backend="memory"} 1
+`
+	if err = testutil.CollectAndCompare(NewCacheCollector(cache), strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
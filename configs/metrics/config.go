@@ -0,0 +1,89 @@
+package metricsConfig
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds the metrics exported on /metrics: the ones below, the Go runtime
+// and process metrics, and the collectors registered by Register.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// latencyBuckets are the buckets of the durations of the HTTP requests and RPC calls, in seconds.
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+var (
+	// HTTPRequestDuration is the duration of the HTTP requests, by method, route
+	// (the template, e.g. /api/v1/blocks/:numberOrHash) and status.
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of the HTTP requests, by method, route and status.",
+		Buckets: latencyBuckets,
+	}, []string{"method", "route", "status"})
+
+	// RPCRequests counts the JSON-RPC calls to the Besu nodes, by method and outcome
+	// (ok, error when the node answered with one, or failure when the node failed).
+	RPCRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "besu_rpc_requests_total",
+		Help: "JSON-RPC calls to the Besu nodes, by method and outcome (ok, error or failure).",
+	}, []string{"method", "outcome"})
+
+	// RPCRequestDuration is the duration of the JSON-RPC calls to the Besu nodes, by method.
+	RPCRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "besu_rpc_request_duration_seconds",
+		Help:    "Duration of the JSON-RPC calls to the Besu nodes, by method.",
+		Buckets: latencyBuckets,
+	}, []string{"method"})
+
+	// LatestBlock is the highest block number seen by the health checks of the nodes.
+	LatestBlock = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "besu_latest_block_number",
+		Help: "Highest block number seen on the Besu nodes.",
+	})
+
+	// Transactions counts the transactions sent by the service, by outcome
+	// (submitted, mined, reverted or dropped).
+	Transactions = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "besu_transactions_total",
+		Help: "Transactions sent by the service, by outcome (submitted, mined, reverted or dropped).",
+	}, []string{"outcome"})
+
+	// TransactionTimeToMine is the time from the submission of a transaction to its
+	// block, mined or reverted.
+	TransactionTimeToMine = factory.NewHistogram(prometheus.HistogramOpts{
+		Name:    "besu_transaction_time_to_mine_seconds",
+		Help:    "Time from the submission of a transaction to its block.",
+		Buckets: []float64{1, 2, 5, 10, 20, 30, 60, 120, 300, 600},
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	// the outcomes are exported from the start, so the rates don't miss the first ones
+	for _, outcome := range []string{"submitted", "mined", "reverted", "dropped"} {
+		Transactions.WithLabelValues(outcome)
+	}
+}
+
+// Register adds collectors to the Registry, replacing the ones already registered
+// with the same metrics (e.g. by a previous instance in the tests).
+func Register(collectors ...prometheus.Collector) {
+	for _, collector := range collectors {
+		Registry.Unregister(collector)
+		Registry.MustRegister(collector)
+	}
+}
+
+// Handler serves the metrics of the Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
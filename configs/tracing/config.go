@@ -0,0 +1,115 @@
+package tracingConfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation is the name of the tracer of the spans of the service.
+const instrumentation = "goledger-challenge-besu"
+
+// Tracing exports the spans of the service over OTLP (HTTP/protobuf).
+// It is disabled (the spans are no-ops) when no OTLP endpoint is configured.
+type Tracing struct {
+	provider *sdktrace.TracerProvider
+}
+
+// New installs the tracer provider exporting to the OTLP endpoint of the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT (or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) variable,
+// with the rest of the standard variables: OTEL_EXPORTER_OTLP_HEADERS, OTEL_SERVICE_NAME
+// (APP_NAME by default), OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG.
+// Parameters:
+//   - ctx: The context of the exporter connection.
+//
+// Returns:
+//   - A pointer to Tracing, disabled when no endpoint is configured.
+//   - An error if the exporter or the resource can't be built.
+func New(ctx *context.Context) (*Tracing, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return &Tracing{}, nil
+	}
+	exporter, err := otlptracehttp.New(*ctx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP exporter: %w", err)
+	}
+
+	name := os.Getenv("OTEL_SERVICE_NAME")
+	if name == "" {
+		name = os.Getenv("APP_NAME")
+	}
+	// the OTEL_RESOURCE_ATTRIBUTES are merged over the service name
+	res, err := resource.Merge(
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(name)),
+		resource.Environment(),
+	)
+	if err != nil && !errors.Is(err, resource.ErrPartialResource) {
+		return nil, fmt.Errorf("invalid OTEL_RESOURCE_ATTRIBUTES: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	Install(provider)
+	return &Tracing{provider}, nil
+}
+
+// Install makes provider the tracer provider of the spans of the service, and
+// propagates the W3C trace context and baggage of the requests.
+func Install(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Enabled tells whether the spans are exported.
+func (t *Tracing) Enabled() bool {
+	return t.provider != nil
+}
+
+// Shutdown exports the spans still buffered and stops the exporter.
+func (t *Tracing) Shutdown(ctx context.Context) error {
+	if t.provider == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
+
+// Start starts a span, child of the span of ctx if any.
+// Parameters:
+//   - ctx: The context holding the parent span.
+//   - name: The name of the span, e.g. SmartContractService.SetValue.
+//   - attrs: The attributes of the span.
+//
+// Returns:
+//   - The context holding the new span, and the span to End.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends a span, recording err as its error when not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Join returns root carrying the span of ctx: the work done with it is traced
+// under the span of the request, while it is still canceled by root only (a
+// transaction sent by a request timed out is still waited for).
+func Join(root context.Context, ctx context.Context) context.Context {
+	return trace.ContextWithSpan(root, trace.SpanFromContext(ctx))
+}
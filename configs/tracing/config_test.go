@@ -0,0 +1,79 @@
+package tracingConfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	ctx := context.Background()
+	tracing, err := New(&ctx)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tracing.Enabled() {
+		t.Error("Enabled() = true without endpoint, want false")
+	}
+	if err = tracing.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestNewEnabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("APP_NAME", "besu-api")
+	ctx := context.Background()
+	tracing, err := New(&ctx)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer tracing.Shutdown(ctx)
+	if !tracing.Enabled() {
+		t.Error("Enabled() = false with an endpoint, want true")
+	}
+}
+
+func TestStartEndJoin(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	Install(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	request, cancel := context.WithCancel(context.Background())
+	request, parent := Start(request, "request")
+	cancel()
+
+	// the work joined to the root context isn't canceled with the request, but is its child
+	ctx := Join(context.Background(), request)
+	if ctx.Err() != nil {
+		t.Errorf("joined context error = %v, want nil", ctx.Err())
+	}
+	_, child := Start(ctx, "child", attribute.String("tx.hash", "0x01"))
+	End(child, errors.New("reverted"))
+	End(parent, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("%d spans exported, want 2", len(spans))
+	}
+	if spans[0].Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("child span isn't a child of the span of the request")
+	}
+	if spans[0].Status.Code != codes.Error || spans[0].Status.Description != "reverted" || len(spans[0].Events) != 1 {
+		t.Errorf("child status = %v with %d events, want the error recorded", spans[0].Status, len(spans[0].Events))
+	}
+	if spans[1].Status.Code != codes.Unset {
+		t.Errorf("parent status = %v, want unset", spans[1].Status)
+	}
+	if trace.SpanFromContext(Join(context.Background(), context.Background())).SpanContext().IsValid() {
+		t.Error("joined context without span holds a span")
+	}
+}
@@ -0,0 +1,142 @@
+package accountApp
+
+import (
+	"net/http"
+	"strconv"
+
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTransactionsLimit = 50
+	maxTransactionsLimit     = 500
+)
+
+// AccountHandler handles HTTP requests related to accounts and the configured signers.
+type AccountHandler struct {
+	// The service layer for reading accounts.
+	service *AccountService
+}
+
+// NewHandler initializes a new AccountHandler.
+// Parameters:
+//   - service: The AccountService used for business logic.
+//
+// Returns:
+//   - A pointer to a newly created AccountHandler.
+func NewHandler(service *AccountService) *AccountHandler {
+	return &AccountHandler{service}
+}
+
+// statusCode maps the errors returned by the service to HTTP status codes.
+func statusCode(err error) int {
+	switch err {
+	case domain.ErrDataNotFound:
+		return http.StatusNotFound
+	case domain.ErrNodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// uintQuery reads an optional unsigned integer query param, defaultValue when it is missing.
+func uintQuery(ctx *gin.Context, key string, defaultValue uint64) (uint64, bool) {
+	valueStr, ok := ctx.GetQuery(key)
+	if !ok {
+		return defaultValue, true
+	}
+	value, err := strconv.ParseUint(valueStr, 10, 64)
+	return value, err == nil
+}
+
+// GetSigners retrieves the configured signers with their balance compared to the minimum balance.
+// HTTP Method: GET
+// URL: /accounts/signers
+// Responses:
+//   - 200: The signers (balance, nonces, lowBalance).
+//   - 500: Internal server error if the RPC call fails.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *AccountHandler) GetSigners(ctx *gin.Context) {
+	signers, err := r.service.GetSigners()
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, signers)
+}
+
+// GetAccount retrieves the balance, nonces and code presence of an account.
+// HTTP Method: GET
+// URL: /accounts/:address
+// Path Parameters:
+//   - address (string): The account address.
+//
+// Query Parameters:
+//   - block (string, optional): Block tag or number (defaults to "latest").
+//
+// Responses:
+//   - 200: The account.
+//   - 400: Bad request if the address or the block is invalid.
+//   - 500: Internal server error if the RPC call fails.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *AccountHandler) GetAccount(ctx *gin.Context) {
+	address := ctx.Param("address")
+	if !common.IsHexAddress(address) {
+		ctx.JSON(http.StatusBadRequest, "Invalid path param address")
+		return
+	}
+	block, err := domain.ParseBlockTag(ctx.Query("block"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param block")
+		return
+	}
+	account, err := r.service.GetAccount(common.HexToAddress(address), block)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, account)
+}
+
+// GetTransactions retrieves the mined transactions sent by a configured signer, newest first.
+// HTTP Method: GET
+// URL: /accounts/:address/transactions
+// Path Parameters:
+//   - address (string): The signer address.
+//
+// Query Parameters:
+//   - limit (int, optional): Maximum number of transactions (defaults to 50, at most 500).
+//   - offset (int, optional): Number of transactions to skip.
+//
+// Responses:
+//   - 200: The transactions collected so far.
+//   - 400: Bad request if the address, limit or offset is invalid.
+//   - 404: Not found if the address isn't a configured signer.
+//   - 500: Internal server error if the database query fails.
+func (r *AccountHandler) GetTransactions(ctx *gin.Context) {
+	address := ctx.Param("address")
+	if !common.IsHexAddress(address) {
+		ctx.JSON(http.StatusBadRequest, "Invalid path param address")
+		return
+	}
+	limit, ok := uintQuery(ctx, "limit", defaultTransactionsLimit)
+	if !ok || limit == 0 || limit > maxTransactionsLimit {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param limit")
+		return
+	}
+	offset, ok := uintQuery(ctx, "offset", 0)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param offset")
+		return
+	}
+	transactions, err := r.service.GetTransactions(common.HexToAddress(address), limit, offset)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, transactions)
+}
@@ -0,0 +1,89 @@
+package accountApp
+
+import (
+	"context"
+	"log/slog"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/account"
+	"goledger-challenge-besu/internal/domain/explorer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type AccountService struct {
+	repositoryDB       *accountDomain.AccountRepositoryDB
+	repositoryBesu     *accountDomain.AccountRepositoryBesu
+	repositoryExplorer *explorerDomain.ExplorerRepositoryBesu
+	watcher            *watcher
+}
+
+// NewService initializes a new AccountService, with the worker watching the signers.
+// Returns:
+//   - A pointer to AccountService if successful.
+//   - An error if the settings of the worker are invalid.
+func NewService(
+	ctx *context.Context,
+	repositoryDB *accountDomain.AccountRepositoryDB,
+	repositoryBesu *accountDomain.AccountRepositoryBesu,
+	repositoryExplorer *explorerDomain.ExplorerRepositoryBesu) (*AccountService, error) {
+	service := &AccountService{
+		repositoryDB:       repositoryDB,
+		repositoryBesu:     repositoryBesu,
+		repositoryExplorer: repositoryExplorer,
+	}
+	watcher, err := newWatcher(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	service.watcher = watcher
+	return service, nil
+}
+
+// Watch starts the background worker collecting the signer transactions and
+// checking the signer balances. It does nothing if no signer is configured.
+func (r *AccountService) Watch() {
+	if len(r.repositoryBesu.Signers) == 0 {
+		slog.Warn("No signer configured (SIGNER_ADDRESSES), signer transactions and balances are not monitored")
+		return
+	}
+	go r.watcher.run()
+}
+
+func (r *AccountService) GetAccount(address common.Address, block rpc.BlockNumber) (accountDomain.Account, error) {
+	account, err := r.repositoryBesu.GetAccount(address, block)
+	if err != nil {
+		slog.Error("Erro getting account from AccountRepositoryBesu.GetAccount", "address", address.Hex(), "block", block.String())
+		return accountDomain.Account{}, err
+	}
+	return account, nil
+}
+
+func (r *AccountService) GetSigners() ([]accountDomain.Signer, error) {
+	signers := make([]accountDomain.Signer, len(r.repositoryBesu.Signers))
+	for i, address := range r.repositoryBesu.Signers {
+		account, err := r.GetAccount(address, rpc.LatestBlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = accountDomain.Signer{
+			Account:    account,
+			MinBalance: r.repositoryBesu.MinBalance,
+			LowBalance: account.Balance.Cmp(r.repositoryBesu.MinBalance) < 0,
+		}
+	}
+	return signers, nil
+}
+
+func (r *AccountService) GetTransactions(address common.Address, limit, offset uint64) ([]accountDomain.SignerTransaction, error) {
+	if !r.repositoryBesu.IsSigner(address) {
+		return nil, domain.ErrDataNotFound
+	}
+	transactions, err := r.repositoryDB.GetTransactions(address.Hex(), limit, offset)
+	if err != nil {
+		slog.Error("Erro getting signer transactions from AccountRepositoryDB.GetTransactions", "address", address.Hex())
+		return nil, err
+	}
+	return transactions, nil
+}
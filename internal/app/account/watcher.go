@@ -0,0 +1,201 @@
+package accountApp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/account"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxBlocksPerScan bounds the blocks read in a single tick, so a long catch up
+// is saved (and resumed after a restart) in small steps.
+const maxBlocksPerScan = 100
+
+// watcher periodically scans the new blocks for transactions sent by the signers
+// and checks the signer balances against the minimum balance.
+// QBFT blocks are final, so the scanned blocks are never revisited.
+type watcher struct {
+	ctx        *context.Context
+	service    *AccountService
+	interval   time.Duration
+	startBlock uint64
+	webhookURL string
+	httpClient *http.Client
+	// lowBalance keeps the last known state of each signer, so the alert fires
+	// once when the balance crosses the threshold instead of on every tick.
+	lowBalance map[common.Address]bool
+}
+
+// newWatcher builds the watcher, scanning every SIGNER_SCAN_INTERVAL (5s by default)
+// from SIGNER_SCAN_START_BLOCK (0 by default).
+// Returns:
+//   - A pointer to watcher if successful.
+//   - An error if SIGNER_SCAN_INTERVAL or SIGNER_SCAN_START_BLOCK is invalid.
+func newWatcher(ctx *context.Context, service *AccountService) (*watcher, error) {
+	interval := 5 * time.Second
+	if env := os.Getenv("SIGNER_SCAN_INTERVAL"); env != "" {
+		var err error
+		if interval, err = time.ParseDuration(env); err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid SIGNER_SCAN_INTERVAL %q, want a positive duration", env)
+		}
+	}
+	var startBlock uint64
+	if env := os.Getenv("SIGNER_SCAN_START_BLOCK"); env != "" {
+		var err error
+		if startBlock, err = strconv.ParseUint(env, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid SIGNER_SCAN_START_BLOCK %q, want a block number", env)
+		}
+	}
+	return &watcher{
+		ctx:        ctx,
+		service:    service,
+		interval:   interval,
+		startBlock: startBlock,
+		webhookURL: os.Getenv("SIGNER_ALERT_WEBHOOK_URL"),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		lowBalance: map[common.Address]bool{},
+	}, nil
+}
+
+func (w *watcher) run() {
+	slog.Info("Watching signer accounts", "signers", len(w.service.repositoryBesu.Signers), "interval", w.interval.String())
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.scan()
+		w.checkBalances()
+		select {
+		case <-(*w.ctx).Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan records the signer transactions of the blocks after the last scanned one.
+func (w *watcher) scan() {
+	repositoryBesu := w.service.repositoryBesu
+	repositoryDB := w.service.repositoryDB
+	repositoryExplorer := w.service.repositoryExplorer
+
+	from := w.startBlock
+	lastBlock, err := repositoryDB.GetLastScannedBlock()
+	if err == nil {
+		from = lastBlock + 1
+	} else if err != domain.ErrDataNotFound {
+		return
+	}
+	head, err := repositoryBesu.GetBlockNumber()
+	if err != nil || head < from {
+		return
+	}
+	to := min(head, from+maxBlocksPerScan-1)
+
+	transactions := []accountDomain.SignerTransaction{}
+	for number := from; number <= to; number++ {
+		block, err := repositoryExplorer.GetBlockByNumber(rpc.BlockNumber(number))
+		if err != nil {
+			slog.Warn("Erro scanning block for signer transactions", "block", number, "error", err.Error())
+			return
+		}
+		for _, transaction := range block.Transactions {
+			if !repositoryBesu.IsSigner(common.HexToAddress(transaction.From)) {
+				continue
+			}
+			receipt, err := repositoryExplorer.GetReceipt(common.HexToHash(transaction.Hash))
+			if err != nil {
+				slog.Warn("Erro getting receipt of signer transaction", "hash", transaction.Hash, "error", err.Error())
+				return
+			}
+			signerTransaction := accountDomain.SignerTransaction{
+				Hash:        transaction.Hash,
+				BlockNumber: block.Number,
+				BlockHash:   block.Hash,
+				Signer:      transaction.From,
+				To:          transaction.To,
+				Nonce:       transaction.Nonce,
+				Value:       transaction.Value,
+				Status:      receipt.Status,
+				GasUsed:     receipt.GasUsed,
+				MinedAt:     block.Timestamp,
+			}
+			if transaction.Decoded != nil {
+				signerTransaction.Method = &transaction.Decoded.Call
+			}
+			transactions = append(transactions, signerTransaction)
+		}
+	}
+
+	if err := repositoryDB.SaveScan(transactions, to); err != nil {
+		return
+	}
+	slog.Debug("Blocks scanned for signer transactions", "from", from, "to", to, "transactions", len(transactions))
+}
+
+// checkBalances compares the signer balances with the minimum balance and alerts
+// when a signer goes below it (and when it is funded again).
+func (w *watcher) checkBalances() {
+	signers, err := w.service.GetSigners()
+	if err != nil {
+		return
+	}
+	for _, signer := range signers {
+		address := common.HexToAddress(signer.Address)
+		wasLow, known := w.lowBalance[address]
+		w.lowBalance[address] = signer.LowBalance
+		if known && wasLow == signer.LowBalance || !known && !signer.LowBalance {
+			continue
+		}
+
+		if signer.LowBalance {
+			slog.Warn("Signer balance below the minimum balance", "signer", signer.Address, "balance", signer.Balance.String(), "minBalance", signer.MinBalance.String())
+		} else {
+			slog.Info("Signer balance back above the minimum balance", "signer", signer.Address, "balance", signer.Balance.String(), "minBalance", signer.MinBalance.String())
+		}
+		w.notify(accountDomain.LowBalanceAlert{
+			Signer:     signer.Address,
+			Balance:    signer.Balance,
+			MinBalance: signer.MinBalance,
+			LowBalance: signer.LowBalance,
+			At:         time.Now().UTC(),
+		})
+	}
+}
+
+// notify posts the alert to SIGNER_ALERT_WEBHOOK_URL, when configured.
+func (w *watcher) notify(alert accountDomain.LowBalanceAlert) {
+	if w.webhookURL == "" {
+		return
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		slog.Error("Erro encoding signer balance alert", "error", err.Error())
+		return
+	}
+	request, err := http.NewRequestWithContext(*w.ctx, http.MethodPost, w.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		slog.Error("Erro building signer balance alert request", "error", err.Error())
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := w.httpClient.Do(request)
+	if err != nil {
+		slog.Error("Erro sending signer balance alert", "signer", alert.Signer, "error", err.Error())
+		return
+	}
+	response.Body.Close()
+	if response.StatusCode >= 300 {
+		slog.Error("Erro sending signer balance alert", "signer", alert.Signer, "status", response.StatusCode)
+	}
+}
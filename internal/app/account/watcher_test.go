@@ -0,0 +1,38 @@
+package accountApp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewWatcherSettings(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		interval, startBlock string
+		wantInterval         time.Duration
+		wantStartBlock       uint64
+		valid                bool
+	}{
+		{"", "", 5 * time.Second, 0, true},
+		{"1m", "1200", time.Minute, 1200, true},
+		{"5", "", 0, 0, false},
+		{"0s", "", 0, 0, false},
+		{"-1s", "", 0, 0, false},
+		{"", "-1", 0, 0, false},
+		{"", "latest", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Setenv("SIGNER_SCAN_INTERVAL", tt.interval)
+		t.Setenv("SIGNER_SCAN_START_BLOCK", tt.startBlock)
+		watcher, err := newWatcher(&ctx, &AccountService{})
+		if (err == nil) != tt.valid {
+			t.Errorf("newWatcher() with %q, %q error = %v, want valid %v", tt.interval, tt.startBlock, err, tt.valid)
+			continue
+		}
+		if tt.valid && (watcher.interval != tt.wantInterval || watcher.startBlock != tt.wantStartBlock) {
+			t.Errorf("newWatcher() with %q, %q = %v, %d, want %v, %d",
+				tt.interval, tt.startBlock, watcher.interval, watcher.startBlock, tt.wantInterval, tt.wantStartBlock)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package auditApp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultEventsLimit = 100
+	maxEventsLimit     = 10000
+)
+
+// csvColumns are the columns of the CSV export, one per field of the events.
+var csvColumns = []string{
+	"id", "occurredAt", "action", "actor", "authMethod", "role", "clientIp", "method", "route",
+	"statusCode", "contract", "value", "signer", "txHash", "details", "prevHash", "hash",
+}
+
+// AuditHandler handles HTTP requests related to the audit log.
+type AuditHandler struct {
+	// The service layer for reading the audit log.
+	service *AuditService
+}
+
+// NewHandler initializes a new AuditHandler.
+// Parameters:
+//   - service: The AuditService used for business logic.
+//
+// Returns:
+//   - A pointer to a newly created AuditHandler.
+func NewHandler(service *AuditService) *AuditHandler {
+	return &AuditHandler{service}
+}
+
+// statusCode maps the errors returned by the service to HTTP status codes.
+func statusCode(err error) int {
+	switch err {
+	case domain.ErrDataNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// filterOf reads the filter of the events from the query params.
+func filterOf(ctx *gin.Context) (auditDomain.Filter, string, bool) {
+	filter := auditDomain.Filter{
+		Actor:    ctx.Query("actor"),
+		Action:   ctx.Query("action"),
+		Contract: ctx.Query("contract"),
+		Signer:   ctx.Query("signer"),
+		Limit:    defaultEventsLimit,
+	}
+	for key, value := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if param, ok := ctx.GetQuery(key); ok {
+			at, err := time.Parse(time.RFC3339, param)
+			if err != nil {
+				return filter, key, false
+			}
+			*value = &at
+		}
+	}
+	for key, value := range map[string]*uint64{"after": &filter.AfterID, "limit": &filter.Limit} {
+		if param, ok := ctx.GetQuery(key); ok {
+			number, err := strconv.ParseUint(param, 10, 64)
+			if err != nil {
+				return filter, key, false
+			}
+			*value = number
+		}
+	}
+	if filter.Limit == 0 || filter.Limit > maxEventsLimit {
+		return filter, "limit", false
+	}
+	return filter, "", true
+}
+
+// GetEvents lists the audit events, in the order they were recorded, as JSON or as a CSV export.
+// HTTP Method: GET
+// URL: /audit
+// Query Parameters:
+//   - actor, action, contract, signer (string, optional): Exact values of the events.
+//   - from, to (string, optional): RFC 3339 times, from inclusive and to exclusive.
+//   - after (int, optional): The ID after which the events are listed, the X-Next-After header of the previous page.
+//   - limit (int, optional): Maximum number of events (defaults to 100, at most 10000).
+//   - format (string, optional): json (default) or csv.
+//
+// Responses:
+//   - 200: The events, with the X-Next-After header when there may be more.
+//   - 400: Bad request if a query param is invalid.
+//   - 500: Internal server error if the events can't be read.
+func (r *AuditHandler) GetEvents(ctx *gin.Context) {
+	filter, param, ok := filterOf(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param "+param)
+		return
+	}
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param format")
+		return
+	}
+	events, err := r.service.List(filter)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	if uint64(len(events)) == filter.Limit {
+		ctx.Header("X-Next-After", strconv.FormatUint(events[len(events)-1].ID, 10))
+	}
+	if format == "json" {
+		ctx.JSON(http.StatusOK, events)
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="audit.csv"`)
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	writer := csv.NewWriter(ctx.Writer)
+	writer.Write(csvColumns)
+	for _, event := range events {
+		details := ""
+		if len(event.Details) > 0 {
+			encoded, _ := json.Marshal(event.Details)
+			details = string(encoded)
+		}
+		writer.Write([]string{
+			strconv.FormatUint(event.ID, 10), event.OccurredAt.Format(time.RFC3339Nano), event.Action,
+			csvCell(event.Actor), event.AuthMethod, event.Role, event.ClientIP, event.Method, event.Route,
+			strconv.Itoa(event.StatusCode), event.Contract, event.Value, event.Signer, event.TxHash,
+			details, event.PrevHash, event.Hash,
+		})
+	}
+	writer.Flush()
+}
+
+// csvCell escapes the values set by the callers (e.g. the name of an API key) that
+// a spreadsheet would run as a formula.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// VerifyEvents verifies the hash chain of the audit log.
+// HTTP Method: GET
+// URL: /audit/verify
+// Responses:
+//   - 200: Whether the chain is valid, the events verified, and the first event breaking it if any.
+//   - 500: Internal server error if the events can't be read.
+func (r *AuditHandler) VerifyEvents(ctx *gin.Context) {
+	verification, err := r.service.Verify()
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, verification)
+}
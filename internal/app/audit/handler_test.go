@@ -0,0 +1,70 @@
+package auditApp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"goledger-challenge-besu/internal/domain/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newStore(t, 5)
+	store.Events[0].Actor = "=HYPERLINK(\"http://evil\")"
+	store.Events[0].Hash = ""
+	handler := NewHandler(NewService(store))
+	router := gin.New()
+	router.GET("/audit", handler.GetEvents)
+	router.GET("/audit/verify", handler.VerifyEvents)
+	get := func(url string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+		return recorder
+	}
+
+	recorder := get("/audit?actor=ops&limit=2")
+	var events []auditDomain.Event
+	if err := json.Unmarshal(recorder.Body.Bytes(), &events); err != nil || recorder.Code != http.StatusOK || len(events) != 2 || events[1].ID != 4 {
+		t.Fatalf("GET /audit = %d %s, want the first 2 events of ops", recorder.Code, recorder.Body)
+	}
+	if next := recorder.Header().Get("X-Next-After"); next != "4" {
+		t.Errorf("X-Next-After = %q, want 4", next)
+	}
+	recorder = get("/audit?actor=ops&after=4&limit=2&from=2025-01-01T12:00:00Z&to=2025-01-01T13:00:00Z")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &events); err != nil || len(events) != 0 || recorder.Header().Get("X-Next-After") != "" {
+		t.Errorf("GET /audit of the last page = %s, want no events nor next page", recorder.Body)
+	}
+
+	recorder = get("/audit?format=csv&limit=1")
+	records, err := csv.NewReader(recorder.Body).ReadAll()
+	if err != nil || recorder.Code != http.StatusOK || len(records) != 2 || len(records[1]) != len(csvColumns) {
+		t.Fatalf("GET /audit?format=csv = %d %v %v, want the header and 1 event", recorder.Code, records, err)
+	}
+	if actor := records[1][3]; actor != "'=HYPERLINK(\"http://evil\")" {
+		t.Errorf("actor cell = %q, want the formula escaped", actor)
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); disposition != `attachment; filename="audit.csv"` {
+		t.Errorf("Content-Disposition = %q, want an attachment", disposition)
+	}
+
+	for _, url := range []string{
+		"/audit?from=yesterday", "/audit?to=2025-01-01", "/audit?after=-1", "/audit?limit=0",
+		"/audit?limit=10001", "/audit?format=xml",
+	} {
+		if recorder := get(url); recorder.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", url, recorder.Code)
+		}
+	}
+
+	recorder = get("/audit/verify")
+	var verification auditDomain.Verification
+	if err := json.Unmarshal(recorder.Body.Bytes(), &verification); err != nil || recorder.Code != http.StatusOK ||
+		verification.Valid || verification.BrokenAt == nil || *verification.BrokenAt != 1 {
+		t.Errorf("GET /audit/verify = %d %s, want the chain broken at the first event", recorder.Code, recorder.Body)
+	}
+}
@@ -0,0 +1,98 @@
+package auditApp
+
+import (
+	"fmt"
+	"log/slog"
+
+	"goledger-challenge-besu/internal/domain/audit"
+)
+
+// verifyPageSize is the number of events read at a time by Verify.
+const verifyPageSize = 1000
+
+// AuditService records the audit events of the state-changing operations, lists
+// them and verifies their hash chain.
+type AuditService struct {
+	store auditDomain.AuditStore
+}
+
+// NewService initializes a new AuditService.
+// Parameters:
+//   - store: The AuditStore of the events.
+//
+// Returns:
+//   - A pointer to a newly created AuditService.
+func NewService(store auditDomain.AuditStore) *AuditService {
+	return &AuditService{store}
+}
+
+// Record appends an event to the audit log.
+// Parameters:
+//   - event: The event, filled in by the middleware and the services.
+//
+// Returns:
+//   - The recorded event, with its ID and hashes.
+//   - An error if the event can't be stored.
+func (r *AuditService) Record(event auditDomain.Event) (*auditDomain.Event, error) {
+	recorded, err := r.store.Append(event)
+	if err != nil {
+		slog.Error("Erro appending audit event in AuditStore.Append", "action", event.Action, "actor", event.Actor, "status", event.StatusCode)
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// List lists the events matching a filter, in the order they were recorded.
+// Parameters:
+//   - filter: The filter and page of the events.
+//
+// Returns:
+//   - The events.
+//   - An error if they can't be read.
+func (r *AuditService) List(filter auditDomain.Filter) ([]auditDomain.Event, error) {
+	events, err := r.store.List(filter)
+	if err != nil {
+		slog.Error("Erro listing audit events from AuditStore.List")
+		return nil, err
+	}
+	return events, nil
+}
+
+// Verify checks the hash chain of every event: each one must hold the hash of the
+// previous one, and its hash must match its content.
+// Returns:
+//   - The Verification, with the first event breaking the chain if any.
+//   - An error if the events can't be read.
+func (r *AuditService) Verify() (*auditDomain.Verification, error) {
+	verification := &auditDomain.Verification{Valid: true}
+	prevHash := ""
+	filter := auditDomain.Filter{Limit: verifyPageSize}
+	for {
+		events, err := r.store.List(filter)
+		if err != nil {
+			slog.Error("Erro listing audit events from AuditStore.List", "after", filter.AfterID)
+			return nil, err
+		}
+		for _, event := range events {
+			reason := ""
+			switch {
+			case event.PrevHash != prevHash:
+				reason = fmt.Sprintf("previous hash %q, want %q", event.PrevHash, prevHash)
+			case event.ComputeHash() != event.Hash:
+				reason = "hash doesn't match the content of the event"
+			}
+			if reason != "" {
+				id := event.ID
+				verification.Valid, verification.BrokenAt, verification.Reason = false, &id, reason
+				slog.Warn("Audit log chain broken", "id", id, "reason", reason)
+				return verification, nil
+			}
+			prevHash = event.Hash
+			verification.Events++
+		}
+		if uint64(len(events)) < filter.Limit {
+			return verification, nil
+		}
+		filter.AfterID = events[len(events)-1].ID
+	}
+}
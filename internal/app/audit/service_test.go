@@ -0,0 +1,92 @@
+package auditApp
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/audit"
+	"goledger-challenge-besu/internal/domain/audit/fake"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// newStore returns a store holding n chained events, one a minute.
+func newStore(t *testing.T, n int) *auditFake.Store {
+	t.Helper()
+	store := auditFake.NewStore()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range n {
+		actor := "billing"
+		if i%2 == 1 {
+			actor = "ops"
+		}
+		if _, err := store.Append(auditDomain.Event{
+			OccurredAt: start.Add(time.Duration(i) * time.Minute), Action: auditDomain.ActionSetValue,
+			Actor: actor, StatusCode: 200, Value: "1",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func TestRecord(t *testing.T) {
+	store := newStore(t, 1)
+	service := NewService(store)
+	event, err := service.Record(auditDomain.Event{Action: auditDomain.ActionSync, Actor: "ops"})
+	if err != nil || event.ID != 2 || event.PrevHash != store.Events[0].Hash {
+		t.Errorf("Record() = %+v, %v, want the event chained to the last one", event, err)
+	}
+
+	store.Err = domain.ErrInternal
+	if _, err := service.Record(auditDomain.Event{Action: auditDomain.ActionSync}); err != domain.ErrInternal {
+		t.Errorf("Record() error = %v, want %v", err, domain.ErrInternal)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	// more events than a page
+	store := newStore(t, 2*verifyPageSize+1)
+	service := NewService(store)
+	verification, err := service.Verify()
+	if err != nil || !verification.Valid || verification.Events != 2*verifyPageSize+1 || verification.BrokenAt != nil {
+		t.Fatalf("Verify() = %+v, %v, want the whole chain valid", verification, err)
+	}
+
+	empty, err := NewService(auditFake.NewStore()).Verify()
+	if err != nil || !empty.Valid || empty.Events != 0 {
+		t.Errorf("Verify() of an empty log = %+v, %v, want it valid", empty, err)
+	}
+
+	for name, tt := range map[string]struct {
+		tamper func(events []auditDomain.Event)
+		want   uint64
+	}{
+		"content changed": {func(events []auditDomain.Event) { events[1500].Value = "2" }, 1501},
+		"content and hash changed": {func(events []auditDomain.Event) {
+			events[10].Actor = "nobody"
+			events[10].Hash = events[10].ComputeHash()
+		}, 12},
+		"event removed": {func(events []auditDomain.Event) { copy(events[5:], events[6:]) }, 7},
+		"first changed": {func(events []auditDomain.Event) { events[0].PrevHash = "00" }, 1},
+	} {
+		store := newStore(t, 2*verifyPageSize+1)
+		tt.tamper(store.Events)
+		verification, err := NewService(store).Verify()
+		if err != nil || verification.Valid || verification.BrokenAt == nil || *verification.BrokenAt != tt.want || verification.Reason == "" {
+			t.Errorf("Verify() with the %s = %+v, %v, want it broken at %d", name, verification, err, tt.want)
+		}
+	}
+
+	store.Err = domain.ErrInternal
+	if _, err := service.Verify(); err != domain.ErrInternal {
+		t.Errorf("Verify() error = %v, want %v", err, domain.ErrInternal)
+	}
+}
@@ -0,0 +1,136 @@
+package authApp
+
+import (
+	"net/http"
+	"strconv"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// principalKey is the key of the authenticated Principal in the gin context.
+const principalKey = "principal"
+
+// SetPrincipal stores the authenticated caller in the request context.
+func SetPrincipal(ctx *gin.Context, principal *authDomain.Principal) {
+	ctx.Set(principalKey, principal)
+}
+
+// PrincipalOf returns the authenticated caller of the request, nil outside of the
+// authenticated routes.
+func PrincipalOf(ctx *gin.Context) *authDomain.Principal {
+	principal, _ := ctx.Get(principalKey)
+	p, _ := principal.(*authDomain.Principal)
+	return p
+}
+
+// AuthHandler handles HTTP requests related to the API keys and the caller identity.
+type AuthHandler struct {
+	// The service layer for authentication.
+	service *AuthService
+}
+
+// NewHandler initializes a new AuthHandler.
+// Parameters:
+//   - service: The AuthService used for business logic.
+//
+// Returns:
+//   - A pointer to a newly created AuthHandler.
+func NewHandler(service *AuthService) *AuthHandler {
+	return &AuthHandler{service}
+}
+
+// statusCode maps the errors returned by the service to HTTP status codes.
+func statusCode(err error) int {
+	switch err {
+	case domain.ErrInvalidRole:
+		return http.StatusBadRequest
+	case domain.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case domain.ErrForbidden:
+		return http.StatusForbidden
+	case domain.ErrDataNotFound:
+		return http.StatusNotFound
+	case domain.ErrConflictingData:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetMe returns the authenticated caller.
+// HTTP Method: GET
+// URL: /auth/me
+// Responses:
+//   - 200: The subject, role and authentication method of the caller.
+func (r *AuthHandler) GetMe(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, PrincipalOf(ctx))
+}
+
+type createAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=255" example:"billing-service"`
+	Role string `json:"role" binding:"required" example:"writer"`
+}
+
+// CreateAPIKey creates an API key.
+// HTTP Method: POST
+// URL: /auth/api-keys
+// Request Body:
+//   - name (string): The name of the key (its owner).
+//   - role (string): reader, writer or admin.
+//
+// Responses:
+//   - 201: The created key, with the key itself which is never returned again.
+//   - 400: Bad request if input validation fails.
+//   - 500: Internal server error if the key can't be stored.
+func (r *AuthHandler) CreateAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	key, err := r.service.CreateAPIKey(ctx.Request.Context(), req.Name, req.Role)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys lists the API keys, without the keys themselves.
+// HTTP Method: GET
+// URL: /auth/api-keys
+// Responses:
+//   - 200: The API keys, revoked ones included.
+//   - 500: Internal server error if the keys can't be read.
+func (r *AuthHandler) ListAPIKeys(ctx *gin.Context) {
+	keys, err := r.service.ListAPIKeys()
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes an API key.
+// HTTP Method: DELETE
+// URL: /auth/api-keys/:id
+// Responses:
+//   - 204: The key is revoked.
+//   - 400: Bad request if the id is invalid.
+//   - 404: Not found if there is no such key or it is already revoked.
+//   - 500: Internal server error if the key can't be revoked.
+func (r *AuthHandler) RevokeAPIKey(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid param id")
+		return
+	}
+	if err = r.service.RevokeAPIKey(ctx.Request.Context(), id); err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,121 @@
+package authApp
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/audit"
+	"goledger-challenge-besu/internal/domain/auth"
+)
+
+// AuthService authenticates the callers of the API, by API key or JWT bearer
+// token, and manages the API keys.
+type AuthService struct {
+	keys      authDomain.KeyStore
+	tokens    authDomain.TokenVerifier
+	bootstrap string // ADMIN_API_KEY, an admin key that isn't stored
+}
+
+// NewService initializes a new AuthService.
+// Parameters:
+//   - keys: The KeyStore of the API keys.
+//   - tokens: The TokenVerifier of the bearer tokens.
+//   - bootstrapKey: An admin API key accepted without being stored (ADMIN_API_KEY), to create
+//     the first keys; disabled when empty.
+//
+// Returns:
+//   - A pointer to a newly created AuthService.
+func NewService(keys authDomain.KeyStore, tokens authDomain.TokenVerifier, bootstrapKey string) *AuthService {
+	return &AuthService{keys, tokens, bootstrapKey}
+}
+
+// Authenticate identifies the caller of a request.
+// Parameters:
+//   - apiKey: The API key of the request (X-API-Key header), if any.
+//   - authorization: The Authorization header of the request, if any ("Bearer <token>").
+//
+// Returns:
+//   - The Principal of the caller.
+//   - domain.ErrUnauthorized if there are no credentials or they are invalid.
+func (r *AuthService) Authenticate(apiKey string, authorization string) (*authDomain.Principal, error) {
+	if apiKey != "" {
+		if r.bootstrap != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(r.bootstrap)) == 1 {
+			return &authDomain.Principal{Subject: "bootstrap", Role: authDomain.RoleAdmin, Method: "api_key"}, nil
+		}
+		key, err := r.keys.GetAPIKeyByHash(authDomain.HashAPIKey(apiKey))
+		if err == domain.ErrDataNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		if err != nil {
+			slog.Error("Erro getting api key from KeyStore.GetAPIKeyByHash")
+			return nil, err
+		}
+		return &authDomain.Principal{Subject: key.Name, Role: key.Role, Method: "api_key", KeyID: key.ID}, nil
+	}
+
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if ok && strings.EqualFold(scheme, "Bearer") && token != "" {
+		return r.tokens.VerifyToken(strings.TrimSpace(token))
+	}
+	return nil, domain.ErrUnauthorized
+}
+
+// CreateAPIKey creates an API key.
+// Parameters:
+//   - ctx: The context of the request, whose audit event gets the key.
+//   - name: The name of the key (its owner).
+//   - role: The Role granted by the key.
+//
+// Returns:
+//   - The NewAPIKey, the only time the key itself is returned.
+//   - An error if the role is invalid or the key can't be stored.
+func (r *AuthService) CreateAPIKey(ctx context.Context, name string, role string) (*authDomain.NewAPIKey, error) {
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) {
+		event.Detail("name", name)
+		event.Detail("role", role)
+	})
+	keyRole, err := authDomain.ParseRole(role)
+	if err != nil {
+		return nil, err
+	}
+	key, prefix, keyHash, err := authDomain.GenerateAPIKey()
+	if err != nil {
+		slog.Error("Erro generating api key", "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	stored, err := r.keys.CreateAPIKey(name, keyRole, prefix, keyHash)
+	if err != nil {
+		slog.Error("Erro storing api key in KeyStore.CreateAPIKey", "name", name)
+		return nil, err
+	}
+	slog.Info("API key created", "id", stored.ID, "name", name, "role", keyRole, "prefix", prefix)
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) {
+		event.Detail("id", strconv.FormatUint(stored.ID, 10))
+		event.Detail("prefix", prefix)
+	})
+	return &authDomain.NewAPIKey{APIKey: *stored, Key: key}, nil
+}
+
+func (r *AuthService) ListAPIKeys() ([]authDomain.APIKey, error) {
+	keys, err := r.keys.ListAPIKeys()
+	if err != nil {
+		slog.Error("Erro listing api keys from KeyStore.ListAPIKeys")
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *AuthService) RevokeAPIKey(ctx context.Context, id uint64) error {
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) { event.Detail("id", strconv.FormatUint(id, 10)) })
+	err := r.keys.RevokeAPIKey(id)
+	if err != nil {
+		slog.Error("Erro revoking api key in KeyStore.RevokeAPIKey", "id", id)
+		return err
+	}
+	slog.Info("API key revoked", "id", id)
+	return nil
+}
@@ -0,0 +1,133 @@
+package authApp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/auth"
+	"goledger-challenge-besu/internal/domain/auth/fake"
+
+	"github.com/gin-gonic/gin"
+)
+
+const bootstrapKey = "bootstrap-secret"
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+func newService() *AuthService {
+	tokens := authFake.Tokens{"bob-token": {Subject: "bob", Role: authDomain.RoleReader, Method: "jwt"}}
+	return NewService(authFake.NewKeyStore(), tokens, bootstrapKey)
+}
+
+func TestAuthenticate(t *testing.T) {
+	service := newService()
+	created, err := service.CreateAPIKey(context.Background(), "ci", "writer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		apiKey        string
+		authorization string
+		wantSubject   string
+		wantRole      authDomain.Role
+	}{
+		{name: "api key", apiKey: created.Key, wantSubject: "ci", wantRole: authDomain.RoleWriter},
+		{name: "bootstrap key", apiKey: bootstrapKey, wantSubject: "bootstrap", wantRole: authDomain.RoleAdmin},
+		{name: "bearer token", authorization: "Bearer bob-token", wantSubject: "bob", wantRole: authDomain.RoleReader},
+		{name: "bearer scheme case", authorization: "bearer bob-token", wantSubject: "bob", wantRole: authDomain.RoleReader},
+		{name: "unknown api key", apiKey: "glk_unknown"},
+		{name: "unknown token", authorization: "Bearer other"},
+		{name: "basic scheme", authorization: "Basic Ym9iOnNlY3JldA=="},
+		{name: "api key wins over token", apiKey: "glk_unknown", authorization: "Bearer bob-token"},
+		{name: "no credentials"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := service.Authenticate(tt.apiKey, tt.authorization)
+			if tt.wantRole == "" {
+				if err != domain.ErrUnauthorized {
+					t.Errorf("Authenticate() = %+v, %v, want %v", principal, err, domain.ErrUnauthorized)
+				}
+				return
+			}
+			if err != nil || principal.Subject != tt.wantSubject || principal.Role != tt.wantRole {
+				t.Errorf("Authenticate() = %+v, %v, want %s as %s", principal, err, tt.wantSubject, tt.wantRole)
+			}
+		})
+	}
+
+	if err = service.RevokeAPIKey(context.Background(), created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = service.Authenticate(created.Key, ""); err != domain.ErrUnauthorized {
+		t.Errorf("Authenticate() with a revoked key error = %v, want %v", err, domain.ErrUnauthorized)
+	}
+
+	// without a bootstrap key, an empty key doesn't match it
+	service = NewService(authFake.NewKeyStore(), authFake.Tokens{}, "")
+	if _, err = service.Authenticate("", ""); err != domain.ErrUnauthorized {
+		t.Errorf("Authenticate() without bootstrap key error = %v, want %v", err, domain.ErrUnauthorized)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(newService())
+	router := gin.New()
+	router.Use(func(ctx *gin.Context) {
+		SetPrincipal(ctx, &authDomain.Principal{Subject: "root", Role: authDomain.RoleAdmin, Method: "api_key"})
+	})
+	router.GET("/auth/me", handler.GetMe)
+	router.GET("/auth/api-keys", handler.ListAPIKeys)
+	router.POST("/auth/api-keys", handler.CreateAPIKey)
+	router.DELETE("/auth/api-keys/:id", handler.RevokeAPIKey)
+
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, url, strings.NewReader(body)))
+		return recorder
+	}
+
+	if res := do(http.MethodGet, "/auth/me", ""); res.Code != http.StatusOK || !strings.Contains(res.Body.String(), `"subject":"root"`) {
+		t.Errorf("GET /auth/me = %d %s", res.Code, res.Body)
+	}
+
+	res := do(http.MethodPost, "/auth/api-keys", `{"name": "ci", "role": "writer"}`)
+	var created authDomain.NewAPIKey
+	if err := json.Unmarshal(res.Body.Bytes(), &created); res.Code != http.StatusCreated || err != nil || created.Key == "" || created.Role != authDomain.RoleWriter {
+		t.Fatalf("POST /auth/api-keys = %d %s", res.Code, res.Body)
+	}
+	for _, body := range []string{`{"name": "ci", "role": "root"}`, `{"role": "reader"}`, `{"name": "ci"`} {
+		if res = do(http.MethodPost, "/auth/api-keys", body); res.Code != http.StatusBadRequest {
+			t.Errorf("POST /auth/api-keys %s = %d, want 400", body, res.Code)
+		}
+	}
+
+	// the keys are listed without the key itself
+	if res = do(http.MethodGet, "/auth/api-keys", ""); res.Code != http.StatusOK || strings.Contains(res.Body.String(), created.Key) || !strings.Contains(res.Body.String(), created.Prefix) {
+		t.Errorf("GET /auth/api-keys = %d %s", res.Code, res.Body)
+	}
+
+	if res = do(http.MethodDelete, "/auth/api-keys/1", ""); res.Code != http.StatusNoContent {
+		t.Errorf("DELETE /auth/api-keys/1 = %d, want 204", res.Code)
+	}
+	if res = do(http.MethodDelete, "/auth/api-keys/1", ""); res.Code != http.StatusNotFound {
+		t.Errorf("second DELETE /auth/api-keys/1 = %d, want 404", res.Code)
+	}
+	if res = do(http.MethodDelete, "/auth/api-keys/one", ""); res.Code != http.StatusBadRequest {
+		t.Errorf("DELETE /auth/api-keys/one = %d, want 400", res.Code)
+	}
+}
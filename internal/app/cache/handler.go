@@ -0,0 +1,30 @@
+package cacheApp
+
+import (
+	"net/http"
+
+	"goledger-challenge-besu/configs/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheHandler exposes the metrics of the cache of the on-chain reads.
+type CacheHandler struct {
+	cache *cacheConfig.Cache
+}
+
+// NewHandler initializes a new CacheHandler.
+// Parameters:
+//   - cache: The cache of the on-chain reads.
+//
+// Returns:
+//   - A pointer to a newly created CacheHandler.
+func NewHandler(cache *cacheConfig.Cache) *CacheHandler {
+	return &CacheHandler{cache}
+}
+
+// GetStats handles GET requests returning the hits, misses and errors of the cache
+// since the service started.
+func (h *CacheHandler) GetStats(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.cache.Stats())
+}
@@ -0,0 +1,154 @@
+package explorerApp
+
+import (
+	"net/http"
+
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gin-gonic/gin"
+)
+
+// ExplorerHandler handles HTTP requests to browse the blocks and transactions of the chain.
+type ExplorerHandler struct {
+	// The service layer for reading blocks and transactions.
+	service *ExplorerService
+}
+
+// NewHandler initializes a new ExplorerHandler.
+// Parameters:
+//   - service: The ExplorerService used for business logic.
+//
+// Returns:
+//   - A pointer to a newly created ExplorerHandler.
+func NewHandler(service *ExplorerService) *ExplorerHandler {
+	return &ExplorerHandler{service}
+}
+
+// statusCode maps the errors returned by the service to HTTP status codes.
+func statusCode(err error) int {
+	switch err {
+	case domain.ErrDataNotFound:
+		return http.StatusNotFound
+	case domain.ErrNodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseHash parses a 0x prefixed 32 bytes hash.
+func parseHash(value string) (common.Hash, bool) {
+	bytes, err := hexutil.Decode(value)
+	if err != nil || len(bytes) != common.HashLength {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(bytes), true
+}
+
+// GetLatestBlock retrieves the latest block with its transactions.
+// HTTP Method: GET
+// URL: /blocks/latest
+// Responses:
+//   - 200: The block, with its transactions and their decoded input.
+//   - 500: Internal server error if the RPC call fails.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *ExplorerHandler) GetLatestBlock(ctx *gin.Context) {
+	block, err := r.service.GetBlockByNumber(rpc.LatestBlockNumber)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, block)
+}
+
+// GetBlock retrieves a block with its transactions.
+// HTTP Method: GET
+// URL: /blocks/:numberOrHash
+// Path Parameters:
+//   - numberOrHash (string): The block hash, number (decimal or hex) or tag ("latest", "finalized"...).
+//
+// Responses:
+//   - 200: The block, with its transactions and their decoded input.
+//   - 400: Bad request if numberOrHash is invalid.
+//   - 404: Not found if there is no such block.
+//   - 500: Internal server error if the RPC call fails.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *ExplorerHandler) GetBlock(ctx *gin.Context) {
+	numberOrHash := ctx.Param("numberOrHash")
+	if hash, ok := parseHash(numberOrHash); ok {
+		block, err := r.service.GetBlockByHash(hash)
+		if err != nil {
+			ctx.JSON(statusCode(err), err.Error())
+			return
+		}
+		ctx.JSON(http.StatusOK, block)
+		return
+	}
+
+	number, err := domain.ParseBlockTag(numberOrHash)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid path param numberOrHash")
+		return
+	}
+	block, err := r.service.GetBlockByNumber(number)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, block)
+}
+
+// GetTransaction retrieves a transaction, with its input decoded against the known ABIs.
+// HTTP Method: GET
+// URL: /transactions/:hash
+// Path Parameters:
+//   - hash (string): The transaction hash.
+//
+// Responses:
+//   - 200: The transaction (blockHash and blockNumber are null while it is pending).
+//   - 400: Bad request if the hash is invalid.
+//   - 404: Not found if there is no such transaction.
+//   - 500: Internal server error if the RPC call fails.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *ExplorerHandler) GetTransaction(ctx *gin.Context) {
+	hash, ok := parseHash(ctx.Param("hash"))
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid path param hash")
+		return
+	}
+	transaction, err := r.service.GetTransaction(hash)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, transaction)
+}
+
+// GetReceipt retrieves the receipt of a transaction, with its logs decoded against the known ABIs.
+// HTTP Method: GET
+// URL: /transactions/:hash/receipt
+// Path Parameters:
+//   - hash (string): The transaction hash.
+//
+// Responses:
+//   - 200: The receipt with its logs.
+//   - 400: Bad request if the hash is invalid.
+//   - 404: Not found if the transaction is unknown or not mined yet.
+//   - 500: Internal server error if the RPC call fails.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *ExplorerHandler) GetReceipt(ctx *gin.Context) {
+	hash, ok := parseHash(ctx.Param("hash"))
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid path param hash")
+		return
+	}
+	receipt, err := r.service.GetReceipt(hash)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, receipt)
+}
@@ -0,0 +1,54 @@
+package explorerApp
+
+import (
+	"log/slog"
+
+	"goledger-challenge-besu/internal/domain/explorer"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type ExplorerService struct {
+	repositoryBesu *explorerDomain.ExplorerRepositoryBesu
+}
+
+func NewService(repositoryBesu *explorerDomain.ExplorerRepositoryBesu) *ExplorerService {
+	return &ExplorerService{repositoryBesu}
+}
+
+func (r *ExplorerService) GetBlockByNumber(number rpc.BlockNumber) (explorerDomain.Block, error) {
+	block, err := r.repositoryBesu.GetBlockByNumber(number)
+	if err != nil {
+		slog.Error("Erro getting block from ExplorerRepositoryBesu.GetBlockByNumber", "block", number.String())
+		return explorerDomain.Block{}, err
+	}
+	return block, nil
+}
+
+func (r *ExplorerService) GetBlockByHash(hash common.Hash) (explorerDomain.Block, error) {
+	block, err := r.repositoryBesu.GetBlockByHash(hash)
+	if err != nil {
+		slog.Error("Erro getting block from ExplorerRepositoryBesu.GetBlockByHash", "hash", hash.Hex())
+		return explorerDomain.Block{}, err
+	}
+	return block, nil
+}
+
+func (r *ExplorerService) GetTransaction(hash common.Hash) (explorerDomain.Transaction, error) {
+	transaction, err := r.repositoryBesu.GetTransaction(hash)
+	if err != nil {
+		slog.Error("Erro getting transaction from ExplorerRepositoryBesu.GetTransaction", "hash", hash.Hex())
+		return explorerDomain.Transaction{}, err
+	}
+	return transaction, nil
+}
+
+func (r *ExplorerService) GetReceipt(hash common.Hash) (explorerDomain.Receipt, error) {
+	receipt, err := r.repositoryBesu.GetReceipt(hash)
+	if err != nil {
+		slog.Error("Erro getting receipt from ExplorerRepositoryBesu.GetReceipt", "hash", hash.Hex())
+		return explorerDomain.Receipt{}, err
+	}
+	return receipt, nil
+}
@@ -0,0 +1,56 @@
+package healthApp
+
+import (
+	"net/http"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler handles the liveness and readiness probes.
+type HealthHandler struct {
+	// The service layer for checking the dependencies.
+	service *HealthService
+	// accepting tells whether the server takes requests, false once it shuts down.
+	accepting func() bool
+}
+
+// NewHandler initializes a new HealthHandler.
+// Parameters:
+//   - service: The HealthService used for business logic.
+//   - accepting: Whether the server takes requests; the readiness fails when it doesn't.
+//
+// Returns:
+//   - A pointer to a newly created HealthHandler.
+func NewHandler(service *HealthService, accepting func() bool) *HealthHandler {
+	return &HealthHandler{service, accepting}
+}
+
+// Liveness handles GET /healthz: the process is alive, whatever its dependencies.
+func (h *HealthHandler) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": healthDomain.StatusUp})
+}
+
+// Readiness handles GET /readyz: 200 when the service can handle requests, 503 with
+// the failed checks otherwise, or while it shuts down.
+func (h *HealthHandler) Readiness(ctx *gin.Context) {
+	if !h.accepting() {
+		ctx.JSON(http.StatusServiceUnavailable, healthDomain.Report{
+			Status: healthDomain.StatusDown,
+			Checks: map[string]healthDomain.Check{
+				"server": {Status: healthDomain.StatusDown, Error: domain.ErrShuttingDown.Error()},
+			},
+			CheckedAt: time.Now(),
+		})
+		return
+	}
+	report := h.service.Readiness()
+	if report.Status != healthDomain.StatusUp {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,54 @@
+package healthApp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	f := newFixture(t)
+	accepting := true
+	handler := NewHandler(f.service, func() bool { return accepting })
+	router := gin.New()
+	router.GET("/healthz", handler.Liveness)
+	router.GET("/readyz", handler.Readiness)
+	get := func(url string) (int, healthDomain.Report) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+		var report healthDomain.Report
+		if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+			t.Fatalf("GET %s body %q: %v", url, recorder.Body, err)
+		}
+		return recorder.Code, report
+	}
+
+	if status, report := get("/readyz"); status != http.StatusOK || report.Status != healthDomain.StatusUp || report.Checks["besu"].Details["chainId"] != "1337" {
+		t.Errorf("GET /readyz = %d %+v, want 200 up", status, report)
+	}
+	f.database.PingErr = domain.ErrInternal
+	f.clock = f.clock.Add(time.Minute)
+	if status, report := get("/readyz"); status != http.StatusServiceUnavailable || report.Checks["database"].Error != domain.ErrInternal.Error() {
+		t.Errorf("GET /readyz with the database down = %d %+v, want 503 with its error", status, report)
+	}
+
+	// the process is alive all the same
+	if status, report := get("/healthz"); status != http.StatusOK || report.Status != healthDomain.StatusUp {
+		t.Errorf("GET /healthz = %d %+v, want 200 up", status, report)
+	}
+
+	// shutting down, the dependencies don't matter
+	f.database.PingErr = nil
+	accepting = false
+	if status, report := get("/readyz"); status != http.StatusServiceUnavailable || report.Checks["server"].Error != domain.ErrShuttingDown.Error() {
+		t.Errorf("GET /readyz while shutting down = %d %+v, want 503", status, report)
+	}
+}
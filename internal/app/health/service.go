@@ -0,0 +1,207 @@
+package healthApp
+
+import (
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"goledger-challenge-besu/internal/domain/health"
+)
+
+// HealthService checks the dependencies of the service for the readiness probe:
+// the database (ping and schema version) and the Besu nodes (chain ID, block
+// production and peers). The reports are cached for HEALTH_CACHE_TTL, so frequent
+// probes don't hammer the node; the checks of a report run in parallel, and the
+// probes arriving meanwhile wait for it.
+type HealthService struct {
+	database healthDomain.DatabaseProbe
+	node     healthDomain.NodeProbe
+
+	chainID       *big.Int // expected, or pinned by the first check when BESU_CHAIN_ID is empty
+	minPeers      uint64
+	maxBlockStall time.Duration
+	cacheTTL      time.Duration
+	now           func() time.Time
+
+	mu         sync.Mutex
+	report     *healthDomain.Report
+	head       uint64    // the highest block seen
+	headSince  time.Time // when it was first seen
+	pinnedHead bool
+}
+
+// NewService builds the HealthService from BESU_CHAIN_ID (the chain ID the nodes
+// must be on, the one of the first check by default), HEALTH_MIN_PEERS (1 by default),
+// HEALTH_MAX_BLOCK_STALL (the time without a new block before the node is down, 1m
+// by default) and HEALTH_CACHE_TTL (2s by default, 0 disables the cache).
+// Parameters:
+//   - database: The DatabaseProbe.
+//   - node: The NodeProbe.
+//
+// Returns:
+//   - A pointer to HealthService if successful.
+//   - An error if a variable is invalid.
+func NewService(database healthDomain.DatabaseProbe, node healthDomain.NodeProbe) (*HealthService, error) {
+	service := &HealthService{
+		database:      database,
+		node:          node,
+		minPeers:      1,
+		maxBlockStall: time.Minute,
+		cacheTTL:      2 * time.Second,
+		now:           time.Now,
+	}
+	if env := os.Getenv("BESU_CHAIN_ID"); env != "" {
+		chainID, ok := new(big.Int).SetString(env, 10)
+		if !ok || chainID.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid BESU_CHAIN_ID %q", env)
+		}
+		service.chainID = chainID
+	}
+	if env := os.Getenv("HEALTH_MIN_PEERS"); env != "" {
+		peers, err := strconv.ParseUint(env, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HEALTH_MIN_PEERS %q", env)
+		}
+		service.minPeers = peers
+	}
+	if env := os.Getenv("HEALTH_MAX_BLOCK_STALL"); env != "" {
+		stall, err := time.ParseDuration(env)
+		if err != nil || stall <= 0 {
+			return nil, fmt.Errorf("invalid HEALTH_MAX_BLOCK_STALL %q", env)
+		}
+		service.maxBlockStall = stall
+	}
+	if env := os.Getenv("HEALTH_CACHE_TTL"); env != "" {
+		ttl, err := time.ParseDuration(env)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid HEALTH_CACHE_TTL %q", env)
+		}
+		service.cacheTTL = ttl
+	}
+	return service, nil
+}
+
+// Readiness checks the dependencies, or returns the report of a check made less
+// than HEALTH_CACHE_TTL ago.
+func (r *HealthService) Readiness() healthDomain.Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.report != nil && r.now().Sub(r.report.CheckedAt) < r.cacheTTL {
+		report := *r.report
+		report.Cached = true
+		return report
+	}
+
+	checks := map[string]func() healthDomain.Check{
+		"database":   r.checkDatabase,
+		"migrations": r.checkMigrations,
+		"besu":       r.checkNode,
+	}
+	report := healthDomain.Report{Status: healthDomain.StatusUp, Checks: map[string]healthDomain.Check{}, CheckedAt: r.now()}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := timed(r.now, check)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != healthDomain.StatusUp {
+				report.Status = healthDomain.StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+	if report.Status != healthDomain.StatusUp {
+		slog.Warn("Service not ready", "checks", report.Checks)
+	}
+	r.report = &report
+	return report
+}
+
+// timed runs a check and measures its latency.
+func timed(now func() time.Time, check func() healthDomain.Check) healthDomain.Check {
+	start := now()
+	result := check()
+	result.LatencyMs = float64(now().Sub(start).Microseconds()) / 1000
+	return result
+}
+
+func down(err error, details map[string]any) healthDomain.Check {
+	return healthDomain.Check{Status: healthDomain.StatusDown, Error: err.Error(), Details: details}
+}
+
+func (r *HealthService) checkDatabase() healthDomain.Check {
+	if err := r.database.Ping(); err != nil {
+		return down(err, nil)
+	}
+	return healthDomain.Check{Status: healthDomain.StatusUp}
+}
+
+// checkMigrations checks that the database is on the schema of the binary: an
+// older one lacks tables, a dirty one had a migration fail halfway.
+func (r *HealthService) checkMigrations() healthDomain.Check {
+	latest, err := r.database.LatestSchemaVersion()
+	if err != nil {
+		return down(err, nil)
+	}
+	version, dirty, err := r.database.SchemaVersion()
+	if err != nil {
+		return down(err, nil)
+	}
+	details := map[string]any{"version": version, "expectedVersion": latest, "dirty": dirty}
+	switch {
+	case dirty:
+		return down(fmt.Errorf("migration %d failed halfway", version), details)
+	case version != latest:
+		return down(fmt.Errorf("schema version %d, want %d", version, latest), details)
+	}
+	return healthDomain.Check{Status: healthDomain.StatusUp, Details: details}
+}
+
+// checkNode checks that the node is on the expected chain, produces blocks and has peers.
+func (r *HealthService) checkNode() healthDomain.Check {
+	chainID, err := r.node.ChainID()
+	if err != nil {
+		return down(err, nil)
+	}
+	block, err := r.node.BlockNumber()
+	if err != nil {
+		return down(err, nil)
+	}
+	peers, err := r.node.PeerCount()
+	if err != nil {
+		return down(err, nil)
+	}
+
+	// the checks of a report run in parallel, only this one reads and moves the head
+	now := r.now()
+	if r.chainID == nil {
+		r.chainID = chainID
+	}
+	if !r.pinnedHead || block > r.head {
+		r.head, r.headSince, r.pinnedHead = block, now, true
+	}
+	stalled := now.Sub(r.headSince)
+	details := map[string]any{
+		"chainId":              chainID.String(),
+		"blockNumber":          block,
+		"peerCount":            peers,
+		"secondsSinceNewBlock": int64(stalled.Seconds()),
+	}
+	switch {
+	case chainID.Cmp(r.chainID) != 0:
+		return down(fmt.Errorf("chain id %s, want %s", chainID, r.chainID), details)
+	case stalled > r.maxBlockStall:
+		return down(fmt.Errorf("no new block for %s", stalled.Truncate(time.Second)), details)
+	case peers < r.minPeers:
+		return down(fmt.Errorf("%d peers, want %d at least", peers, r.minPeers), details)
+	}
+	return healthDomain.Check{Status: healthDomain.StatusUp, Details: details}
+}
@@ -0,0 +1,182 @@
+package healthApp
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/health"
+	"goledger-challenge-besu/internal/domain/health/fake"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+type fixture struct {
+	database *healthFake.Database
+	node     *healthFake.Node
+	service  *HealthService
+	clock    time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{
+		database: &healthFake.Database{Version: 8, Latest: 8},
+		node:     healthFake.NewNode(1337, 100, 3),
+		clock:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	service, err := NewService(f.database, f.node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.now = func() time.Time { return f.clock }
+	f.service = service
+	return f
+}
+
+// wantChecks compares the status of the report and of each of its checks.
+func wantChecks(t *testing.T, report healthDomain.Report, want map[string]healthDomain.Status) {
+	t.Helper()
+	status := healthDomain.StatusUp
+	for name, wantStatus := range want {
+		if report.Checks[name].Status != wantStatus {
+			t.Errorf("check %s = %+v, want %s", name, report.Checks[name], wantStatus)
+		}
+		if wantStatus != healthDomain.StatusUp {
+			status = healthDomain.StatusDown
+		}
+	}
+	if report.Status != status || len(report.Checks) != len(want) {
+		t.Errorf("report = %+v, want %s with %d checks", report, status, len(want))
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	up, down := healthDomain.StatusUp, healthDomain.StatusDown
+	tests := []struct {
+		name  string
+		env   map[string]string
+		setup func(f *fixture)
+		want  map[string]healthDomain.Status
+	}{
+		{name: "ready", want: map[string]healthDomain.Status{"database": up, "migrations": up, "besu": up}},
+		{
+			name:  "database unavailable",
+			setup: func(f *fixture) { f.database.PingErr = domain.ErrInternal },
+			want:  map[string]healthDomain.Status{"database": down, "migrations": up, "besu": up},
+		},
+		{
+			name:  "schema behind",
+			setup: func(f *fixture) { f.database.Version = 7 },
+			want:  map[string]healthDomain.Status{"database": up, "migrations": down, "besu": up},
+		},
+		{
+			name:  "dirty schema",
+			setup: func(f *fixture) { f.database.Dirty = true },
+			want:  map[string]healthDomain.Status{"database": up, "migrations": down, "besu": up},
+		},
+		{
+			name:  "node unavailable",
+			setup: func(f *fixture) { f.node.Set(1337, 100, 3, domain.ErrNodeUnavailable) },
+			want:  map[string]healthDomain.Status{"database": up, "migrations": up, "besu": down},
+		},
+		{
+			name: "other chain", env: map[string]string{"BESU_CHAIN_ID": "1"},
+			want: map[string]healthDomain.Status{"database": up, "migrations": up, "besu": down},
+		},
+		{
+			name: "expected chain", env: map[string]string{"BESU_CHAIN_ID": "1337"},
+			want: map[string]healthDomain.Status{"database": up, "migrations": up, "besu": up},
+		},
+		{
+			name:  "no peers",
+			setup: func(f *fixture) { f.node.Set(1337, 100, 0, nil) },
+			want:  map[string]healthDomain.Status{"database": up, "migrations": up, "besu": down},
+		},
+		{
+			name: "no peers required", env: map[string]string{"HEALTH_MIN_PEERS": "0"},
+			setup: func(f *fixture) { f.node.Set(1337, 100, 0, nil) },
+			want:  map[string]healthDomain.Status{"database": up, "migrations": up, "besu": up},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			f := newFixture(t)
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			wantChecks(t, f.service.Readiness(), tt.want)
+		})
+	}
+}
+
+func TestReadinessBlocksAndCache(t *testing.T) {
+	t.Setenv("HEALTH_CACHE_TTL", "5s")
+	t.Setenv("HEALTH_MAX_BLOCK_STALL", "30s")
+	f := newFixture(t)
+	all := func(besu healthDomain.Status) map[string]healthDomain.Status {
+		return map[string]healthDomain.Status{"database": healthDomain.StatusUp, "migrations": healthDomain.StatusUp, "besu": besu}
+	}
+
+	wantChecks(t, f.service.Readiness(), all(healthDomain.StatusUp))
+	// the probes within the ttl get the same report, without calling the dependencies
+	f.clock = f.clock.Add(4 * time.Second)
+	if report := f.service.Readiness(); !report.Cached || f.database.Pings != 1 {
+		t.Errorf("report = %+v after %d pings, want the cached one", report, f.database.Pings)
+	}
+
+	// the chain stops producing blocks
+	f.clock = f.clock.Add(20 * time.Second)
+	wantChecks(t, f.service.Readiness(), all(healthDomain.StatusUp))
+	f.clock = f.clock.Add(20 * time.Second)
+	report := f.service.Readiness()
+	wantChecks(t, report, all(healthDomain.StatusDown))
+	if report.Cached || f.database.Pings != 3 {
+		t.Errorf("report cached %v after %d pings, want a new one", report.Cached, f.database.Pings)
+	}
+
+	// and starts again
+	f.node.Set(1337, 101, 3, nil)
+	f.clock = f.clock.Add(10 * time.Second)
+	wantChecks(t, f.service.Readiness(), all(healthDomain.StatusUp))
+
+	// the chain ID is pinned by the first check
+	f.node.Set(1, 102, 3, nil)
+	f.clock = f.clock.Add(10 * time.Second)
+	wantChecks(t, f.service.Readiness(), all(healthDomain.StatusDown))
+}
+
+func TestNewService(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		valid bool
+	}{
+		{"defaults", nil, true},
+		{"chain id", map[string]string{"BESU_CHAIN_ID": "0x539"}, false},
+		{"min peers", map[string]string{"HEALTH_MIN_PEERS": "-1"}, false},
+		{"block stall", map[string]string{"HEALTH_MAX_BLOCK_STALL": "0s"}, false},
+		{"cache disabled", map[string]string{"HEALTH_CACHE_TTL": "0s"}, true},
+		{"cache ttl", map[string]string{"HEALTH_CACHE_TTL": "-1s"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			_, err := NewService(&healthFake.Database{}, healthFake.NewNode(1337, 1, 1))
+			if (err == nil) != tt.valid {
+				t.Errorf("NewService() error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
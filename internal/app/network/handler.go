@@ -0,0 +1,498 @@
+package networkApp
+
+import (
+	"net/http"
+	"strconv"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/network"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gin-gonic/gin"
+)
+
+// NetworkHandler handles HTTP requests related to the Besu network and its nodes.
+type NetworkHandler struct {
+	// The service layer for inspecting the Besu network.
+	service *NetworkService
+}
+
+// NewHandler initializes a new NetworkHandler.
+// Parameters:
+//   - service: The NetworkService used for business logic.
+//
+// Returns:
+//   - A pointer to a newly created NetworkHandler.
+func NewHandler(service *NetworkService) *NetworkHandler {
+	return &NetworkHandler{service}
+}
+
+// statusCode maps the errors returned by the service to HTTP status codes.
+func statusCode(err error) int {
+	switch err {
+	case domain.ErrDataNotFound:
+		return http.StatusNotFound
+	case domain.ErrNodeMethodDisabled:
+		return http.StatusNotImplemented
+	case domain.ErrNodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// nodeQuery reads the optional "node" query param (index of the node in BESU_URL).
+func nodeQuery(ctx *gin.Context) (*int, bool) {
+	nodeStr, ok := ctx.GetQuery("node")
+	if !ok {
+		return nil, true
+	}
+	node, err := strconv.Atoi(nodeStr)
+	if err != nil || node < 0 {
+		return nil, false
+	}
+	return &node, true
+}
+
+// blockQuery reads an optional block tag query param, nil when it is missing.
+func blockQuery(ctx *gin.Context, key string) (*rpc.BlockNumber, bool) {
+	tag, ok := ctx.GetQuery(key)
+	if !ok {
+		return nil, true
+	}
+	block, err := domain.ParseBlockTag(tag)
+	if err != nil {
+		return nil, false
+	}
+	return &block, true
+}
+
+// GetStatus retrieves the connection state of each configured Besu node.
+// HTTP Method: GET
+// URL: /network/status
+// Responses:
+//   - 200: The status of each node, when at least one is available.
+//   - 503: The status of each node, when none is available.
+func (r *NetworkHandler) GetStatus(ctx *gin.Context) {
+	status := r.service.GetStatus()
+	if !status.Available {
+		ctx.JSON(http.StatusServiceUnavailable, status)
+		return
+	}
+	ctx.JSON(http.StatusOK, status)
+}
+
+// GetValidators retrieves the QBFT validator set.
+// HTTP Method: GET
+// URL: /network/validators
+// Query Parameters:
+//   - block (string, optional): Block tag or number (defaults to "latest").
+//
+// Responses:
+//   - 200: The addresses of the validators.
+//   - 400: Bad request if the block is invalid.
+//   - 500: Internal server error if the RPC call fails.
+//   - 501: Not implemented if the QBFT api isn't enabled on the node.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *NetworkHandler) GetValidators(ctx *gin.Context) {
+	block, err := domain.ParseBlockTag(ctx.Query("block"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param block")
+		return
+	}
+	validators, err := r.service.GetValidators(block)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, validators)
+}
+
+// GetSignerMetrics retrieves the number of blocks proposed by each validator.
+// HTTP Method: GET
+// URL: /network/validators/metrics
+// Query Parameters:
+//   - from (string, optional): First block of the range (defaults to the last 100 blocks).
+//   - to (string, optional): Last block of the range (defaults to "latest", requires from).
+//
+// Responses:
+//   - 200: The metrics of each validator.
+//   - 400: Bad request if a block is invalid.
+//   - 500: Internal server error if the RPC call fails.
+//   - 501: Not implemented if the QBFT api isn't enabled on the node.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *NetworkHandler) GetSignerMetrics(ctx *gin.Context) {
+	fromBlock, ok := blockQuery(ctx, "from")
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param from")
+		return
+	}
+	toBlock, ok := blockQuery(ctx, "to")
+	if !ok || (toBlock != nil && fromBlock == nil) {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param to")
+		return
+	}
+	metrics, err := r.service.GetSignerMetrics(fromBlock, toBlock)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, metrics)
+}
+
+// GetPeers retrieves the peers connected to a node.
+// HTTP Method: GET
+// URL: /network/peers
+// Query Parameters:
+//   - node (int, optional): Index of the node in BESU_URL (defaults to any healthy node).
+//
+// Responses:
+//   - 200: The peers of the node.
+//   - 400: Bad request if the node is invalid.
+//   - 404: Not found if there is no node at the index.
+//   - 500: Internal server error if the RPC call fails.
+//   - 501: Not implemented if the ADMIN api isn't enabled on the node.
+//   - 503: Service unavailable if the node isn't available.
+func (r *NetworkHandler) GetPeers(ctx *gin.Context) {
+	node, ok := nodeQuery(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param node")
+		return
+	}
+	peers, err := r.service.GetPeers(node)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, peers)
+}
+
+// GetPeerCount retrieves the number of peers connected to a node.
+// HTTP Method: GET
+// URL: /network/peers/count
+// Query Parameters:
+//   - node (int, optional): Index of the node in BESU_URL (defaults to any healthy node).
+//
+// Responses:
+//   - 200: The peer count of the node.
+//   - 400: Bad request if the node is invalid.
+//   - 404: Not found if there is no node at the index.
+//   - 500: Internal server error if the RPC call fails.
+//   - 503: Service unavailable if the node isn't available.
+func (r *NetworkHandler) GetPeerCount(ctx *gin.Context) {
+	node, ok := nodeQuery(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param node")
+		return
+	}
+	count, err := r.service.GetPeerCount(node)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, count)
+}
+
+// GetSyncStatus retrieves the synchronization progress of a node.
+// HTTP Method: GET
+// URL: /network/syncing
+// Query Parameters:
+//   - node (int, optional): Index of the node in BESU_URL (defaults to any healthy node).
+//
+// Responses:
+//   - 200: The sync status of the node.
+//   - 400: Bad request if the node is invalid.
+//   - 404: Not found if there is no node at the index.
+//   - 500: Internal server error if the RPC call fails.
+//   - 503: Service unavailable if the node isn't available.
+func (r *NetworkHandler) GetSyncStatus(ctx *gin.Context) {
+	node, ok := nodeQuery(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param node")
+		return
+	}
+	status, err := r.service.GetSyncStatus(node)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, status)
+}
+
+// GetTxPoolStatistics retrieves the transaction pool counters of a node.
+// HTTP Method: GET
+// URL: /network/txpool
+// Query Parameters:
+//   - node (int, optional): Index of the node in BESU_URL (defaults to any healthy node).
+//
+// Responses:
+//   - 200: The txpool statistics of the node.
+//   - 400: Bad request if the node is invalid.
+//   - 404: Not found if there is no node at the index.
+//   - 500: Internal server error if the RPC call fails.
+//   - 501: Not implemented if the TXPOOL api isn't enabled on the node.
+//   - 503: Service unavailable if the node isn't available.
+func (r *NetworkHandler) GetTxPoolStatistics(ctx *gin.Context) {
+	node, ok := nodeQuery(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid query param node")
+		return
+	}
+	statistics, err := r.service.GetTxPoolStatistics(node)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, statistics)
+}
+
+// voteStatusCode is 200 when at least one node accepted the action, 502 otherwise.
+func voteStatusCode(results []networkDomain.VoteResult) int {
+	for _, result := range results {
+		if result.Success {
+			return http.StatusOK
+		}
+	}
+	return http.StatusBadGateway
+}
+
+// GetValidatorVotes retrieves the current validator set and the pending votes of each node.
+// HTTP Method: GET
+// URL: /network/validators/votes
+// Responses:
+//   - 200: The validators and the pending votes of each node.
+//   - 500: Internal server error if the RPC call fails.
+//   - 501: Not implemented if the QBFT api isn't enabled on the node.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *NetworkHandler) GetValidatorVotes(ctx *gin.Context) {
+	votes, err := r.service.GetValidatorVotes()
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, votes)
+}
+
+type proposeValidatorVoteRequest struct {
+	Validator string `json:"validator" binding:"required" example:"0x42699A7612A82f1d9C36148af9C77354759b210b"`
+	Add       *bool  `json:"add" binding:"required" example:"true"`
+}
+
+// ProposeValidatorVote proposes adding or removing a validator on every configured node.
+// HTTP Method: POST
+// URL: /network/validators/votes
+// Request Body:
+//   - validator (string): The address of the validator.
+//   - add (bool): True to vote for adding the validator, false to vote for removing it.
+//
+// Responses:
+//   - 200: The result of the vote on each node, when at least one node accepted it.
+//   - 400: Bad request if input validation fails.
+//   - 500: Internal server error if the vote can't be audited.
+//   - 502: The result of the vote on each node, when every node refused it.
+func (r *NetworkHandler) ProposeValidatorVote(ctx *gin.Context) {
+	var req proposeValidatorVoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	if !common.IsHexAddress(req.Validator) {
+		ctx.JSON(http.StatusBadRequest, "Invalid validator address")
+		return
+	}
+	results, err := r.service.ProposeValidatorVote(ctx.Request.Context(), common.HexToAddress(req.Validator), *req.Add, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(voteStatusCode(results), results)
+}
+
+// DiscardValidatorVotes discards pending votes on every configured node.
+// HTTP Method: DELETE
+// URL: /network/validators/votes
+// Query Parameters:
+//   - validator (string, optional): Only discard the vote for this validator (defaults to every pending vote).
+//
+// Responses:
+//   - 200: The result of each discarded vote on each node.
+//   - 400: Bad request if the validator is invalid.
+//   - 500: Internal server error if the action can't be audited.
+//   - 502: The result of each discarded vote on each node, when every node failed.
+func (r *NetworkHandler) DiscardValidatorVotes(ctx *gin.Context) {
+	var validator *common.Address
+	if validatorStr, ok := ctx.GetQuery("validator"); ok {
+		if !common.IsHexAddress(validatorStr) {
+			ctx.JSON(http.StatusBadRequest, "Invalid query param validator")
+			return
+		}
+		address := common.HexToAddress(validatorStr)
+		validator = &address
+	}
+	results, err := r.service.DiscardValidatorVotes(ctx.Request.Context(), validator, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	if len(results) == 0 {
+		ctx.JSON(http.StatusOK, results)
+		return
+	}
+	ctx.JSON(voteStatusCode(results), results)
+}
+
+// permissioningStatusCode is 200 when at least one node applied the change, 502 otherwise.
+func permissioningStatusCode(results []networkDomain.PermissioningResult) int {
+	for _, result := range results {
+		if result.Success {
+			return http.StatusOK
+		}
+	}
+	return http.StatusBadGateway
+}
+
+type accountsAllowlistRequest struct {
+	Accounts []string `json:"accounts" binding:"required,min=1,dive,required" example:"0xfe3b557e8fb62b89f4916b721be55ceb828dbd73"`
+}
+
+func (req *accountsAllowlistRequest) addresses() ([]common.Address, bool) {
+	addresses := make([]common.Address, len(req.Accounts))
+	for i, account := range req.Accounts {
+		if !common.IsHexAddress(account) {
+			return nil, false
+		}
+		addresses[i] = common.HexToAddress(account)
+	}
+	return addresses, true
+}
+
+type nodesAllowlistRequest struct {
+	Nodes []string `json:"nodes" binding:"required,min=1,dive,required" example:"enode://6f8a80d1...@127.0.0.1:30303"`
+}
+
+func (req *nodesAllowlistRequest) valid() bool {
+	for _, node := range req.Nodes {
+		if _, err := enode.ParseV4(node); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// GetAccountsAllowlists retrieves the accounts allowlist of each node.
+// HTTP Method: GET
+// URL: /network/permissioning/accounts
+// Responses:
+//   - 200: The accounts allowlist of each node (or the error of the node).
+func (r *NetworkHandler) GetAccountsAllowlists(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, r.service.GetAccountsAllowlists())
+}
+
+// AddAccountsToAllowlist adds accounts to the allowlist of each node.
+// HTTP Method: POST
+// URL: /network/permissioning/accounts
+// Request Body:
+//   - accounts ([]string): The accounts to add.
+//
+// Responses:
+//   - 200: The result on each node, when at least one node applied it.
+//   - 400: Bad request if input validation fails.
+//   - 502: The result on each node, when every node failed.
+func (r *NetworkHandler) AddAccountsToAllowlist(ctx *gin.Context) {
+	var req accountsAllowlistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	accounts, ok := req.addresses()
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid account address")
+		return
+	}
+	results := r.service.AddAccountsToAllowlist(ctx.Request.Context(), accounts)
+	ctx.JSON(permissioningStatusCode(results), results)
+}
+
+// RemoveAccountsFromAllowlist removes accounts from the allowlist of each node.
+// HTTP Method: DELETE
+// URL: /network/permissioning/accounts
+// Request Body:
+//   - accounts ([]string): The accounts to remove.
+//
+// Responses:
+//   - 200: The result on each node, when at least one node applied it.
+//   - 400: Bad request if input validation fails.
+//   - 502: The result on each node, when every node failed.
+func (r *NetworkHandler) RemoveAccountsFromAllowlist(ctx *gin.Context) {
+	var req accountsAllowlistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	accounts, ok := req.addresses()
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, "Invalid account address")
+		return
+	}
+	results := r.service.RemoveAccountsFromAllowlist(ctx.Request.Context(), accounts)
+	ctx.JSON(permissioningStatusCode(results), results)
+}
+
+// GetNodesAllowlists retrieves the nodes allowlist of each node.
+// HTTP Method: GET
+// URL: /network/permissioning/nodes
+// Responses:
+//   - 200: The enode URLs allowlisted by each node (or the error of the node).
+func (r *NetworkHandler) GetNodesAllowlists(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, r.service.GetNodesAllowlists())
+}
+
+// AddNodesToAllowlist adds enode URLs to the allowlist of each node.
+// HTTP Method: POST
+// URL: /network/permissioning/nodes
+// Request Body:
+//   - nodes ([]string): The enode URLs to add.
+//
+// Responses:
+//   - 200: The result on each node, when at least one node applied it.
+//   - 400: Bad request if input validation fails.
+//   - 502: The result on each node, when every node failed.
+func (r *NetworkHandler) AddNodesToAllowlist(ctx *gin.Context) {
+	var req nodesAllowlistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	if !req.valid() {
+		ctx.JSON(http.StatusBadRequest, "Invalid enode URL")
+		return
+	}
+	results := r.service.AddNodesToAllowlist(ctx.Request.Context(), req.Nodes)
+	ctx.JSON(permissioningStatusCode(results), results)
+}
+
+// RemoveNodesFromAllowlist removes enode URLs from the allowlist of each node.
+// HTTP Method: DELETE
+// URL: /network/permissioning/nodes
+// Request Body:
+//   - nodes ([]string): The enode URLs to remove.
+//
+// Responses:
+//   - 200: The result on each node, when at least one node applied it.
+//   - 400: Bad request if input validation fails.
+//   - 502: The result on each node, when every node failed.
+func (r *NetworkHandler) RemoveNodesFromAllowlist(ctx *gin.Context) {
+	var req nodesAllowlistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	if !req.valid() {
+		ctx.JSON(http.StatusBadRequest, "Invalid enode URL")
+		return
+	}
+	results := r.service.RemoveNodesFromAllowlist(ctx.Request.Context(), req.Nodes)
+	ctx.JSON(permissioningStatusCode(results), results)
+}
@@ -0,0 +1,169 @@
+package networkApp
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"goledger-challenge-besu/internal/domain/audit"
+	"goledger-challenge-besu/internal/domain/network"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type NetworkService struct {
+	repositoryDB   *networkDomain.NetworkRepositoryDB
+	repositoryBesu *networkDomain.NetworkRepositoryBesu
+}
+
+func NewService(
+	repositoryDB *networkDomain.NetworkRepositoryDB,
+	repositoryBesu *networkDomain.NetworkRepositoryBesu) *NetworkService {
+	return &NetworkService{repositoryDB, repositoryBesu}
+}
+
+func (r *NetworkService) GetStatus() networkDomain.NetworkStatus {
+	status := r.repositoryBesu.GetStatus()
+	if !status.Available {
+		slog.Warn("No Besu node available", "nodes", len(status.Nodes))
+	}
+	return status
+}
+
+func (r *NetworkService) GetValidators(block rpc.BlockNumber) (networkDomain.Validators, error) {
+	validators, err := r.repositoryBesu.GetValidators(block)
+	if err != nil {
+		slog.Error("Erro getting validators from NetworkRepositoryBesu.GetValidators", "block", block.String())
+		return networkDomain.Validators{}, err
+	}
+	return validators, nil
+}
+
+func (r *NetworkService) GetSignerMetrics(fromBlock, toBlock *rpc.BlockNumber) ([]networkDomain.SignerMetric, error) {
+	metrics, err := r.repositoryBesu.GetSignerMetrics(fromBlock, toBlock)
+	if err != nil {
+		slog.Error("Erro getting signer metrics from NetworkRepositoryBesu.GetSignerMetrics")
+		return nil, err
+	}
+	return metrics, nil
+}
+
+func (r *NetworkService) GetPeers(node *int) ([]networkDomain.Peer, error) {
+	peers, err := r.repositoryBesu.GetPeers(node)
+	if err != nil {
+		slog.Error("Erro getting peers from NetworkRepositoryBesu.GetPeers")
+		return nil, err
+	}
+	return peers, nil
+}
+
+func (r *NetworkService) GetPeerCount(node *int) (networkDomain.PeerCount, error) {
+	count, err := r.repositoryBesu.GetPeerCount(node)
+	if err != nil {
+		slog.Error("Erro getting peer count from NetworkRepositoryBesu.GetPeerCount")
+		return networkDomain.PeerCount{}, err
+	}
+	return count, nil
+}
+
+func (r *NetworkService) GetSyncStatus(node *int) (networkDomain.SyncStatus, error) {
+	status, err := r.repositoryBesu.GetSyncStatus(node)
+	if err != nil {
+		slog.Error("Erro getting sync status from NetworkRepositoryBesu.GetSyncStatus")
+		return networkDomain.SyncStatus{}, err
+	}
+	return status, nil
+}
+
+func (r *NetworkService) GetTxPoolStatistics(node *int) (networkDomain.TxPoolStatistics, error) {
+	statistics, err := r.repositoryBesu.GetTxPoolStatistics(node)
+	if err != nil {
+		slog.Error("Erro getting txpool statistics from NetworkRepositoryBesu.GetTxPoolStatistics")
+		return networkDomain.TxPoolStatistics{}, err
+	}
+	return statistics, nil
+}
+
+func (r *NetworkService) GetValidatorVotes() (networkDomain.ValidatorVotes, error) {
+	validators, err := r.repositoryBesu.GetValidators(rpc.LatestBlockNumber)
+	if err != nil {
+		slog.Error("Erro getting validators from NetworkRepositoryBesu.GetValidators")
+		return networkDomain.ValidatorVotes{}, err
+	}
+	return networkDomain.ValidatorVotes{
+		Validators: validators.Validators,
+		Pending:    r.repositoryBesu.GetPendingVotes(),
+	}, nil
+}
+
+func (r *NetworkService) ProposeValidatorVote(ctx context.Context, validator common.Address, add bool, requestedBy string) ([]networkDomain.VoteResult, error) {
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) {
+		event.Detail("validator", validator.Hex())
+		event.Detail("add", strconv.FormatBool(add))
+	})
+	results := r.repositoryBesu.ProposeValidatorVote(validator, add)
+	// the votes are already cast at this point, but an action that can't be audited is reported as failed
+	err := r.repositoryDB.CreateValidatorVotes(results, requestedBy)
+	if err != nil {
+		slog.Error("Erro auditing validator vote in NetworkRepositoryDB.CreateValidatorVotes", "validator", validator.Hex(), "add", add)
+		return results, err
+	}
+	return results, nil
+}
+
+func (r *NetworkService) DiscardValidatorVotes(ctx context.Context, validator *common.Address, requestedBy string) ([]networkDomain.VoteResult, error) {
+	if validator != nil {
+		auditDomain.Annotate(ctx, func(event *auditDomain.Event) { event.Detail("validator", validator.Hex()) })
+	}
+	results := r.repositoryBesu.DiscardValidatorVotes(validator)
+	err := r.repositoryDB.CreateValidatorVotes(results, requestedBy)
+	if err != nil {
+		slog.Error("Erro auditing discarded validator votes in NetworkRepositoryDB.CreateValidatorVotes")
+		return results, err
+	}
+	return results, nil
+}
+
+func (r *NetworkService) GetAccountsAllowlists() []networkDomain.NodeAllowlist {
+	return r.repositoryBesu.GetAccountsAllowlists()
+}
+
+func (r *NetworkService) AddAccountsToAllowlist(ctx context.Context, accounts []common.Address) []networkDomain.PermissioningResult {
+	auditAccounts(ctx, accounts)
+	slog.Info("Adding accounts to the allowlist of the Besu nodes", "accounts", len(accounts))
+	return r.repositoryBesu.AddAccountsToAllowlist(accounts)
+}
+
+func (r *NetworkService) RemoveAccountsFromAllowlist(ctx context.Context, accounts []common.Address) []networkDomain.PermissioningResult {
+	auditAccounts(ctx, accounts)
+	slog.Info("Removing accounts from the allowlist of the Besu nodes", "accounts", len(accounts))
+	return r.repositoryBesu.RemoveAccountsFromAllowlist(accounts)
+}
+
+func (r *NetworkService) GetNodesAllowlists() []networkDomain.NodeAllowlist {
+	return r.repositoryBesu.GetNodesAllowlists()
+}
+
+func (r *NetworkService) AddNodesToAllowlist(ctx context.Context, enodes []string) []networkDomain.PermissioningResult {
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) { event.Detail("nodes", strings.Join(enodes, ",")) })
+	slog.Info("Adding nodes to the allowlist of the Besu nodes", "nodes", len(enodes))
+	return r.repositoryBesu.AddNodesToAllowlist(enodes)
+}
+
+func (r *NetworkService) RemoveNodesFromAllowlist(ctx context.Context, enodes []string) []networkDomain.PermissioningResult {
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) { event.Detail("nodes", strings.Join(enodes, ",")) })
+	slog.Info("Removing nodes from the allowlist of the Besu nodes", "nodes", len(enodes))
+	return r.repositoryBesu.RemoveNodesFromAllowlist(enodes)
+}
+
+// auditAccounts records the accounts of a change of the allowlist on the audit event
+// of the request.
+func auditAccounts(ctx context.Context, accounts []common.Address) {
+	hexes := make([]string, len(accounts))
+	for i, account := range accounts {
+		hexes[i] = account.Hex()
+	}
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) { event.Detail("accounts", strings.Join(hexes, ",")) })
+}
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"net/http"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
 )
 
@@ -24,21 +25,86 @@ func NewHandler(service *SmartContractService) *SmartContractHandler {
 	return &SmartContractHandler{service}
 }
 
+// statusCode maps the errors returned by the service to HTTP status codes.
+func statusCode(err error) int {
+	switch err {
+	case domain.ErrInvalidValue:
+		return http.StatusBadRequest
+	case domain.ErrUnauthorized:
+		return http.StatusUnauthorized
+	case domain.ErrSignerNotAllowlisted:
+		return http.StatusForbidden
+	case domain.ErrConflictingData, domain.ErrTransactionDropped:
+		return http.StatusConflict
+	case domain.ErrTransactionReverted:
+		return http.StatusUnprocessableEntity
+	case domain.ErrNodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // GetValue retrieves the current value stored in the smart contract.
 // HTTP Method: GET
 // URL: /smart-contract
 // Responses:
 //   - 200: The current value in the smart contract.
 //   - 500: Internal server error if retrieval fails.
+//   - 503: Service unavailable if no Besu node is available.
 func (r *SmartContractHandler) GetValue(ctx *gin.Context) {
-	value, err := r.service.GetValue()
+	value, err := r.service.GetValue(ctx.Request.Context())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err.Error())
+		ctx.JSON(statusCode(err), err.Error())
 		return
 	}
 	ctx.JSON(http.StatusOK, value)
 }
 
+type getValuesRequest struct {
+	Addresses []string `json:"addresses" binding:"required,min=1,max=500,dive,required" example:"0x42699A7612A82f1d9C36148af9C77354759b210b"`
+	Block     string   `json:"block" example:"latest"`
+}
+
+// GetValues retrieves the values stored in many smart contracts in a single RPC round-trip.
+// HTTP Method: POST
+// URL: /smart-contracts/values
+// Request Body:
+//   - addresses ([]string): The addresses of the contracts to read, 500 at most.
+//   - block (string, optional): Block tag or number to read at (defaults to "latest").
+//
+// Responses:
+//   - 200: The value (or the error) of each contract, in the requested order.
+//   - 400: Bad request if input validation fails.
+//   - 500: Internal server error if the batch read fails.
+//   - 503: Service unavailable if no Besu node is available.
+func (r *SmartContractHandler) GetValues(ctx *gin.Context) {
+	var req getValuesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	addresses := make([]common.Address, len(req.Addresses))
+	for i, address := range req.Addresses {
+		if !common.IsHexAddress(address) {
+			ctx.JSON(http.StatusBadRequest, "Invalid address "+address)
+			return
+		}
+		addresses[i] = common.HexToAddress(address)
+	}
+	block, err := domain.ParseBlockTag(req.Block)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, "Invalid block tag")
+		return
+	}
+	values, err := r.service.GetValues(ctx.Request.Context(), addresses, block)
+	if err != nil {
+		ctx.JSON(statusCode(err), err.Error())
+		return
+	}
+	ctx.JSON(http.StatusOK, values)
+}
+
 type setValueRequest struct {
 	Value      big.Int `json:"value" binding:"required,omitempty" example:"0"`
 	PrivateKey string  `json:"privateKey" binding:"required,omitempty" example:"ef321a27ac482e12c1d1"`
@@ -53,22 +119,29 @@ type setValueRequest struct {
 //
 // Responses:
 //   - 200: Success message upon updating the value.
-//   - 400: Bad request if input validation fails.
+//   - 202: Accepted, with the hash of the transaction, if it was sent but not mined before
+//     the request timeout. It is still waited for in background, and its response is kept
+//     for the retries with the same Idempotency-Key.
+//   - 400: Bad request if input validation fails or the value is out of the uint256 range.
 //   - 401: Unauthorized if the private key is invalid.
+//   - 403: Forbidden if the signer is not in the accounts allowlist of the nodes.
+//   - 409: Conflict if the transaction was dropped by the nodes without being mined.
+//   - 422: Unprocessable entity if the transaction was mined but reverted.
 //   - 500: Internal server error if the update fails.
+//   - 503: Service unavailable if no Besu node is available.
 func (r *SmartContractHandler) SetValue(ctx *gin.Context) {
 	var req setValueRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, err.Error())
 		return
 	}
-	err := r.service.SetValue(&req.Value, req.PrivateKey)
+	hash, err := r.service.SetValue(ctx.Request.Context(), &req.Value, req.PrivateKey)
+	if err == domain.ErrTransactionPending {
+		ctx.JSON(http.StatusAccepted, gin.H{"message": err.Error(), "hash": hash})
+		return
+	}
 	if err != nil {
-		if err == domain.ErrUnauthorized {
-			ctx.JSON(http.StatusUnauthorized, err.Error())
-		} else {
-			ctx.JSON(http.StatusInternalServerError, err.Error())
-		}
+		ctx.JSON(statusCode(err), err.Error())
 		return
 	}
 	ctx.JSON(http.StatusOK, "New Value Defined Successfully")
@@ -82,17 +155,19 @@ func (r *SmartContractHandler) SetValue(ctx *gin.Context) {
 //
 // Responses:
 //   - 200: True or false indicating if the value matches.
-//   - 400: Bad request if the input value is invalid.
+//   - 400: Bad request if the input value is invalid or out of the uint256 range.
 //   - 500: Internal server error if the verification fails.
+//   - 503: Service unavailable if no Besu node is available.
 func (r *SmartContractHandler) CheckValue(ctx *gin.Context) {
 	valueStr := ctx.Param("value")
 	value, ok := new(big.Int).SetString(valueStr, 10)
 	if !ok {
 		ctx.JSON(http.StatusBadRequest, "Invalid param value")
+		return
 	}
-	isEqual, err := r.service.CheckValue(value)
+	isEqual, err := r.service.CheckValue(ctx.Request.Context(), value)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err.Error())
+		ctx.JSON(statusCode(err), err.Error())
 		return
 	}
 	ctx.JSON(http.StatusOK, isEqual)
@@ -102,13 +177,16 @@ func (r *SmartContractHandler) CheckValue(ctx *gin.Context) {
 // HTTP Method: POST
 // URL: /smart-contract/sync
 // Responses:
-//   - 200: Success message upon synchronization.
+//   - 200: The stored value with its block, and "changed" false when the database
+//     already held the value of the latest block or of a newer one.
+//   - 409: Conflict if the stored contract conflicts with existing data.
 //   - 500: Internal server error if synchronization fails.
+//   - 503: Service unavailable if no Besu node is available.
 func (r *SmartContractHandler) SyncValue(ctx *gin.Context) {
-	err := r.service.SyncValue()
+	result, err := r.service.SyncValue(ctx.Request.Context())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err.Error())
+		ctx.JSON(statusCode(err), err.Error())
 		return
 	}
-	ctx.JSON(http.StatusOK, "Sync Successfully")
+	ctx.JSON(http.StatusOK, result)
 }
@@ -0,0 +1,169 @@
+package smartContractApp
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+func newRouter(f *fixture) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(f.service)
+	router := gin.New()
+	router.GET("/smart-contract", handler.GetValue)
+	router.GET("/smart-contract/check-value/:value", handler.CheckValue)
+	router.POST("/smart-contract/set-value", handler.SetValue)
+	router.POST("/smart-contract/sync", handler.SyncValue)
+	router.POST("/smart-contracts/values", handler.GetValues)
+	return router
+}
+
+func TestHandlers(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		setup      func(f *fixture)
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "get value", method: http.MethodGet, url: "/smart-contract", wantStatus: http.StatusOK, wantBody: "7"},
+		{
+			name: "get value above uint64", method: http.MethodGet, url: "/smart-contract",
+			setup:      func(f *fixture) { f.contract.SendValue(context.Background(), maxUint64PlusOne, aliceKey) },
+			wantStatus: http.StatusOK, wantBody: "18446744073709551616",
+		},
+		{
+			name: "get value without node", method: http.MethodGet, url: "/smart-contract",
+			setup:      func(f *fixture) { f.contract.GetErr = domain.ErrNodeUnavailable },
+			wantStatus: http.StatusServiceUnavailable, wantBody: `"` + domain.ErrNodeUnavailable.Error() + `"`,
+		},
+		{
+			name: "get value call error", method: http.MethodGet, url: "/smart-contract",
+			setup:      func(f *fixture) { f.contract.GetErr = domain.ErrBoundContractCall },
+			wantStatus: http.StatusInternalServerError,
+		},
+
+		{name: "check equal value", method: http.MethodGet, url: "/smart-contract/check-value/7", wantStatus: http.StatusOK, wantBody: "true"},
+		{name: "check different value", method: http.MethodGet, url: "/smart-contract/check-value/8", wantStatus: http.StatusOK, wantBody: "false"},
+		{name: "check value above uint64", method: http.MethodGet, url: "/smart-contract/check-value/18446744073709551623", wantStatus: http.StatusOK, wantBody: "false"},
+		{name: "check value not a number", method: http.MethodGet, url: "/smart-contract/check-value/abc", wantStatus: http.StatusBadRequest, wantBody: `"Invalid param value"`},
+		{name: "check negative value", method: http.MethodGet, url: "/smart-contract/check-value/-1", wantStatus: http.StatusBadRequest},
+
+		{name: "set value", method: http.MethodPost, url: "/smart-contract/set-value", body: `{"value": 42, "privateKey": "` + aliceKey + `"}`, wantStatus: http.StatusOK},
+		{name: "set zero", method: http.MethodPost, url: "/smart-contract/set-value", body: `{"value": 0, "privateKey": "` + aliceKey + `"}`, wantStatus: http.StatusOK},
+		{name: "set max uint64", method: http.MethodPost, url: "/smart-contract/set-value", body: `{"value": 18446744073709551615, "privateKey": "` + aliceKey + `"}`, wantStatus: http.StatusOK},
+		{name: "set value above uint64", method: http.MethodPost, url: "/smart-contract/set-value", body: `{"value": 18446744073709551616, "privateKey": "` + aliceKey + `"}`, wantStatus: http.StatusOK},
+		{
+			name: "set value above uint256", method: http.MethodPost, url: "/smart-contract/set-value",
+			body:       `{"value": 115792089237316195423570985008687907853269984665640564039457584007913129639936, "privateKey": "` + aliceKey + `"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{name: "set negative value", method: http.MethodPost, url: "/smart-contract/set-value", body: `{"value": -1, "privateKey": "` + aliceKey + `"}`, wantStatus: http.StatusBadRequest},
+		{name: "set value without private key", method: http.MethodPost, url: "/smart-contract/set-value", body: `{"value": 1}`, wantStatus: http.StatusBadRequest},
+		{name: "set value malformed body", method: http.MethodPost, url: "/smart-contract/set-value", body: `{"value": "1"`, wantStatus: http.StatusBadRequest},
+		{name: "set value invalid private key", method: http.MethodPost, url: "/smart-contract/set-value", body: `{"value": 1, "privateKey": "zz"}`, wantStatus: http.StatusUnauthorized},
+		{
+			name: "set value signer not allowlisted", method: http.MethodPost, url: "/smart-contract/set-value",
+			body:       `{"value": 1, "privateKey": "` + aliceKey + `"}`,
+			setup:      func(f *fixture) { f.allowlist.Accounts = []common.Address{bob} },
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "set value without node", method: http.MethodPost, url: "/smart-contract/set-value",
+			body:       `{"value": 1, "privateKey": "` + aliceKey + `"}`,
+			setup:      func(f *fixture) { f.contract.SetErr = domain.ErrNodeUnavailable },
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "set value transaction error", method: http.MethodPost, url: "/smart-contract/set-value",
+			body:       `{"value": 1, "privateKey": "` + aliceKey + `"}`,
+			setup:      func(f *fixture) { f.contract.SetErr = domain.ErrBoundContractTransact },
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "set value wait cut short", method: http.MethodPost, url: "/smart-contract/set-value",
+			body:       `{"value": 1, "privateKey": "` + aliceKey + `"}`,
+			setup:      func(f *fixture) { f.contract.WaitErr = domain.ErrBoundContractTransact },
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name: "set value reverted", method: http.MethodPost, url: "/smart-contract/set-value",
+			body:       `{"value": 1, "privateKey": "` + aliceKey + `"}`,
+			setup:      func(f *fixture) { f.contract.Revert = true },
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+
+		{name: "sync", method: http.MethodPost, url: "/smart-contract/sync", wantStatus: http.StatusOK},
+		{
+			name: "sync conflict", method: http.MethodPost, url: "/smart-contract/sync",
+			setup:      func(f *fixture) { f.store.SyncErr = domain.ErrConflictingData },
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "sync database error", method: http.MethodPost, url: "/smart-contract/sync",
+			setup:      func(f *fixture) { f.store.SyncErr = domain.ErrInternal },
+			wantStatus: http.StatusInternalServerError,
+		},
+
+		{name: "get values", method: http.MethodPost, url: "/smart-contracts/values", body: `{"addresses": ["0x42699A7612A82f1d9C36148af9C77354759b210b"]}`, wantStatus: http.StatusOK},
+		{name: "get values invalid address", method: http.MethodPost, url: "/smart-contracts/values", body: `{"addresses": ["0x42"]}`, wantStatus: http.StatusBadRequest},
+		{name: "get values without addresses", method: http.MethodPost, url: "/smart-contracts/values", body: `{"addresses": []}`, wantStatus: http.StatusBadRequest},
+		{
+			name: "get values too many addresses", method: http.MethodPost, url: "/smart-contracts/values",
+			body:       `{"addresses": [` + strings.Repeat(`"0x42699A7612A82f1d9C36148af9C77354759b210b",`, 500) + `"0x42699A7612A82f1d9C36148af9C77354759b210b"]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{name: "get values invalid block", method: http.MethodPost, url: "/smart-contracts/values", body: `{"addresses": ["0x42699A7612A82f1d9C36148af9C77354759b210b"], "block": "tomorrow"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(big.NewInt(7))
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+
+			newRouter(f).ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+			if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+				t.Errorf("body = %s, want %s", recorder.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{domain.ErrInvalidValue, http.StatusBadRequest},
+		{domain.ErrUnauthorized, http.StatusUnauthorized},
+		{domain.ErrSignerNotAllowlisted, http.StatusForbidden},
+		{domain.ErrConflictingData, http.StatusConflict},
+		{domain.ErrTransactionReverted, http.StatusUnprocessableEntity},
+		{domain.ErrNodeUnavailable, http.StatusServiceUnavailable},
+		{domain.ErrBoundContractCall, http.StatusInternalServerError},
+		{domain.ErrInternal, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := statusCode(tt.err); got != tt.want {
+			t.Errorf("statusCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
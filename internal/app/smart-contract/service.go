@@ -1,76 +1,298 @@
 package smartContractApp
 
 import (
+	"context"
 	"log/slog"
 	"math/big"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"goledger-challenge-besu/configs/metrics"
+	"goledger-challenge-besu/configs/tracing"
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/audit"
+	"goledger-challenge-besu/internal/domain/network"
 	"goledger-challenge-besu/internal/domain/smart-contract"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// SmartContractService is safe for concurrent use: its only mutable state is the
+// count of the transactions being waited for, and the repositories hold none. The
+// on-chain reads are cached by the Backend of the Besu repository (see
+// smartContractDomain.CachedBackend). Each method runs in a span, child of the one
+// of its context (the span of the request).
 type SmartContractService struct {
-	repositoryDB   *smartContractDomain.SmartContractRepositoryDB
-	repositoryBesu *smartContractDomain.SmartContractRepositoryBesu
+	store        smartContractDomain.ValueStore
+	transactions smartContractDomain.TransactionStore
+	reader       smartContractDomain.ValueReader
+	writer       smartContractDomain.ValueWriter
+	allowlist    networkDomain.AccountAllowlist
+
+	waiting  atomic.Int64 // transactions being waited for
+	tracking sync.Map     // hashes being waited for, so Track doesn't wait twice
 }
 
 func NewService(
-	repositoryDB *smartContractDomain.SmartContractRepositoryDB,
-	repositoryBesu *smartContractDomain.SmartContractRepositoryBesu) *SmartContractService {
-	return &SmartContractService{repositoryDB, repositoryBesu}
+	store smartContractDomain.ValueStore,
+	transactions smartContractDomain.TransactionStore,
+	reader smartContractDomain.ValueReader,
+	writer smartContractDomain.ValueWriter,
+	allowlist networkDomain.AccountAllowlist) *SmartContractService {
+	return &SmartContractService{store: store, transactions: transactions, reader: reader, writer: writer, allowlist: allowlist}
 }
 
-func (r *SmartContractService) GetValue() (*big.Int, error) {
-	// for multiple requests, a cache system or a service method could be implemented
-	// that calls a repository of more than one type of contract
-	value, err := r.repositoryBesu.GetValue()
+// validateValue checks that the value fits the uint256 argument of the contract,
+// the ABI encoder would otherwise wrap negative values around.
+func validateValue(value *big.Int) error {
+	if value.Sign() < 0 || value.Cmp(math.MaxBig256) > 0 {
+		return domain.ErrInvalidValue
+	}
+	return nil
+}
+
+func (r *SmartContractService) GetValue(ctx context.Context) (value *big.Int, err error) {
+	ctx, span := tracingConfig.Start(ctx, "SmartContractService.GetValue")
+	defer func() { tracingConfig.End(span, err) }()
+
+	value, err = r.reader.GetValue(ctx)
 	if err != nil {
-		slog.Error("Erro getting value from SmartContractRepositoryBesu.GetValue")
+		slog.ErrorContext(ctx, "Erro getting value from ValueReader.GetValue")
 		return new(big.Int), err
 	}
-	// if the repositoryDB singleton has never been updated
-	if r.repositoryDB.SmartContract.UpdatedAt.IsZero() {
-		r.repositoryDB.SmartContract.Value = value.Uint64()
-	}
 	return value, nil
 }
 
-func (r *SmartContractService) SetValue(value *big.Int, privateKey string) error {
-	// here it would be possible to validate the value before passing it to the repository
-	err := r.repositoryBesu.SetValue(value, privateKey)
+func (r *SmartContractService) GetValues(ctx context.Context, addresses []common.Address, block rpc.BlockNumber) (values []smartContractDomain.SmartContractValue, err error) {
+	ctx, span := tracingConfig.Start(ctx, "SmartContractService.GetValues",
+		attribute.Int("contracts", len(addresses)), attribute.String("block", block.String()))
+	defer func() { tracingConfig.End(span, err) }()
+
+	values, err = r.reader.GetValues(ctx, addresses, block)
 	if err != nil {
-		slog.Error("Erro setting value in SmartContractRepositoryBesu.SetValue", "value", value)
-		return err
+		slog.ErrorContext(ctx, "Erro getting values from ValueReader.GetValues", "block", block.String())
+		return nil, err
 	}
-	// automatically updates the singleton of the repositoryDB
-	if value.Cmp(new(big.Int).SetUint64(r.repositoryDB.SmartContract.Value)) != 0 {
-		r.repositoryDB.SmartContract.Value = value.Uint64()
+	return values, nil
+}
+
+// SetValue sends a transaction setting the value of the contract, and waits for it
+// to be mined.
+// Returns:
+//   - The hash of the transaction, once it is submitted.
+//   - domain.ErrTransactionPending, with the hash, if the request is done (e.g. by its
+//     timeout) before the transaction is mined: it is still waited for, in background,
+//     or if the shutdown cancels the wait: it is left pending, to Track.
+//   - Another error if the transaction isn't sent, or is reverted or dropped.
+func (r *SmartContractService) SetValue(ctx context.Context, value *big.Int, privateKey string) (hash string, err error) {
+	ctx, span := tracingConfig.Start(ctx, "SmartContractService.SetValue", attribute.String("value", value.String()))
+	defer func() { tracingConfig.End(span, err) }()
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) { event.Value = value.String() })
+
+	if err := validateValue(value); err != nil {
+		return "", err
 	}
-	return nil
+	// the signer must be allowlisted (when the nodes enforce account permissioning),
+	// otherwise the node would reject the transaction with a generic error
+	signer, err := smartContractDomain.SignerAddress(privateKey)
+	if err != nil {
+		return "", err
+	}
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) { event.Signer = signer.Hex() })
+	allowlisted, err := r.allowlist.IsAccountAllowlisted(signer)
+	if err != nil {
+		slog.ErrorContext(ctx, "Erro checking signer in AccountAllowlist.IsAccountAllowlisted", "signer", signer.Hex())
+		return "", err
+	}
+	if !allowlisted {
+		slog.WarnContext(ctx, "Signer is not in the accounts allowlist", "signer", signer.Hex())
+		return "", domain.ErrSignerNotAllowlisted
+	}
+
+	sentAt := time.Now()
+	tx, err := r.writer.SendValue(ctx, value, privateKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "Erro sending value in ValueWriter.SendValue", "value", value)
+		return "", err
+	}
+	metricsConfig.Transactions.WithLabelValues("submitted").Inc()
+	span.SetAttributes(attribute.String("tx.hash", tx.Hash), attribute.String("tx.signer", signer.Hex()))
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) { event.Contract, event.TxHash = tx.Contract, tx.Hash })
+	// the transaction is persisted before waiting, so that a wait interrupted by a
+	// shutdown is resumed by Track on the next start
+	if err := r.transactions.SaveTransaction(ctx, *tx); err != nil {
+		slog.ErrorContext(ctx, "Erro saving transaction in TransactionStore.SaveTransaction", "hash", tx.Hash)
+	}
+	// the wait outlives the request, until the shutdown: when the request is done
+	// first (e.g. by its timeout), the transaction is still followed until it's mined
+	r.begin(tx.Hash)
+	waited := make(chan waitResult, 1)
+	go func() {
+		state, err := r.wait(context.WithoutCancel(ctx), tx.Hash, sentAt)
+		waited <- waitResult{state, err}
+	}()
+	var result waitResult
+	select {
+	case result = <-waited:
+	case <-ctx.Done():
+		slog.WarnContext(ctx, "Request done before the transaction was mined, still waiting for it", "hash", tx.Hash)
+		result.err = ctx.Err()
+	}
+	if result.err != nil {
+		// the transaction is sent all the same, so a retry must not send another one
+		auditDomain.Annotate(ctx, func(event *auditDomain.Event) {
+			event.Detail("state", string(smartContractDomain.TransactionPending))
+		})
+		return tx.Hash, domain.ErrTransactionPending
+	}
+	state := result.state
+	span.SetAttributes(attribute.String("tx.state", string(state)))
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) { event.Detail("state", string(state)) })
+	switch state {
+	case smartContractDomain.TransactionReverted:
+		return tx.Hash, domain.ErrTransactionReverted
+	case smartContractDomain.TransactionDropped:
+		return tx.Hash, domain.ErrTransactionDropped
+	}
+	return tx.Hash, nil
+}
+
+// waitResult is the outcome of wait, for the request that sent the transaction.
+type waitResult struct {
+	state smartContractDomain.TransactionState
+	err   error
 }
 
-func (r *SmartContractService) CheckValue(value *big.Int) (bool, error) {
-	// for multiple requests, a cache system could be implemented
-	isEqual, err := r.repositoryBesu.CheckValue(value)
+// begin counts a transaction as waited for, before its wait starts so that Drain
+// doesn't miss it.
+// Returns:
+//   - false if it is waited for already.
+func (r *SmartContractService) begin(hash string) bool {
+	if _, loaded := r.tracking.LoadOrStore(hash, struct{}{}); loaded {
+		return false
+	}
+	r.waiting.Add(1)
+	return true
+}
+
+// wait waits for a transaction counted by begin to be mined, or dropped, and records
+// its state. When the wait fails (e.g. canceled by the shutdown), the transaction
+// stays pending.
+// Parameters:
+//   - ctx: The context holding the span the wait is traced under.
+//   - hash: The hash of the transaction.
+//   - sentAt: When the transaction was submitted, for its time to mine.
+func (r *SmartContractService) wait(ctx context.Context, hash string, sentAt time.Time) (smartContractDomain.TransactionState, error) {
+	defer func() {
+		r.tracking.Delete(hash)
+		r.waiting.Add(-1)
+	}()
+
+	state, block, err := r.writer.WaitTransaction(ctx, hash)
 	if err != nil {
-		slog.Error("Erro checking value in SmartContractRepositoryBesu.SetValue", "value", value)
-		return false, nil
+		slog.ErrorContext(ctx, "Erro waiting transaction in ValueWriter.WaitTransaction, left pending", "hash", hash)
+		return state, err
 	}
-	return isEqual, nil
+	metricsConfig.Transactions.WithLabelValues(string(state)).Inc()
+	if state != smartContractDomain.TransactionDropped {
+		metricsConfig.TransactionTimeToMine.Observe(time.Since(sentAt).Seconds())
+	}
+	if err = r.transactions.UpdateTransactionState(ctx, hash, state, block); err != nil {
+		slog.ErrorContext(ctx, "Erro updating transaction in TransactionStore.UpdateTransactionState", "hash", hash, "state", state)
+	}
+	return state, nil
 }
 
-func (r *SmartContractService) SyncValue() error {
-	// if the repositoryDB singleton has never been updated
-	if r.repositoryDB.SmartContract.UpdatedAt.IsZero() {
-		value, err := r.repositoryBesu.GetValue()
-		if err != nil {
-			slog.Error("Erro getting value from SmartContractRepositoryBesu.GetValue")
-			return err
+// Track resumes the wait of the transactions left pending, by a previous shutdown or
+// a failed wait, each in its own goroutine and trace. It is called on start.
+// Returns:
+//   - The number of transactions resumed.
+//   - An error if the pending transactions can't be listed.
+func (r *SmartContractService) Track() (int, error) {
+	txs, err := r.transactions.ListPendingTransactions(context.Background())
+	if err != nil {
+		slog.Error("Erro listing transactions from TransactionStore.ListPendingTransactions")
+		return 0, err
+	}
+	resumed := 0
+	for _, tx := range txs {
+		if !r.begin(tx.Hash) {
+			continue
+		}
+		resumed++
+		go func() {
+			ctx, span := tracingConfig.Start(context.Background(), "SmartContractService.Track", attribute.String("tx.hash", tx.Hash))
+			state, err := r.wait(ctx, tx.Hash, tx.CreatedAt)
+			tracingConfig.End(span, err)
+			if err == nil {
+				slog.InfoContext(ctx, "Pending transaction settled", "hash", tx.Hash, "state", state)
+			}
+		}()
+	}
+	return resumed, nil
+}
+
+// Drain waits for the transactions being waited for, until there are none or the
+// context is done. Those left stay pending in the TransactionStore, for Track.
+// Returns:
+//   - The number of transactions still being waited for.
+func (r *SmartContractService) Drain(ctx context.Context) int {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if waiting := r.waiting.Load(); waiting == 0 {
+			return 0
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return int(r.waiting.Load())
 		}
-		r.repositoryDB.SmartContract.Value = value.Uint64()
 	}
-	err := r.repositoryDB.SyncValue()
+}
+
+func (r *SmartContractService) CheckValue(ctx context.Context, value *big.Int) (isEqual bool, err error) {
+	ctx, span := tracingConfig.Start(ctx, "SmartContractService.CheckValue", attribute.String("value", value.String()))
+	defer func() { tracingConfig.End(span, err) }()
+
+	if err = validateValue(value); err != nil {
+		return false, err
+	}
+	isEqual, err = r.reader.CheckValue(ctx, value)
 	if err != nil {
-		slog.Error("Erro synchronizing value in SmartContractRepositoryDB.SyncValue")
-		return err
+		slog.ErrorContext(ctx, "Erro checking value in ValueReader.CheckValue", "value", value)
+		return false, err
 	}
-	return nil
+	return isEqual, nil
+}
+
+// SyncValue stores the value of the contract at the latest block in the database.
+// The result tells whether the database changed, it doesn't when it already held
+// the value of that block or of a newer one.
+func (r *SmartContractService) SyncValue(ctx context.Context) (result *smartContractDomain.SyncResult, err error) {
+	ctx, span := tracingConfig.Start(ctx, "SmartContractService.SyncValue")
+	defer func() { tracingConfig.End(span, err) }()
+
+	value, err := r.reader.GetLatestValue(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Erro getting value from ValueReader.GetLatestValue")
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int64("block.number", int64(value.BlockNumber)))
+	result, err = r.store.SyncValue(ctx, *value)
+	if err != nil {
+		slog.ErrorContext(ctx, "Erro synchronizing value in ValueStore.SyncValue", "block", value.BlockNumber)
+		return nil, err
+	}
+	auditDomain.Annotate(ctx, func(event *auditDomain.Event) {
+		event.Contract, event.Value = result.Address, result.Value.String()
+		event.Detail("block", strconv.FormatUint(result.BlockNumber, 10))
+		event.Detail("changed", strconv.FormatBool(result.Changed))
+	})
+	return result, nil
 }
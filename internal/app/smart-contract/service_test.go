@@ -0,0 +1,514 @@
+package smartContractApp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/configs/metrics"
+	"goledger-challenge-besu/configs/tracing"
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/audit"
+	"goledger-challenge-besu/internal/domain/network/fake"
+	"goledger-challenge-besu/internal/domain/smart-contract"
+	"goledger-challenge-besu/internal/domain/smart-contract/fake"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// genesis accounts of scripts/besu/config/qbftConfigFile.json
+const (
+	aliceKey = "8f2a55949038a9610f50fb23b5883af3b4ecb3c3bb792cbcefbd1542c692be63"
+	bobKey   = "ae6ae8e5ccbfb04590405997ee2d52d2b330726137b875053c36d94e974d162f"
+)
+
+var (
+	alice = common.HexToAddress("0xfe3b557e8fb62b89f4916b721be55ceb828dbd73")
+	bob   = common.HexToAddress("0xf17f52151EbEF6C7334FAD080c5704D77216b732")
+
+	maxUint64        = new(big.Int).SetUint64(^uint64(0))
+	maxUint64PlusOne = new(big.Int).Add(maxUint64, big.NewInt(1))
+	maxUint256       = math.MaxBig256
+	maxUint256Plus1  = new(big.Int).Add(math.MaxBig256, big.NewInt(1))
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+type fixture struct {
+	contract  *smartContractFake.Contract
+	store     *smartContractFake.Store
+	allowlist *networkFake.Allowlist
+	service   *SmartContractService
+}
+
+func newFixture(value *big.Int) *fixture {
+	f := &fixture{
+		contract:  smartContractFake.NewContract(value, nil),
+		store:     smartContractFake.NewStore(),
+		allowlist: &networkFake.Allowlist{},
+	}
+	f.service = NewService(f.store, f.store, f.contract, f.contract, f.allowlist)
+	return f
+}
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      *big.Int
+		privateKey string
+		setup      func(f *fixture)
+		wantErr    error
+	}{
+		{name: "zero", value: big.NewInt(0), privateKey: aliceKey},
+		{name: "max uint64", value: maxUint64, privateKey: aliceKey},
+		{name: "max uint64 plus one is not truncated", value: maxUint64PlusOne, privateKey: aliceKey},
+		{name: "max uint256", value: maxUint256, privateKey: aliceKey},
+		{name: "above uint256", value: maxUint256Plus1, privateKey: aliceKey, wantErr: domain.ErrInvalidValue},
+		{name: "negative", value: big.NewInt(-1), privateKey: aliceKey, wantErr: domain.ErrInvalidValue},
+		{name: "invalid private key", value: big.NewInt(1), privateKey: "not-a-key", wantErr: domain.ErrUnauthorized},
+		{
+			name: "allowlisted signer", value: big.NewInt(1), privateKey: bobKey,
+			setup: func(f *fixture) { f.allowlist.Accounts = []common.Address{bob} },
+		},
+		{
+			name: "signer not allowlisted", value: big.NewInt(1), privateKey: aliceKey,
+			setup:   func(f *fixture) { f.allowlist.Accounts = []common.Address{bob} },
+			wantErr: domain.ErrSignerNotAllowlisted,
+		},
+		{
+			name: "allowlist error", value: big.NewInt(1), privateKey: aliceKey,
+			setup:   func(f *fixture) { f.allowlist.Err = domain.ErrNodeRPC },
+			wantErr: domain.ErrNodeRPC,
+		},
+		{
+			name: "no node available", value: big.NewInt(1), privateKey: aliceKey,
+			setup:   func(f *fixture) { f.contract.SetErr = domain.ErrNodeUnavailable },
+			wantErr: domain.ErrNodeUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(big.NewInt(7))
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+
+			// warms the cache up, SetValue must invalidate it
+			if _, err := f.service.GetValue(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := f.service.SetValue(context.Background(), tt.value, tt.privateKey)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetValue() error = %v, want %v", err, tt.wantErr)
+			}
+			want := tt.value
+			if tt.wantErr != nil {
+				if len(f.contract.SetValues) != 0 {
+					t.Errorf("contract received %v, want no transaction", f.contract.SetValues)
+				}
+				want = big.NewInt(7)
+			} else if len(f.contract.SetValues) != 1 || f.contract.SetValues[0].Cmp(tt.value) != 0 {
+				t.Errorf("contract received %v, want [%v]", f.contract.SetValues, tt.value)
+			}
+			if value, err := f.service.GetValue(context.Background()); err != nil || value.Cmp(want) != 0 {
+				t.Errorf("GetValue() after SetValue = %v, %v, want %v", value, err, want)
+			}
+		})
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   *big.Int
+		getErr  error
+		wantErr error
+	}{
+		{name: "max uint64 plus one", value: maxUint64PlusOne},
+		{name: "max uint256", value: maxUint256},
+		{name: "reader error", value: big.NewInt(5), getErr: domain.ErrBoundContractCall, wantErr: domain.ErrBoundContractCall},
+		{name: "no node available", value: big.NewInt(5), getErr: domain.ErrNodeUnavailable, wantErr: domain.ErrNodeUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(tt.value)
+			f.contract.GetErr = tt.getErr
+
+			value, err := f.service.GetValue(context.Background())
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && value.Cmp(tt.value) != 0 {
+				t.Errorf("GetValue() = %v, want %v", value, tt.value)
+			}
+		})
+	}
+}
+
+// TestServiceConcurrency runs the service methods from concurrent goroutines, as
+// the gin handlers do. Run with -race to check the shared state.
+func TestServiceConcurrency(t *testing.T) {
+	f := newFixture(big.NewInt(0))
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			if _, err := f.service.SetValue(context.Background(), big.NewInt(int64(i)), aliceKey); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := f.service.GetValue(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := f.service.CheckValue(context.Background(), big.NewInt(int64(i))); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := f.service.SyncValue(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// once the writes are done, the reads see the last one
+	last := f.contract.SetValues[len(f.contract.SetValues)-1]
+	if value, err := f.service.GetValue(context.Background()); err != nil || value.Cmp(last) != 0 {
+		t.Errorf("GetValue() = %v, %v, want the last value set %v", value, err, last)
+	}
+	if result, err := f.service.SyncValue(context.Background()); err != nil || result.Value.Cmp(last) != 0 || result.BlockNumber != 50 {
+		t.Errorf("SyncValue() = %+v, %v, want %v at block 50", result, err, last)
+	}
+}
+
+func TestSetValueTransactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(f *fixture)
+		wantErr   error
+		wantState smartContractDomain.TransactionState
+	}{
+		{name: "mined", wantState: smartContractDomain.TransactionMined},
+		{
+			name: "reverted", setup: func(f *fixture) { f.contract.Revert = true },
+			wantErr: domain.ErrTransactionReverted, wantState: smartContractDomain.TransactionReverted,
+		},
+		{
+			name: "dropped", setup: func(f *fixture) { f.contract.Drop = true },
+			wantErr: domain.ErrTransactionDropped, wantState: smartContractDomain.TransactionDropped,
+		},
+		{
+			// left to Track
+			name: "wait canceled", setup: func(f *fixture) { f.contract.WaitErr = context.Canceled },
+			wantErr: domain.ErrTransactionPending, wantState: smartContractDomain.TransactionPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(big.NewInt(7))
+			if tt.setup != nil {
+				tt.setup(f)
+			}
+			// a canceled wait counts the submission only, its outcome is counted by Track
+			outcome := string(tt.wantState)
+			if tt.wantState == smartContractDomain.TransactionPending {
+				outcome = "submitted"
+			}
+			submitted := testutil.ToFloat64(metricsConfig.Transactions.WithLabelValues("submitted"))
+			settled := testutil.ToFloat64(metricsConfig.Transactions.WithLabelValues(outcome))
+
+			_, err := f.service.SetValue(context.Background(), big.NewInt(8), aliceKey)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := testutil.ToFloat64(metricsConfig.Transactions.WithLabelValues("submitted")) - submitted; got != 1 {
+				t.Errorf("submitted transactions counted = %v, want 1", got)
+			}
+			if got := testutil.ToFloat64(metricsConfig.Transactions.WithLabelValues(outcome)) - settled; got != 1 {
+				t.Errorf("%s transactions counted = %v, want 1", outcome, got)
+			}
+			txs := f.store.Transactions()
+			if len(txs) != 1 || txs[0].State != tt.wantState || txs[0].Method != "set(8)" {
+				t.Fatalf("stored transactions = %+v, want one %s set(8)", txs, tt.wantState)
+			}
+			if mined := tt.wantState == smartContractDomain.TransactionMined || tt.wantState == smartContractDomain.TransactionReverted; mined != (txs[0].BlockNumber != nil) {
+				t.Errorf("transaction block = %v, want one when mined", txs[0].BlockNumber)
+			}
+		})
+	}
+
+	t.Run("database unavailable", func(t *testing.T) {
+		// the transaction is on the chain already, its wait doesn't need the database
+		f := newFixture(big.NewInt(7))
+		f.store.TxErr = domain.ErrInternal
+		if _, err := f.service.SetValue(context.Background(), big.NewInt(8), aliceKey); err != nil {
+			t.Errorf("SetValue() error = %v, want nil", err)
+		}
+	})
+}
+
+func TestAuditAnnotations(t *testing.T) {
+	f := newFixture(big.NewInt(7))
+	event := &auditDomain.Event{}
+	ctx := auditDomain.NewContext(context.Background(), event)
+	if _, err := f.service.SetValue(ctx, big.NewInt(8), aliceKey); err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	txs := f.store.Transactions()
+	if event.Value != "8" || event.Signer != alice.Hex() || event.Contract != smartContractFake.Address.Hex() ||
+		len(txs) != 1 || event.TxHash != txs[0].Hash || event.Details["state"] != string(smartContractDomain.TransactionMined) {
+		t.Errorf("event of SetValue = %+v, want the value, signer, contract, transaction and state", event)
+	}
+
+	event = &auditDomain.Event{}
+	if _, err := f.service.SyncValue(auditDomain.NewContext(context.Background(), event)); err != nil {
+		t.Fatalf("SyncValue() error = %v", err)
+	}
+	if event.Value != "8" || event.Contract != smartContractFake.Address.Hex() || event.Details["block"] == "" || event.Details["changed"] != "true" {
+		t.Errorf("event of SyncValue = %+v, want the value, contract and block", event)
+	}
+}
+
+func TestDrainAndTrack(t *testing.T) {
+	f := newFixture(big.NewInt(7))
+	f.contract.Mining = make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := f.service.SetValue(context.Background(), big.NewInt(8), aliceKey)
+		done <- err
+	}()
+	for len(f.store.Transactions()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the deadline passes with the transaction still being mined
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if pending := f.service.Drain(ctx); pending != 1 {
+		t.Fatalf("Drain() = %d, want 1 pending", pending)
+	}
+	// Track doesn't wait again for a transaction already waited for
+	if resumed, err := f.service.Track(); err != nil || resumed != 0 {
+		t.Errorf("Track() = %d, %v, want 0", resumed, err)
+	}
+
+	close(f.contract.Mining)
+	if err := <-done; err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	if pending := f.service.Drain(context.Background()); pending != 0 {
+		t.Errorf("Drain() = %d once mined, want 0", pending)
+	}
+
+	// a transaction left pending by a canceled wait is resumed by Track
+	f.contract.WaitErr = context.Canceled
+	if hash, err := f.service.SetValue(context.Background(), big.NewInt(9), aliceKey); err != domain.ErrTransactionPending || hash == "" {
+		t.Fatalf("SetValue() = %q, %v, want the hash and %v", hash, err, domain.ErrTransactionPending)
+	}
+	f.contract.WaitErr = nil
+	if resumed, err := f.service.Track(); err != nil || resumed != 1 {
+		t.Fatalf("Track() = %d, %v, want 1", resumed, err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if pending := f.service.Drain(ctx); pending != 0 {
+		t.Fatalf("Drain() = %d, want 0", pending)
+	}
+	if pending, _ := f.store.ListPendingTransactions(context.Background()); len(pending) != 0 {
+		t.Errorf("pending transactions = %+v after Track, want none", pending)
+	}
+}
+
+func TestSetValueRequestDone(t *testing.T) {
+	f := newFixture(big.NewInt(7))
+	f.contract.Mining = make(chan struct{})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	hash, err := f.service.SetValue(ctx, big.NewInt(8), aliceKey)
+	if err != domain.ErrTransactionPending || hash == "" {
+		t.Fatalf("SetValue() = %q, %v, want the hash and %v", hash, err, domain.ErrTransactionPending)
+	}
+
+	// the transaction is still waited for once the request is done, until it's mined
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer drainCancel()
+	if pending := f.service.Drain(drainCtx); pending != 1 {
+		t.Fatalf("Drain() = %d, want 1 still waited for", pending)
+	}
+	close(f.contract.Mining)
+	if pending := f.service.Drain(context.Background()); pending != 0 {
+		t.Fatalf("Drain() = %d once mined, want 0", pending)
+	}
+	if txs := f.store.Transactions(); len(txs) != 1 || txs[0].Hash != hash || txs[0].State != smartContractDomain.TransactionMined {
+		t.Errorf("transactions = %+v, want %s mined", txs, hash)
+	}
+}
+
+func TestCheckValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   *big.Int
+		value    *big.Int
+		checkErr error
+		want     bool
+		wantErr  error
+	}{
+		{name: "equal", stored: big.NewInt(42), value: big.NewInt(42), want: true},
+		{name: "different", stored: big.NewInt(42), value: big.NewInt(43)},
+		{name: "max uint64", stored: maxUint64, value: maxUint64, want: true},
+		{name: "above uint64 doesn't wrap to zero", stored: big.NewInt(0), value: maxUint64PlusOne},
+		{name: "above uint64", stored: maxUint64PlusOne, value: maxUint64PlusOne, want: true},
+		{name: "above uint256", stored: big.NewInt(0), value: maxUint256Plus1, wantErr: domain.ErrInvalidValue},
+		{name: "negative", stored: big.NewInt(0), value: big.NewInt(-1), wantErr: domain.ErrInvalidValue},
+		{name: "reader error", stored: big.NewInt(0), value: big.NewInt(0), checkErr: domain.ErrBoundContractCall, wantErr: domain.ErrBoundContractCall},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(tt.stored)
+			f.contract.CheckErr = tt.checkErr
+
+			got, err := f.service.CheckValue(context.Background(), tt.value)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CheckValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		chain       *big.Int
+		setValue    *big.Int // value set through the service before the sync, nil for none
+		syncedTwice bool     // sync again without a new block
+		getErr      error
+		syncErr     error
+		wantErr     error
+		want        *big.Int
+		wantChanged bool
+	}{
+		{name: "first sync reads the chain", chain: maxUint64PlusOne, want: maxUint64PlusOne, wantChanged: true},
+		{name: "syncs the value set", chain: big.NewInt(1), setValue: maxUint256, want: maxUint256, wantChanged: true},
+		{name: "same block doesn't change the store", chain: big.NewInt(1), syncedTwice: true, want: big.NewInt(1)},
+		{name: "reader error", chain: big.NewInt(1), getErr: domain.ErrNodeUnavailable, wantErr: domain.ErrNodeUnavailable},
+		{name: "conflicting data", chain: big.NewInt(1), syncErr: domain.ErrConflictingData, wantErr: domain.ErrConflictingData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(tt.chain)
+			if tt.setValue != nil {
+				if _, err := f.service.SetValue(context.Background(), tt.setValue, aliceKey); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.syncedTwice {
+				if _, err := f.service.SyncValue(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f.contract.GetErr = tt.getErr
+			f.store.SyncErr = tt.syncErr
+
+			result, err := f.service.SyncValue(context.Background())
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SyncValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(f.store.Synced) != 0 {
+					t.Errorf("store synced %v, want nothing", f.store.Synced)
+				}
+				return
+			}
+			if len(f.store.Synced) != 1 || f.store.Synced[0].Cmp(tt.want) != 0 {
+				t.Errorf("store synced %v, want [%v]", f.store.Synced, tt.want)
+			}
+			if result.Value.Cmp(tt.want) != 0 || result.Changed != tt.wantChanged {
+				t.Errorf("SyncValue() = %v changed %v, want %v changed %v", result.Value, result.Changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestGetValues(t *testing.T) {
+	first := common.HexToAddress("0x42699A7612A82f1d9C36148af9C77354759b210b")
+	missing := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	contract := smartContractFake.NewContract(big.NewInt(0), map[common.Address]*big.Int{first: maxUint64PlusOne})
+	service := NewService(smartContractFake.NewStore(), smartContractFake.NewStore(), contract, contract, &networkFake.Allowlist{})
+
+	values, err := service.GetValues(context.Background(), []common.Address{first, missing}, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("GetValues() error = %v", err)
+	}
+	if len(values) != 2 || values[0].Value.Cmp(maxUint64PlusOne) != 0 || values[1].Error == "" {
+		t.Errorf("GetValues() = %+v", values)
+	}
+
+	contract.GetsErr = domain.ErrNodeUnavailable
+	if _, err = service.GetValues(context.Background(), []common.Address{first}, rpc.LatestBlockNumber); err != domain.ErrNodeUnavailable {
+		t.Errorf("GetValues() error = %v, want %v", err, domain.ErrNodeUnavailable)
+	}
+}
+
+func TestSetValueTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracingConfig.Install(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	f := newFixture(big.NewInt(7))
+
+	if _, err := f.service.SetValue(context.Background(), big.NewInt(8), aliceKey); err != nil {
+		t.Fatal(err)
+	}
+	f.contract.SetErr = domain.ErrNodeUnavailable
+	if _, err := f.service.SetValue(context.Background(), big.NewInt(9), aliceKey); !errors.Is(err, domain.ErrNodeUnavailable) {
+		t.Fatalf("SetValue() error = %v, want %v", err, domain.ErrNodeUnavailable)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("%d spans exported, want 2", len(spans))
+	}
+	attrs := map[string]string{}
+	for _, attr := range spans[0].Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if spans[0].Name != "SmartContractService.SetValue" || spans[0].Status.Code != codes.Unset {
+		t.Errorf("span = %s %v, want SmartContractService.SetValue without error", spans[0].Name, spans[0].Status.Code)
+	}
+	if attrs["value"] != "8" || attrs["tx.hash"] == "" || attrs["tx.signer"] != alice.Hex() || attrs["tx.state"] != string(smartContractDomain.TransactionMined) {
+		t.Errorf("attributes = %v, want the value, the hash, the signer and the state of the transaction", attrs)
+	}
+	if spans[1].Status.Code != codes.Error || spans[1].Status.Description != domain.ErrNodeUnavailable.Error() {
+		t.Errorf("failed span status = %v, want the error", spans[1].Status)
+	}
+}
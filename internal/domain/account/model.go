@@ -0,0 +1,46 @@
+package accountDomain
+
+import (
+	"math/big"
+	"time"
+)
+
+type Account struct {
+	Address      string   `json:"address"`
+	Balance      *big.Int `json:"balance"`
+	Nonce        uint64   `json:"nonce"`
+	PendingNonce uint64   `json:"pendingNonce"`
+	HasCode      bool     `json:"hasCode"`
+}
+
+// Signer is a configured signer account with its balance compared to the alert threshold.
+type Signer struct {
+	Account
+	MinBalance *big.Int `json:"minBalance"`
+	LowBalance bool     `json:"lowBalance"`
+}
+
+// SignerTransaction is a mined transaction sent by a configured signer.
+type SignerTransaction struct {
+	Hash        string    `json:"hash"`
+	BlockNumber uint64    `json:"blockNumber"`
+	BlockHash   string    `json:"blockHash"`
+	Signer      string    `json:"signer"`
+	To          *string   `json:"to"`
+	Nonce       uint64    `json:"nonce"`
+	Value       *big.Int  `json:"value"`
+	Method      *string   `json:"method"`
+	Status      uint64    `json:"status"`
+	GasUsed     uint64    `json:"gasUsed"`
+	MinedAt     time.Time `json:"minedAt"`
+}
+
+// LowBalanceAlert is the payload posted to SIGNER_ALERT_WEBHOOK_URL when a signer
+// balance crosses the threshold, in either direction.
+type LowBalanceAlert struct {
+	Signer     string    `json:"signer"`
+	Balance    *big.Int  `json:"balance"`
+	MinBalance *big.Int  `json:"minBalance"`
+	LowBalance bool      `json:"lowBalance"`
+	At         time.Time `json:"at"`
+}
@@ -0,0 +1,139 @@
+package accountDomain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+
+	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type AccountRepositoryBesu struct {
+	ctx    *context.Context
+	client *besuConfig.EthClient
+	// Signers are the accounts whose transactions and balance are monitored (SIGNER_ADDRESSES).
+	Signers []common.Address
+	// MinBalance is the balance, in wei, under which a signer is reported (SIGNER_MIN_BALANCE).
+	MinBalance *big.Int
+}
+
+// NewRepositoryBesu initializes a new instance of AccountRepositoryBesu.
+// Parameters:
+//   - ctx: The context for node operations.
+//   - client: The Ethereum client configuration.
+//
+// Returns:
+//   - A pointer to AccountRepositoryBesu if successful.
+//   - An error if one of the signer addresses or the minimum balance is invalid.
+func NewRepositoryBesu(ctx *context.Context, client *besuConfig.EthClient) (*AccountRepositoryBesu, error) {
+	var signers []common.Address
+	for _, signer := range strings.Split(os.Getenv("SIGNER_ADDRESSES"), ",") {
+		if signer = strings.TrimSpace(signer); signer == "" {
+			continue
+		}
+		if !common.IsHexAddress(signer) {
+			slog.Error("Error reading signer addresses", "address", signer, "error", "Signer address in invalid format")
+			return nil, fmt.Errorf("Invalid signer address %q", signer)
+		}
+		signers = append(signers, common.HexToAddress(signer))
+	}
+
+	minBalance := big.NewInt(params.Ether) // 1 ETH
+	if value := os.Getenv("SIGNER_MIN_BALANCE"); value != "" {
+		var ok bool
+		minBalance, ok = new(big.Int).SetString(value, 10)
+		if !ok || minBalance.Sign() < 0 {
+			slog.Error("Error reading signer minimum balance", "value", value, "error", "Minimum balance must be a positive amount of wei")
+			return nil, errors.New("Invalid signer minimum balance")
+		}
+	}
+
+	return &AccountRepositoryBesu{
+		ctx:        ctx,
+		client:     client,
+		Signers:    signers,
+		MinBalance: minBalance,
+	}, nil
+}
+
+// nodeError maps the errors of the node pool to domain errors.
+func nodeError(err error) error {
+	if errors.Is(err, besuConfig.ErrNoHealthyNode) {
+		return domain.ErrNodeUnavailable
+	}
+	return domain.ErrNodeRPC
+}
+
+// IsSigner reports whether the address is one of the configured signers.
+func (r *AccountRepositoryBesu) IsSigner(address common.Address) bool {
+	for _, signer := range r.Signers {
+		if signer == address {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAccount retrieves the balance, nonces and code presence of an account.
+// Parameters:
+//   - address: The account address.
+//   - block: The block number or tag of the balance, nonce and code.
+//
+// Returns:
+//   - The Account.
+//   - An error if one of the RPC calls fails.
+func (r *AccountRepositoryBesu) GetAccount(address common.Address, block rpc.BlockNumber) (Account, error) {
+	// negative numbers are the block tags (latest, pending...), understood by ethclient
+	blockNumber := big.NewInt(block.Int64())
+
+	balance, err := r.client.BalanceAt(*r.ctx, address, blockNumber)
+	if err != nil {
+		slog.Error("Error getting account balance", "address", address.Hex(), "error", err.Error())
+		return Account{}, nodeError(err)
+	}
+	nonce, err := r.client.NonceAt(*r.ctx, address, blockNumber)
+	if err != nil {
+		slog.Error("Error getting account nonce", "address", address.Hex(), "error", err.Error())
+		return Account{}, nodeError(err)
+	}
+	pendingNonce, err := r.client.PendingNonceAt(*r.ctx, address)
+	if err != nil {
+		slog.Error("Error getting account pending nonce", "address", address.Hex(), "error", err.Error())
+		return Account{}, nodeError(err)
+	}
+	code, err := r.client.CodeAt(*r.ctx, address, blockNumber)
+	if err != nil {
+		slog.Error("Error getting account code", "address", address.Hex(), "error", err.Error())
+		return Account{}, nodeError(err)
+	}
+
+	return Account{
+		Address:      address.Hex(),
+		Balance:      balance,
+		Nonce:        nonce,
+		PendingNonce: pendingNonce,
+		HasCode:      len(code) > 0,
+	}, nil
+}
+
+// GetBlockNumber retrieves the number of the latest block.
+// Returns:
+//   - The latest block number.
+//   - An error if the RPC call fails.
+func (r *AccountRepositoryBesu) GetBlockNumber() (uint64, error) {
+	blockNumber, err := r.client.BlockNumber(*r.ctx)
+	if err != nil {
+		slog.Error("Error getting latest block number", "error", err.Error())
+		return 0, nodeError(err)
+	}
+	return blockNumber, nil
+}
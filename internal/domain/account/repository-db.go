@@ -0,0 +1,182 @@
+package accountDomain
+
+import (
+	"context"
+	"log/slog"
+	"math/big"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+type AccountRepositoryDB struct {
+	ctx *context.Context
+	db  *dbConfig.DB
+}
+
+// NewRepositoryDB initializes a new instance of AccountRepositoryDB.
+// Parameters:
+//   - ctx: The context for database operations.
+//   - db: The database configuration to use.
+//
+// Returns:
+//   - A pointer to AccountRepositoryDB.
+//   - An error, reserved for future initialization failures.
+func NewRepositoryDB(ctx *context.Context, db *dbConfig.DB) (*AccountRepositoryDB, error) {
+	return &AccountRepositoryDB{
+		ctx: ctx,
+		db:  db,
+	}, nil
+}
+
+// GetLastScannedBlock retrieves the last block scanned for signer transactions.
+// Returns:
+//   - The last scanned block number.
+//   - domain.ErrDataNotFound if no block was scanned yet, or another error if the query fails.
+func (r *AccountRepositoryDB) GetLastScannedBlock() (uint64, error) {
+	sql, args, err := r.db.QueryBuilder.Select("last_block").From("signer_scan_state").ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to get signer scan state from db", "error", err.Error())
+		return 0, domain.ErrInvalidSQL
+	}
+	var lastBlock uint64
+	err = r.db.QueryRow(*r.ctx, sql, args...).Scan(&lastBlock)
+	if err == pgx.ErrNoRows {
+		return 0, domain.ErrDataNotFound
+	} else if err != nil {
+		slog.Error("Error getting signer scan state from db", "sql", sql, "error", err.Error())
+		return 0, domain.ErrInternal
+	}
+	return lastBlock, nil
+}
+
+// SaveScan stores the signer transactions found in a range of blocks and advances
+// the last scanned block, atomically, so a range is never half recorded.
+// Transactions already stored are ignored.
+// Parameters:
+//   - transactions: The signer transactions found in the range.
+//   - lastBlock: The last block of the range.
+//
+// Returns:
+//   - An error if the SQL generation or one of the statements fails.
+func (r *AccountRepositoryDB) SaveScan(transactions []SignerTransaction, lastBlock uint64) error {
+	tx, err := r.db.Begin(*r.ctx)
+	if err != nil {
+		slog.Error("Error starting db transaction to save signer scan", "error", err.Error())
+		return domain.ErrInternal
+	}
+	defer tx.Rollback(*r.ctx)
+
+	if len(transactions) > 0 {
+		query := r.db.QueryBuilder.Insert("signer_transactions").
+			Columns("hash", "block_number", "block_hash", "signer", "recipient", "nonce", "value", "method", "status", "gas_used", "mined_at").
+			Suffix("ON CONFLICT (hash) DO NOTHING")
+		for _, transaction := range transactions {
+			query = query.Values(
+				transaction.Hash,
+				transaction.BlockNumber,
+				transaction.BlockHash,
+				transaction.Signer,
+				transaction.To,
+				transaction.Nonce,
+				transaction.Value.String(),
+				transaction.Method,
+				transaction.Status,
+				transaction.GasUsed,
+				transaction.MinedAt,
+			)
+		}
+		sql, args, err := query.ToSql()
+		if err != nil {
+			slog.Error("Error generating query sql to insert signer transactions on db", "error", err.Error())
+			return domain.ErrInvalidSQL
+		}
+		if _, err = tx.Exec(*r.ctx, sql, args...); err != nil {
+			slog.Error("Error inserting signer transactions on db", "sql", sql, "error", err.Error())
+			return domain.ErrInternal
+		}
+	}
+
+	sql, args, err := r.db.QueryBuilder.Insert("signer_scan_state").
+		Columns("last_block").
+		Values(lastBlock).
+		Suffix("ON CONFLICT (signer_scan_state_id) DO UPDATE SET last_block = EXCLUDED.last_block, updated_at = CURRENT_TIMESTAMP").
+		ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to update signer scan state on db", "error", err.Error())
+		return domain.ErrInvalidSQL
+	}
+	if _, err = tx.Exec(*r.ctx, sql, args...); err != nil {
+		slog.Error("Error updating signer scan state on db", "sql", sql, "error", err.Error())
+		return domain.ErrInternal
+	}
+
+	if err = tx.Commit(*r.ctx); err != nil {
+		slog.Error("Error committing signer scan on db", "error", err.Error())
+		return domain.ErrInternal
+	}
+	return nil
+}
+
+// GetTransactions retrieves the stored transactions of a signer, newest first.
+// Parameters:
+//   - signer: The signer address (checksummed hex).
+//   - limit: The maximum number of transactions.
+//   - offset: The number of transactions to skip.
+//
+// Returns:
+//   - The SignerTransaction list (empty if there is none).
+//   - An error if the query fails.
+func (r *AccountRepositoryDB) GetTransactions(signer string, limit, offset uint64) ([]SignerTransaction, error) {
+	query := r.db.QueryBuilder.
+		Select("hash", "block_number", "block_hash", "signer", "recipient", "nonce", "CAST(value AS TEXT)", "method", "status", "gas_used", "mined_at").
+		From("signer_transactions").
+		Where(sq.Eq{"signer": signer}).
+		OrderBy("block_number DESC", "nonce DESC").
+		Limit(limit).
+		Offset(offset)
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to get signer transactions from db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
+	}
+	rows, err := r.db.Query(*r.ctx, sql, args...)
+	if err != nil {
+		slog.Error("Error getting signer transactions from db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	defer rows.Close()
+
+	transactions := []SignerTransaction{}
+	for rows.Next() {
+		var transaction SignerTransaction
+		var value string
+		err = rows.Scan(
+			&transaction.Hash,
+			&transaction.BlockNumber,
+			&transaction.BlockHash,
+			&transaction.Signer,
+			&transaction.To,
+			&transaction.Nonce,
+			&value,
+			&transaction.Method,
+			&transaction.Status,
+			&transaction.GasUsed,
+			&transaction.MinedAt,
+		)
+		if err != nil {
+			slog.Error("Error scanning signer transaction from db", "error", err.Error())
+			return nil, domain.ErrInternal
+		}
+		transaction.Value, _ = new(big.Int).SetString(value, 10)
+		transactions = append(transactions, transaction)
+	}
+	if err = rows.Err(); err != nil {
+		slog.Error("Error reading signer transactions from db", "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	return transactions, nil
+}
@@ -0,0 +1,62 @@
+// Package auditFake provides an in-memory implementation of the audit repository for unit tests.
+package auditFake
+
+import (
+	"sync"
+
+	"goledger-challenge-besu/internal/domain/audit"
+)
+
+// Store is an in-memory AuditStore, chaining the events as the database does.
+type Store struct {
+	mu sync.Mutex
+
+	Events []auditDomain.Event
+	Err    error
+}
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+func (s *Store) Append(event auditDomain.Event) (*auditDomain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	event.ID = uint64(len(s.Events)) + 1
+	event.PrevHash = ""
+	if len(s.Events) > 0 {
+		event.PrevHash = s.Events[len(s.Events)-1].Hash
+	}
+	event.Hash = event.ComputeHash()
+	s.Events = append(s.Events, event)
+	return &event, nil
+}
+
+func (s *Store) List(filter auditDomain.Filter) ([]auditDomain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	events := []auditDomain.Event{}
+	for _, event := range s.Events {
+		switch {
+		case event.ID <= filter.AfterID,
+			filter.Actor != "" && event.Actor != filter.Actor,
+			filter.Action != "" && event.Action != filter.Action,
+			filter.Contract != "" && event.Contract != filter.Contract,
+			filter.Signer != "" && event.Signer != filter.Signer,
+			filter.From != nil && event.OccurredAt.Before(*filter.From),
+			filter.To != nil && !event.OccurredAt.Before(*filter.To):
+			continue
+		}
+		events = append(events, event)
+		if filter.Limit > 0 && uint64(len(events)) == filter.Limit {
+			break
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,133 @@
+package auditDomain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// The actions audited, named after their route.
+const (
+	ActionSetValue             = "smart-contract.set-value"
+	ActionSync                 = "smart-contract.sync"
+	ActionProposeValidatorVote = "network.validator-vote.propose"
+	ActionDiscardValidatorVote = "network.validator-vote.discard"
+	ActionAddAccounts          = "network.permissioning.accounts.add"
+	ActionRemoveAccounts       = "network.permissioning.accounts.remove"
+	ActionAddNodes             = "network.permissioning.nodes.add"
+	ActionRemoveNodes          = "network.permissioning.nodes.remove"
+	ActionCreateAPIKey         = "auth.api-key.create"
+	ActionRevokeAPIKey         = "auth.api-key.revoke"
+)
+
+// Event is an audited operation: who (the actor, from which IP) did what (the action,
+// its outcome and, filled in by the services, the contract, value, signer and
+// transaction it touched) and when.
+// The events are chained: each one holds the hash of the previous one and its own,
+// computed over both, so changing, removing or inserting an event breaks the chain.
+type Event struct {
+	ID         uint64            `json:"id"`
+	OccurredAt time.Time         `json:"occurredAt"`
+	Action     string            `json:"action"`
+	Actor      string            `json:"actor"`      // the API key name or the token subject
+	AuthMethod string            `json:"authMethod"` // api_key, jwt or anonymous
+	Role       string            `json:"role"`
+	ClientIP   string            `json:"clientIp"`
+	Method     string            `json:"method"`
+	Route      string            `json:"route"`
+	StatusCode int               `json:"statusCode"`
+	Contract   string            `json:"contract,omitempty"`
+	Value      string            `json:"value,omitempty"`
+	Signer     string            `json:"signer,omitempty"`
+	TxHash     string            `json:"txHash,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	PrevHash   string            `json:"prevHash"`
+	Hash       string            `json:"hash"`
+}
+
+// ComputeHash returns the SHA-256 of the previous hash and of every field of the
+// event but its ID and Hash, in hex. OccurredAt is hashed in UTC, to the microsecond
+// the databases keep.
+func (e *Event) ComputeHash() string {
+	// the order of the fields is the one of the struct, and the keys of the details
+	// are sorted by encoding/json, so the encoding is stable
+	details := e.Details
+	if len(details) == 0 {
+		// no details hash the same whether they were loaded empty or left nil
+		details = nil
+	}
+	content, _ := json.Marshal(struct {
+		PrevHash   string            `json:"prevHash"`
+		OccurredAt string            `json:"occurredAt"`
+		Action     string            `json:"action"`
+		Actor      string            `json:"actor"`
+		AuthMethod string            `json:"authMethod"`
+		Role       string            `json:"role"`
+		ClientIP   string            `json:"clientIp"`
+		Method     string            `json:"method"`
+		Route      string            `json:"route"`
+		StatusCode int               `json:"statusCode"`
+		Contract   string            `json:"contract"`
+		Value      string            `json:"value"`
+		Signer     string            `json:"signer"`
+		TxHash     string            `json:"txHash"`
+		Details    map[string]string `json:"details"`
+	}{
+		e.PrevHash, e.OccurredAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		e.Action, e.Actor, e.AuthMethod, e.Role, e.ClientIP, e.Method, e.Route, e.StatusCode,
+		e.Contract, e.Value, e.Signer, e.TxHash, details,
+	})
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// Detail sets a detail of the action, e.g. the ID of the API key created.
+func (e *Event) Detail(key string, value string) {
+	if e.Details == nil {
+		e.Details = map[string]string{}
+	}
+	e.Details[key] = value
+}
+
+// Filter selects the events listed, in the order they were recorded. The empty
+// fields don't filter.
+type Filter struct {
+	Actor    string
+	Action   string
+	Contract string
+	Signer   string
+	From     *time.Time // inclusive
+	To       *time.Time // exclusive
+	AfterID  uint64     // the ID of the last event of the previous page
+	Limit    uint64
+}
+
+// Verification is the result of the verification of the chain of the events.
+type Verification struct {
+	Valid    bool    `json:"valid"`
+	Events   uint64  `json:"events"`             // the events verified
+	BrokenAt *uint64 `json:"brokenAt,omitempty"` // the ID of the first event not matching its hash or the previous one
+	Reason   string  `json:"reason,omitempty"`
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying the event of the audited request, for the services
+// to fill in with Annotate.
+func NewContext(ctx context.Context, event *Event) context.Context {
+	return context.WithValue(ctx, contextKey{}, event)
+}
+
+// Annotate records what the operation of a request did on its audit event (the
+// contract, value, signer, transaction...). It is a no-op when the request isn't
+// audited.
+// Parameters:
+//   - ctx: The context of the request, from NewContext.
+//   - annotate: The function filling in the event.
+func Annotate(ctx context.Context, annotate func(event *Event)) {
+	if event, ok := ctx.Value(contextKey{}).(*Event); ok && event != nil {
+		annotate(event)
+	}
+}
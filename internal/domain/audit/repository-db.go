@@ -0,0 +1,176 @@
+package auditDomain
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// chainLockKey is the key of the lock serializing the appends of every instance, so
+// each event is chained to the last one.
+const chainLockKey int64 = 0x61756469746c6f67 // "auditlog"
+
+// AuditRepositoryDB keeps the audit events in the audit_events table, which the
+// triggers of the migrations make append-only.
+type AuditRepositoryDB struct {
+	ctx *context.Context
+	db  *dbConfig.DB
+}
+
+// NewRepositoryDB initializes a new instance of AuditRepositoryDB.
+// Parameters:
+//   - ctx: The context for database operations.
+//   - db: The database configuration to use.
+//
+// Returns:
+//   - A pointer to AuditRepositoryDB.
+//   - An error, reserved for future initialization failures.
+func NewRepositoryDB(ctx *context.Context, db *dbConfig.DB) (*AuditRepositoryDB, error) {
+	return &AuditRepositoryDB{
+		ctx: ctx,
+		db:  db,
+	}, nil
+}
+
+var eventColumns = []string{
+	"audit_event_id", "occurred_at", "action", "actor", "auth_method", "role", "client_ip", "method", "route",
+	"status_code", "contract", "value", "signer", "tx_hash", "details", "prev_hash", "hash",
+}
+
+// Append chains an event to the last one and stores it. The last hash is read and
+// the event inserted in a transaction holding the lock of the chain, so the events
+// of other instances wait for it rather than being chained to the same last one.
+// Parameters:
+//   - event: The event, without ID nor hashes.
+//
+// Returns:
+//   - The stored event, with its ID and hashes.
+//   - An error if the SQL generation or one of the statements fails.
+func (r *AuditRepositoryDB) Append(event Event) (*Event, error) {
+	event.OccurredAt = event.OccurredAt.UTC().Truncate(time.Microsecond)
+	details := []byte("{}")
+	if len(event.Details) > 0 {
+		details, _ = json.Marshal(event.Details)
+	}
+
+	tx, err := r.db.Begin(*r.ctx)
+	if err != nil {
+		slog.Error("Error starting db transaction to append audit event", "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	defer tx.Rollback(*r.ctx)
+	if err = r.db.Backend.Lock(*r.ctx, tx, chainLockKey); err != nil {
+		slog.Error("Error locking audit events chain on db", "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+
+	sql, args, err := r.db.QueryBuilder.Select("hash").From("audit_events").
+		OrderBy("audit_event_id DESC").Limit(1).ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to get last audit event from db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
+	}
+	event.PrevHash = ""
+	err = tx.QueryRow(*r.ctx, sql, args...).Scan(&event.PrevHash)
+	if err != nil && err != pgx.ErrNoRows {
+		slog.Error("Error getting last audit event from db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	event.Hash = event.ComputeHash()
+
+	sql, args, err = r.db.QueryBuilder.Insert("audit_events").
+		Columns(eventColumns[1:]...).
+		Values(event.OccurredAt, event.Action, event.Actor, event.AuthMethod, event.Role, event.ClientIP, event.Method, event.Route,
+			event.StatusCode, event.Contract, event.Value, event.Signer, event.TxHash, string(details), event.PrevHash, event.Hash).
+		Suffix("RETURNING audit_event_id").
+		ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to insert audit event on db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
+	}
+	if err = tx.QueryRow(*r.ctx, sql, args...).Scan(&event.ID); err != nil {
+		slog.Error("Error inserting audit event on db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+
+	if err = tx.Commit(*r.ctx); err != nil {
+		slog.Error("Error committing audit event on db", "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	return &event, nil
+}
+
+// List lists the events matching a filter, in the order they were recorded.
+// Parameters:
+//   - filter: The actor, action, contract, signer and time range of the events, the
+//     ID after which they are listed and their maximum number (0 for all of them).
+//
+// Returns:
+//   - The events, empty when none matches.
+//   - An error if the query fails.
+func (r *AuditRepositoryDB) List(filter Filter) ([]Event, error) {
+	query := r.db.QueryBuilder.Select(eventColumns...).From("audit_events").
+		Where(sq.Gt{"audit_event_id": filter.AfterID}).
+		OrderBy("audit_event_id")
+	for column, value := range map[string]string{
+		"actor": filter.Actor, "action": filter.Action, "contract": filter.Contract, "signer": filter.Signer,
+	} {
+		if value != "" {
+			query = query.Where(sq.Eq{column: value})
+		}
+	}
+	// the times are truncated as the stored ones are, so an event is in the range it was in when appended
+	if filter.From != nil {
+		query = query.Where(sq.GtOrEq{"occurred_at": filter.From.UTC().Truncate(time.Microsecond)})
+	}
+	if filter.To != nil {
+		query = query.Where(sq.Lt{"occurred_at": filter.To.UTC().Truncate(time.Microsecond)})
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to list audit events from db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
+	}
+
+	rows, err := r.db.Query(*r.ctx, sql, args...)
+	if err != nil {
+		slog.Error("Error listing audit events from db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	defer rows.Close()
+	events := []Event{}
+	for rows.Next() {
+		var event Event
+		var details string
+		err := rows.Scan(&event.ID, &event.OccurredAt, &event.Action, &event.Actor, &event.AuthMethod, &event.Role,
+			&event.ClientIP, &event.Method, &event.Route, &event.StatusCode, &event.Contract, &event.Value,
+			&event.Signer, &event.TxHash, &details, &event.PrevHash, &event.Hash)
+		if err == nil {
+			err = json.Unmarshal([]byte(details), &event.Details)
+		}
+		if err != nil {
+			slog.Error("Error scanning audit event from db", "error", err.Error())
+			return nil, domain.ErrInternal
+		}
+		if len(event.Details) == 0 {
+			event.Details = nil
+		}
+		event.OccurredAt = event.OccurredAt.UTC()
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		slog.Error("Error listing audit events from db", "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	return events, nil
+}
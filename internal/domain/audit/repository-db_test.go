@@ -0,0 +1,177 @@
+package auditDomain
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/configs/db"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+func TestComputeHash(t *testing.T) {
+	event := Event{
+		OccurredAt: time.Date(2025, 1, 2, 15, 4, 5, 123456789, time.FixedZone("BRT", -3*3600)),
+		Action:     ActionSetValue, Actor: "billing", Role: "writer", StatusCode: 200,
+		Value: "42", Details: map[string]string{"state": "mined", "block": "7"},
+	}
+	hash := event.ComputeHash()
+
+	// the time zone, the nanoseconds the databases drop, the ID and empty details don't matter
+	same := event
+	same.OccurredAt = event.OccurredAt.UTC().Truncate(time.Microsecond)
+	same.ID, same.Hash = 9, "stored"
+	if same.ComputeHash() != hash {
+		t.Error("ComputeHash() differs for the same event loaded from the database")
+	}
+	empty, emptied := Event{Action: ActionSync}, Event{Action: ActionSync, Details: map[string]string{}}
+	if empty.ComputeHash() != emptied.ComputeHash() {
+		t.Error("ComputeHash() differs for nil and empty details")
+	}
+
+	for name, change := range map[string]func(e *Event){
+		"prevHash": func(e *Event) { e.PrevHash = "00" },
+		"time":     func(e *Event) { e.OccurredAt = e.OccurredAt.Add(time.Microsecond) },
+		"actor":    func(e *Event) { e.Actor = "other" },
+		"status":   func(e *Event) { e.StatusCode = 500 },
+		"value":    func(e *Event) { e.Value = "43" },
+		"details":  func(e *Event) { e.Details = map[string]string{"state": "reverted", "block": "7"} },
+	} {
+		changed := event
+		changed.Details = map[string]string{"state": "mined", "block": "7"}
+		change(&changed)
+		if changed.ComputeHash() == hash {
+			t.Errorf("ComputeHash() is the same for another %s", name)
+		}
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	// not audited: a no-op
+	Annotate(context.Background(), func(event *Event) { t.Error("annotated a request not audited") })
+
+	event := &Event{}
+	Annotate(NewContext(context.Background(), event), func(event *Event) {
+		event.Signer = "0x01"
+		event.Detail("state", "mined")
+	})
+	if event.Signer != "0x01" || event.Details["state"] != "mined" {
+		t.Errorf("event = %+v, want the annotations", event)
+	}
+}
+
+// TestRepositoryDB runs the repository on the embedded SQLite backend.
+func TestRepositoryDB(t *testing.T) {
+	t.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "app.db"))
+	ctx := context.Background()
+	db, err := dbConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err = db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	repository, _ := NewRepositoryDB(&ctx, db)
+
+	start := time.Now()
+	first, err := repository.Append(Event{
+		OccurredAt: start, Action: ActionSetValue, Actor: "billing", AuthMethod: "api_key", Role: "writer",
+		ClientIP: "10.0.0.1", Method: "POST", Route: "/api/v1/smart-contract/set-value", StatusCode: 200,
+		Contract: "0x42", Value: "7", Signer: "0xfe", TxHash: "0xab", Details: map[string]string{"state": "mined"},
+	})
+	if err != nil || first.ID != 1 || first.PrevHash != "" || first.Hash != first.ComputeHash() {
+		t.Fatalf("Append() = %+v, %v, want the first event of the chain", first, err)
+	}
+	second, err := repository.Append(Event{OccurredAt: start.Add(time.Second), Action: ActionSync, Actor: "ops", StatusCode: 409})
+	if err != nil || second.PrevHash != first.Hash {
+		t.Fatalf("Append() = %+v, %v, want it chained to the first event", second, err)
+	}
+
+	events, err := repository.List(Filter{})
+	if err != nil || len(events) != 2 {
+		t.Fatalf("List() = %v, %v, want 2 events", events, err)
+	}
+	for _, event := range events {
+		if event.ComputeHash() != event.Hash {
+			t.Errorf("event %d loaded = %+v, its hash doesn't match", event.ID, event)
+		}
+	}
+	if events[0].Details["state"] != "mined" || events[1].Details != nil || !events[0].OccurredAt.Equal(start.UTC().Truncate(time.Microsecond)) {
+		t.Errorf("List() = %+v, want the events as appended", events)
+	}
+
+	from, to := start.Add(time.Second), start.Add(time.Hour)
+	for name, tt := range map[string]struct {
+		filter Filter
+		want   []uint64
+	}{
+		"actor":    {Filter{Actor: "ops"}, []uint64{2}},
+		"action":   {Filter{Action: ActionSetValue}, []uint64{1}},
+		"contract": {Filter{Contract: "0x42", Signer: "0xfe"}, []uint64{1}},
+		"from":     {Filter{From: &from}, []uint64{2}},
+		"to":       {Filter{To: &from}, []uint64{1}},
+		"range":    {Filter{From: &start, To: &to}, []uint64{1, 2}},
+		"page":     {Filter{AfterID: 1, Limit: 1}, []uint64{2}},
+		"limit":    {Filter{Limit: 1}, []uint64{1}},
+		"none":     {Filter{Actor: "nobody"}, []uint64{}},
+	} {
+		events, err := repository.List(tt.filter)
+		ids := []uint64{}
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		if err != nil || len(ids) != len(tt.want) || len(ids) > 0 && ids[0] != tt.want[0] {
+			t.Errorf("List(%s) = %v, %v, want %v", name, ids, err, tt.want)
+		}
+	}
+
+	// the table is append-only
+	if _, err = db.Exec(ctx, "UPDATE audit_events SET actor = 'nobody'"); err == nil {
+		t.Error("update of audit_events succeeded, want it rejected")
+	}
+	if _, err = db.Exec(ctx, "DELETE FROM audit_events"); err == nil {
+		t.Error("delete from audit_events succeeded, want it rejected")
+	}
+
+	// two instances, each with its own connections, appending at the same time keep a single chain
+	otherDB, err := dbConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherDB.Close()
+	other, _ := NewRepositoryDB(&ctx, otherDB)
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instance := repository
+			if i%2 == 0 {
+				instance = other
+			}
+			if _, err := instance.Append(Event{OccurredAt: time.Now(), Action: ActionSync}); err != nil {
+				t.Errorf("concurrent Append() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	events, err = repository.List(Filter{})
+	if err != nil || len(events) != 52 {
+		t.Fatalf("List() = %d events, %v, want 52", len(events), err)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].PrevHash != events[i-1].Hash {
+			t.Errorf("event %d isn't chained to event %d", events[i].ID, events[i-1].ID)
+		}
+	}
+}
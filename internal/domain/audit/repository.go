@@ -0,0 +1,10 @@
+package auditDomain
+
+// AuditStore keeps the audit events, append-only: they are never updated nor deleted.
+// It is implemented by AuditRepositoryDB.
+type AuditStore interface {
+	Append(event Event) (*Event, error)
+	List(filter Filter) ([]Event, error)
+}
+
+var _ AuditStore = (*AuditRepositoryDB)(nil)
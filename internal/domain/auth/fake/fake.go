@@ -0,0 +1,91 @@
+// Package authFake provides in-memory implementations of the auth repositories for unit tests.
+package authFake
+
+import (
+	"sync"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/auth"
+)
+
+// KeyStore is an in-memory KeyStore.
+type KeyStore struct {
+	mu     sync.Mutex
+	keys   []authDomain.APIKey
+	hashes map[string]uint64 // key hash -> id
+
+	Err error
+}
+
+func NewKeyStore() *KeyStore {
+	return &KeyStore{hashes: map[string]uint64{}}
+}
+
+func (s *KeyStore) CreateAPIKey(name string, role authDomain.Role, prefix string, keyHash string) (*authDomain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	if _, ok := s.hashes[keyHash]; ok {
+		return nil, domain.ErrConflictingData
+	}
+	key := authDomain.APIKey{ID: uint64(len(s.keys) + 1), Name: name, Prefix: prefix, Role: role, CreatedAt: time.Now()}
+	s.keys = append(s.keys, key)
+	s.hashes[keyHash] = key.ID
+	return &key, nil
+}
+
+func (s *KeyStore) GetAPIKeyByHash(keyHash string) (*authDomain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	id, ok := s.hashes[keyHash]
+	if !ok || s.keys[id-1].RevokedAt != nil {
+		return nil, domain.ErrDataNotFound
+	}
+	key := s.keys[id-1]
+	return &key, nil
+}
+
+func (s *KeyStore) ListAPIKeys() ([]authDomain.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return append([]authDomain.APIKey{}, s.keys...), nil
+}
+
+func (s *KeyStore) RevokeAPIKey(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if id == 0 || id > uint64(len(s.keys)) || s.keys[id-1].RevokedAt != nil {
+		return domain.ErrDataNotFound
+	}
+	now := time.Now()
+	s.keys[id-1].RevokedAt = &now
+	return nil
+}
+
+// Tokens is a TokenVerifier accepting the tokens of its map.
+type Tokens map[string]authDomain.Principal
+
+func (t Tokens) VerifyToken(token string) (*authDomain.Principal, error) {
+	principal, ok := t[token]
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+	return &principal, nil
+}
+
+var (
+	_ authDomain.KeyStore      = (*KeyStore)(nil)
+	_ authDomain.TokenVerifier = Tokens(nil)
+)
@@ -0,0 +1,71 @@
+package authDomain
+
+import (
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+)
+
+// Role is the access level of a caller. Each role includes the lower ones:
+// a writer can read, an admin can read and write.
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleWriter Role = "writer"
+	RoleAdmin  Role = "admin"
+)
+
+var roleLevels = map[Role]int{RoleReader: 1, RoleWriter: 2, RoleAdmin: 3}
+
+// ParseRole validates a role name.
+// Returns:
+//   - The Role.
+//   - domain.ErrInvalidRole if it isn't reader, writer or admin.
+func ParseRole(role string) (Role, error) {
+	if _, ok := roleLevels[Role(role)]; !ok {
+		return "", domain.ErrInvalidRole
+	}
+	return Role(role), nil
+}
+
+// Allows tells whether the role grants the access of the required one.
+func (r Role) Allows(required Role) bool {
+	level, ok := roleLevels[r]
+	return ok && level >= roleLevels[required]
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string `json:"subject"` // the API key name or the token subject
+	Role    Role   `json:"role"`
+	Method  string `json:"method"`          // api_key, jwt or anonymous
+	KeyID   uint64 `json:"keyId,omitempty"` // the id of the API key
+}
+
+type APIKeyDB struct {
+	APIKeyId  uint64
+	Name      string
+	Prefix    string
+	KeyHash   string
+	Role      Role
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// APIKey is an API key as listed to the admins, without its hash.
+type APIKey struct {
+	ID        uint64     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Role      Role       `json:"role"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// NewAPIKey is a created API key. Key is only returned on creation, the database
+// keeps its hash.
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
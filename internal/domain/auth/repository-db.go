@@ -0,0 +1,180 @@
+package authDomain
+
+import (
+	"context"
+	"log/slog"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+type AuthRepositoryDB struct {
+	ctx *context.Context
+	db  *dbConfig.DB
+}
+
+// NewRepositoryDB initializes a new instance of AuthRepositoryDB.
+// Parameters:
+//   - ctx: The context for database operations.
+//   - db: The database configuration to use.
+//
+// Returns:
+//   - A pointer to AuthRepositoryDB.
+//   - An error, reserved for future initialization failures.
+func NewRepositoryDB(ctx *context.Context, db *dbConfig.DB) (*AuthRepositoryDB, error) {
+	return &AuthRepositoryDB{
+		ctx: ctx,
+		db:  db,
+	}, nil
+}
+
+// apiKeyColumns are the columns scanned by scanAPIKey.
+var apiKeyColumns = []string{"api_key_id", "name", "prefix", "key_hash", "role", "created_at", "revoked_at"}
+
+// CreateAPIKey stores a new API key.
+// Parameters:
+//   - name: The name of the key (its owner).
+//   - role: The Role granted by the key.
+//   - prefix: The first characters of the key.
+//   - keyHash: The hash of the key (HashAPIKey).
+//
+// Returns:
+//   - The stored APIKey.
+//   - domain.ErrConflictingData if the hash already exists, or another error if the insert fails.
+func (r *AuthRepositoryDB) CreateAPIKey(name string, role Role, prefix string, keyHash string) (*APIKey, error) {
+	query := r.db.QueryBuilder.Insert("api_keys").
+		Columns("name", "prefix", "key_hash", "role").
+		Values(name, prefix, keyHash, string(role)).
+		Suffix("RETURNING api_key_id, created_at")
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to insert api key on db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
+	}
+
+	key := APIKey{Name: name, Prefix: prefix, Role: role}
+	err = r.db.QueryRow(*r.ctx, sql, args...).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		if errCode := r.db.ErrorCode(err); errCode == "23505" {
+			slog.Error("Error inserting api key on db. Conflicts with columns requirements", "error", err.Error())
+			return nil, domain.ErrConflictingData
+		}
+		slog.Error("Error inserting api key on db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	return &key, nil
+}
+
+// GetAPIKeyByHash finds an API key that is not revoked by its hash.
+// Parameters:
+//   - keyHash: The hash of the key (HashAPIKey).
+//
+// Returns:
+//   - The APIKey.
+//   - domain.ErrDataNotFound if there is no such key or it is revoked, or another error if the query fails.
+func (r *AuthRepositoryDB) GetAPIKeyByHash(keyHash string) (*APIKey, error) {
+	query := r.db.QueryBuilder.Select(apiKeyColumns...).From("api_keys").
+		Where(sq.Eq{"key_hash": keyHash, "revoked_at": nil})
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to get api key from db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
+	}
+
+	key, err := scanAPIKey(r.db.QueryRow(*r.ctx, sql, args...))
+	if err == pgx.ErrNoRows {
+		return nil, domain.ErrDataNotFound
+	}
+	if err != nil {
+		slog.Error("Error getting api key from db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	return toAPIKey(key), nil
+}
+
+// ListAPIKeys returns every API key, revoked ones included, oldest first.
+// Returns:
+//   - The APIKey list.
+//   - An error if the query fails.
+func (r *AuthRepositoryDB) ListAPIKeys() ([]APIKey, error) {
+	sql, args, err := r.db.QueryBuilder.Select(apiKeyColumns...).From("api_keys").OrderBy("api_key_id").ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to list api keys from db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
+	}
+
+	rows, err := r.db.Query(*r.ctx, sql, args...)
+	if err != nil {
+		slog.Error("Error listing api keys from db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	defer rows.Close()
+	keys := []APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			slog.Error("Error scanning api key from db", "error", err.Error())
+			return nil, domain.ErrInternal
+		}
+		keys = append(keys, *toAPIKey(key))
+	}
+	if err = rows.Err(); err != nil {
+		slog.Error("Error listing api keys from db", "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key, which stops authenticating right away.
+// Parameters:
+//   - id: The ID of the key.
+//
+// Returns:
+//   - domain.ErrDataNotFound if there is no such key or it is already revoked, or another error if the update fails.
+func (r *AuthRepositoryDB) RevokeAPIKey(id uint64) error {
+	query := r.db.QueryBuilder.Update("api_keys").
+		Set("revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"api_key_id": id, "revoked_at": nil})
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to revoke api key on db", "error", err.Error())
+		return domain.ErrInvalidSQL
+	}
+
+	tag, err := r.db.Exec(*r.ctx, sql, args...)
+	if err != nil {
+		slog.Error("Error revoking api key on db", "sql", sql, "error", err.Error())
+		return domain.ErrInternal
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDataNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (*APIKeyDB, error) {
+	var key APIKeyDB
+	var role string
+	err := row.Scan(&key.APIKeyId, &key.Name, &key.Prefix, &key.KeyHash, &role, &key.CreatedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	key.Role = Role(role)
+	return &key, nil
+}
+
+func toAPIKey(key *APIKeyDB) *APIKey {
+	return &APIKey{
+		ID:        key.APIKeyId,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Role:      key.Role,
+		CreatedAt: key.CreatedAt,
+		RevokedAt: key.RevokedAt,
+	}
+}
+
+var _ KeyStore = (*AuthRepositoryDB)(nil)
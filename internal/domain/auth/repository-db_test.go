@@ -0,0 +1,82 @@
+package authDomain
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, keyHash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "glk_") || len(key) != 47 || !strings.HasPrefix(key, prefix) || len(prefix) != 12 {
+		t.Errorf("GenerateAPIKey() = %q with prefix %q", key, prefix)
+	}
+	if keyHash != HashAPIKey(key) || len(keyHash) != 64 || strings.Contains(keyHash, key) {
+		t.Errorf("GenerateAPIKey() hash = %q, want the SHA-256 of the key", keyHash)
+	}
+	if other, _, _, _ := GenerateAPIKey(); other == key {
+		t.Error("GenerateAPIKey() returned the same key twice")
+	}
+}
+
+// TestRepositoryDB runs the repository on the embedded SQLite backend.
+func TestRepositoryDB(t *testing.T) {
+	t.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "app.db"))
+	ctx := context.Background()
+	db, err := dbConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err = db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	repository, _ := NewRepositoryDB(&ctx, db)
+
+	_, prefix, keyHash, _ := GenerateAPIKey()
+	created, err := repository.CreateAPIKey("ci", RoleWriter, prefix, keyHash)
+	if err != nil || created.ID == 0 || created.CreatedAt.IsZero() {
+		t.Fatalf("CreateAPIKey() = %+v, %v", created, err)
+	}
+	if _, err = repository.CreateAPIKey("copy", RoleReader, prefix, keyHash); err != domain.ErrConflictingData {
+		t.Errorf("CreateAPIKey() of an existing hash error = %v, want %v", err, domain.ErrConflictingData)
+	}
+
+	key, err := repository.GetAPIKeyByHash(keyHash)
+	if err != nil || key.ID != created.ID || key.Name != "ci" || key.Role != RoleWriter || key.Prefix != prefix {
+		t.Fatalf("GetAPIKeyByHash() = %+v, %v, want the created key", key, err)
+	}
+	if _, err = repository.GetAPIKeyByHash(HashAPIKey("glk_unknown")); err != domain.ErrDataNotFound {
+		t.Errorf("GetAPIKeyByHash() of an unknown key error = %v, want %v", err, domain.ErrDataNotFound)
+	}
+
+	if err = repository.RevokeAPIKey(created.ID); err != nil {
+		t.Fatalf("RevokeAPIKey() error = %v", err)
+	}
+	if _, err = repository.GetAPIKeyByHash(keyHash); err != domain.ErrDataNotFound {
+		t.Errorf("GetAPIKeyByHash() of a revoked key error = %v, want %v", err, domain.ErrDataNotFound)
+	}
+	if err = repository.RevokeAPIKey(created.ID); err != domain.ErrDataNotFound {
+		t.Errorf("second RevokeAPIKey() error = %v, want %v", err, domain.ErrDataNotFound)
+	}
+
+	keys, err := repository.ListAPIKeys()
+	if err != nil || len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Errorf("ListAPIKeys() = %+v, %v, want the revoked key", keys, err)
+	}
+}
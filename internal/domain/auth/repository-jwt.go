@@ -0,0 +1,172 @@
+package authDomain
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minSecretLength is the minimum length of the HS256 secret (256 bits).
+const minSecretLength = 32
+
+// AuthRepositoryJWT verifies the JWT bearer tokens issued by an identity provider,
+// signed with a shared secret (HS256) and/or with the RSA keys of a JWKS file (RS256).
+// The tokens must carry an expiration, a subject and the role claim.
+type AuthRepositoryJWT struct {
+	secret    []byte
+	rsaKeys   map[string]*rsa.PublicKey // by kid
+	methods   []string
+	roleClaim string
+	options   []jwt.ParserOption
+}
+
+// NewRepositoryJWT initializes a new instance of AuthRepositoryJWT from
+// AUTH_JWT_SECRET (HS256), AUTH_JWT_JWKS_PATH (RS256), AUTH_JWT_ISSUER,
+// AUTH_JWT_AUDIENCE and AUTH_JWT_ROLE_CLAIM. Without a secret nor a JWKS file, the
+// tokens are disabled and every token is rejected.
+// Returns:
+//   - A pointer to AuthRepositoryJWT if successful.
+//   - An error if the secret is too short or the JWKS file is invalid.
+func NewRepositoryJWT() (*AuthRepositoryJWT, error) {
+	r := &AuthRepositoryJWT{roleClaim: "role"}
+	if claim := os.Getenv("AUTH_JWT_ROLE_CLAIM"); claim != "" {
+		r.roleClaim = claim
+	}
+
+	if secret := os.Getenv("AUTH_JWT_SECRET"); secret != "" {
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("AUTH_JWT_SECRET must have at least %d characters", minSecretLength)
+		}
+		r.secret = []byte(secret)
+		r.methods = append(r.methods, jwt.SigningMethodHS256.Alg())
+	}
+	if path := os.Getenv("AUTH_JWT_JWKS_PATH"); path != "" {
+		keys, err := readJWKS(path)
+		if err != nil {
+			slog.Error("Error reading the JWKS file", "path", path, "error", err.Error())
+			return nil, err
+		}
+		r.rsaKeys = keys
+		r.methods = append(r.methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	r.options = []jwt.ParserOption{
+		jwt.WithValidMethods(r.methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if issuer := os.Getenv("AUTH_JWT_ISSUER"); issuer != "" {
+		r.options = append(r.options, jwt.WithIssuer(issuer))
+	}
+	if audience := os.Getenv("AUTH_JWT_AUDIENCE"); audience != "" {
+		r.options = append(r.options, jwt.WithAudience(audience))
+	}
+	return r, nil
+}
+
+// jwks is a JSON Web Key Set (RFC 7517), of which only the RSA signing keys are used.
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// readJWKS reads the RSA public keys of a JWKS file, by kid.
+func readJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set jwks
+	if err = json.Unmarshal(file, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") || (key.Alg != "" && key.Alg != "RS256") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of key %q: %w", key.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid exponent of key %q", key.Kid)
+		}
+		keys[key.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no RS256 signing key in the JWKS")
+	}
+	return keys, nil
+}
+
+// key returns the key verifying a token, by its algorithm (and kid).
+func (r *AuthRepositoryJWT) key(token *jwt.Token) (any, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return r.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := r.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		if len(r.rsaKeys) == 1 && kid == "" {
+			for _, key := range r.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// VerifyToken verifies the signature and the claims of a token.
+// Parameters:
+//   - token: The JWT (compact serialization).
+//
+// Returns:
+//   - The Principal of the token subject, with the Role of its role claim.
+//   - domain.ErrUnauthorized if the token is invalid, expired, or has no subject or valid role.
+func (r *AuthRepositoryJWT) VerifyToken(token string) (*Principal, error) {
+	if len(r.methods) == 0 {
+		return nil, domain.ErrUnauthorized
+	}
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, r.key, r.options...); err != nil {
+		slog.Warn("Invalid bearer token", "error", err.Error())
+		return nil, domain.ErrUnauthorized
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		slog.Warn("Bearer token without subject")
+		return nil, domain.ErrUnauthorized
+	}
+	roleName, _ := claims[r.roleClaim].(string)
+	role, err := ParseRole(roleName)
+	if err != nil {
+		slog.Warn("Bearer token without a valid role", "subject", subject, "claim", r.roleClaim)
+		return nil, domain.ErrUnauthorized
+	}
+	return &Principal{Subject: subject, Role: role, Method: "jwt"}, nil
+}
+
+var _ TokenVerifier = (*AuthRepositoryJWT)(nil)
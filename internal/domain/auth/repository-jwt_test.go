@@ -0,0 +1,149 @@
+package authDomain
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+// writeJWKS writes the public keys to a JWKS file, by kid.
+func writeJWKS(t *testing.T, keys map[string]*rsa.PrivateKey) string {
+	t.Helper()
+	set := map[string][]map[string]string{"keys": {}}
+	for kid, key := range keys {
+		set["keys"] = append(set["keys"], map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	file, _ := json.Marshal(set)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, file, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestRepositoryJWT(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	t.Setenv("AUTH_JWT_SECRET", secret)
+	t.Setenv("AUTH_JWT_JWKS_PATH", writeJWKS(t, map[string]*rsa.PrivateKey{"k1": rsaKey}))
+	t.Setenv("AUTH_JWT_ISSUER", "https://issuer.example")
+	t.Setenv("AUTH_JWT_AUDIENCE", "goledger")
+	repository, err := NewRepositoryJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := func(changes jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":  "alice",
+			"role": "writer",
+			"iss":  "https://issuer.example",
+			"aud":  "goledger",
+			"exp":  time.Now().Add(time.Hour).Unix(),
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(c, name)
+			} else {
+				c[name] = value
+			}
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantRole Role
+	}{
+		{"HS256", sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(nil)), RoleWriter},
+		{"RS256", sign(t, jwt.SigningMethodRS256, rsaKey, "k1", claims(jwt.MapClaims{"role": "admin"})), RoleAdmin},
+		{"RS256 without kid, single key", sign(t, jwt.SigningMethodRS256, rsaKey, "", claims(nil)), RoleWriter},
+		{"RS256 unknown key", sign(t, jwt.SigningMethodRS256, otherKey, "k1", claims(nil)), ""},
+		{"RS256 unknown kid", sign(t, jwt.SigningMethodRS256, rsaKey, "k2", claims(nil)), ""},
+		{"HS256 wrong secret", sign(t, jwt.SigningMethodHS256, []byte(secret+"x"), "", claims(nil)), ""},
+		{"HS512 not allowed", sign(t, jwt.SigningMethodHS512, []byte(secret), "", claims(nil)), ""},
+		{"none algorithm", sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", claims(nil)), ""},
+		{"expired", sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})), ""},
+		{"without expiration", sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"exp": nil})), ""},
+		{"wrong issuer", sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"iss": "other"})), ""},
+		{"wrong audience", sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"aud": "other"})), ""},
+		{"without subject", sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"sub": nil})), ""},
+		{"without role", sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"role": nil})), ""},
+		{"unknown role", sign(t, jwt.SigningMethodHS256, []byte(secret), "", claims(jwt.MapClaims{"role": "root"})), ""},
+		{"malformed", "not.a.token", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := repository.VerifyToken(tt.token)
+			if tt.wantRole == "" {
+				if err != domain.ErrUnauthorized {
+					t.Errorf("VerifyToken() = %+v, %v, want %v", principal, err, domain.ErrUnauthorized)
+				}
+				return
+			}
+			if err != nil || principal.Subject != "alice" || principal.Role != tt.wantRole || principal.Method != "jwt" {
+				t.Errorf("VerifyToken() = %+v, %v, want alice as %s", principal, err, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestNewRepositoryJWT(t *testing.T) {
+	// without keys every token is rejected
+	repository, err := NewRepositoryJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := sign(t, jwt.SigningMethodHS256, []byte(secret), "", jwt.MapClaims{"sub": "alice", "role": "admin", "exp": time.Now().Add(time.Hour).Unix()})
+	if _, err = repository.VerifyToken(token); err != domain.ErrUnauthorized {
+		t.Errorf("VerifyToken() without keys error = %v, want %v", err, domain.ErrUnauthorized)
+	}
+
+	t.Setenv("AUTH_JWT_SECRET", "short")
+	if _, err = NewRepositoryJWT(); err == nil {
+		t.Error("NewRepositoryJWT() with a short secret, want an error")
+	}
+	t.Setenv("AUTH_JWT_SECRET", "")
+
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	os.WriteFile(invalid, []byte(`{"keys": [{"kty": "EC", "kid": "k1"}]}`), 0o600)
+	for _, path := range []string{invalid, filepath.Join(t.TempDir(), "missing.json")} {
+		t.Setenv("AUTH_JWT_JWKS_PATH", path)
+		if _, err = NewRepositoryJWT(); err == nil {
+			t.Errorf("NewRepositoryJWT() with JWKS %s, want an error", filepath.Base(path))
+		}
+	}
+}
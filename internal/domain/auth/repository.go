@@ -0,0 +1,50 @@
+package authDomain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// KeyStore persists the API keys, by the hash of the key.
+// It is implemented by AuthRepositoryDB.
+type KeyStore interface {
+	CreateAPIKey(name string, role Role, prefix string, keyHash string) (*APIKey, error)
+	GetAPIKeyByHash(keyHash string) (*APIKey, error)
+	ListAPIKeys() ([]APIKey, error)
+	RevokeAPIKey(id uint64) error
+}
+
+// TokenVerifier authenticates the callers by their bearer tokens.
+// It is implemented by AuthRepositoryJWT.
+type TokenVerifier interface {
+	VerifyToken(token string) (*Principal, error)
+}
+
+const (
+	apiKeyScheme = "glk_"
+	// prefixLength is the part of the key stored in clear, the scheme and 8 characters
+	prefixLength = len(apiKeyScheme) + 8
+)
+
+// GenerateAPIKey creates a random API key (256 bits).
+// Returns:
+//   - The key, to be handed to its owner.
+//   - Its prefix and hash, to be stored.
+//   - An error if the random source fails.
+func GenerateAPIKey() (key string, prefix string, keyHash string, err error) {
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyScheme + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:prefixLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey hashes an API key with SHA-256. The keys are random, so a fast hash
+// is enough and lets the keys be looked up by their hash.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ParseBlockTag converts a block tag ("latest", "pending", "safe", "finalized", "earliest")
+// or a decimal/hex block number into a rpc.BlockNumber. An empty tag means "latest".
+func ParseBlockTag(tag string) (rpc.BlockNumber, error) {
+	if tag == "" {
+		return rpc.LatestBlockNumber, nil
+	}
+	if number, err := strconv.ParseInt(tag, 10, 64); err == nil && number >= 0 {
+		return rpc.BlockNumber(number), nil
+	}
+	var block rpc.BlockNumber
+	err := block.UnmarshalJSON([]byte(tag))
+	return block, err
+}
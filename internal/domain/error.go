@@ -14,4 +14,21 @@ var (
 	ErrBoundContractCall     = errors.New("Error Calling Contract (BoundContract)")
 	ErrBoundContractTransact = errors.New("Error Executing Transaction in Contract (BoundContract)")
 	ErrInvalidSQL            = errors.New("Invalid SQL Query")
+	ErrNodeUnavailable       = errors.New("No Besu Node Available to Handle the Request")
+	ErrNodeRPC               = errors.New("Error Calling Besu Node RPC")
+	ErrNodeMethodDisabled    = errors.New("RPC Method not Enabled on the Besu Node")
+	ErrSignerNotAllowlisted  = errors.New("Signer Account is not in the Besu Accounts Allowlist")
+	ErrTransactionReverted   = errors.New("Transaction Reverted in Contract")
+	ErrTransactionDropped    = errors.New("Transaction Dropped by the Besu Nodes, Never Mined")
+	ErrTransactionPending    = errors.New("Transaction Submitted, Still Pending")
+	ErrInvalidValue          = errors.New("Value Out of the Range of the Contract (uint256)")
+	ErrInvalidRole           = errors.New("Invalid Role (reader, writer or admin)")
+	ErrRateLimited           = errors.New("Too Many Requests, Retry Later")
+	ErrQuotaExceeded         = errors.New("Daily Quota Exceeded")
+	ErrInvalidIdempotencyKey = errors.New("Invalid Idempotency-Key (1 to 255 printable characters)")
+	ErrIdempotencyKeyReused  = errors.New("Idempotency-Key Already Used with a Different Request")
+	ErrIdempotencyInProgress = errors.New("A Request with the Same Idempotency-Key is in Progress")
+	ErrRequestTooLarge       = errors.New("Request Body Too Large")
+	ErrRequestTimedOut       = errors.New("Request Timed Out")
+	ErrShuttingDown          = errors.New("Server is Shutting Down")
 )
@@ -0,0 +1,92 @@
+package explorerDomain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// decoder decodes transaction inputs and logs with the known contract ABIs,
+// matching them by method selector and event topic.
+type decoder struct {
+	abis []*abi.ABI
+}
+
+// formatValue prints the decoded values the way they're written in a call, bytes in hex.
+func formatValue(value any) any {
+	switch v := value.(type) {
+	case []byte:
+		return hexutil.Encode(v)
+	case [32]byte:
+		return hexutil.Encode(v[:])
+	}
+	return value
+}
+
+func (d *decoder) decodeInput(input []byte) *DecodedCall {
+	if len(input) < 4 {
+		return nil
+	}
+	for _, contractABI := range d.abis {
+		method, err := contractABI.MethodById(input[:4])
+		if err != nil {
+			continue
+		}
+		values, err := method.Inputs.Unpack(input[4:])
+		if err != nil {
+			continue
+		}
+		decoded := &DecodedCall{
+			Method:    method.RawName,
+			Signature: method.Sig,
+			Args:      make([]DecodedArg, len(values)),
+		}
+		formatted := make([]string, len(values))
+		for i, value := range values {
+			value = formatValue(value)
+			decoded.Args[i] = DecodedArg{Name: method.Inputs[i].Name, Type: method.Inputs[i].Type.String(), Value: value}
+			formatted[i] = fmt.Sprint(value)
+		}
+		decoded.Call = fmt.Sprintf("%s(%s)", method.RawName, strings.Join(formatted, ", "))
+		return decoded
+	}
+	return nil
+}
+
+func (d *decoder) decodeLog(log *types.Log) *DecodedEvent {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	for _, contractABI := range d.abis {
+		event, err := contractABI.EventByID(log.Topics[0])
+		if err != nil {
+			continue
+		}
+		values := make(map[string]any)
+		if err := event.Inputs.NonIndexed().UnpackIntoMap(values, log.Data); err != nil {
+			continue
+		}
+		var indexed abi.Arguments
+		for _, input := range event.Inputs {
+			if input.Indexed {
+				indexed = append(indexed, input)
+			}
+		}
+		if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+			continue
+		}
+		decoded := &DecodedEvent{
+			Event:     event.RawName,
+			Signature: event.Sig,
+			Args:      make([]DecodedArg, len(event.Inputs)),
+		}
+		for i, input := range event.Inputs {
+			decoded.Args[i] = DecodedArg{Name: input.Name, Type: input.Type.String(), Value: formatValue(values[input.Name])}
+		}
+		return decoded
+	}
+	return nil
+}
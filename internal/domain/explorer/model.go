@@ -0,0 +1,71 @@
+package explorerDomain
+
+import (
+	"math/big"
+	"time"
+)
+
+type Block struct {
+	Number       uint64        `json:"number"`
+	Hash         string        `json:"hash"`
+	ParentHash   string        `json:"parentHash"`
+	Timestamp    time.Time     `json:"timestamp"`
+	Miner        string        `json:"miner"`
+	GasUsed      uint64        `json:"gasUsed"`
+	GasLimit     uint64        `json:"gasLimit"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+type Transaction struct {
+	Hash        string       `json:"hash"`
+	BlockHash   *string      `json:"blockHash"`
+	BlockNumber *uint64      `json:"blockNumber"`
+	From        string       `json:"from"`
+	To          *string      `json:"to"`
+	Nonce       uint64       `json:"nonce"`
+	Value       *big.Int     `json:"value"`
+	Gas         uint64       `json:"gas"`
+	GasPrice    *big.Int     `json:"gasPrice"`
+	Type        uint8        `json:"type"`
+	Input       string       `json:"input"`
+	Decoded     *DecodedCall `json:"decoded,omitempty"`
+}
+
+type Receipt struct {
+	TransactionHash   string  `json:"transactionHash"`
+	BlockHash         string  `json:"blockHash"`
+	BlockNumber       uint64  `json:"blockNumber"`
+	Status            uint64  `json:"status"`
+	GasUsed           uint64  `json:"gasUsed"`
+	CumulativeGasUsed uint64  `json:"cumulativeGasUsed"`
+	ContractAddress   *string `json:"contractAddress"`
+	Logs              []Log   `json:"logs"`
+}
+
+type Log struct {
+	Index   uint          `json:"index"`
+	Address string        `json:"address"`
+	Topics  []string      `json:"topics"`
+	Data    string        `json:"data"`
+	Decoded *DecodedEvent `json:"decoded,omitempty"`
+}
+
+// DecodedCall is a transaction input decoded with a known ABI, Call being the readable form (e.g. "set(42)").
+type DecodedCall struct {
+	Method    string       `json:"method"`
+	Signature string       `json:"signature"`
+	Call      string       `json:"call"`
+	Args      []DecodedArg `json:"args"`
+}
+
+type DecodedEvent struct {
+	Event     string       `json:"event"`
+	Signature string       `json:"signature"`
+	Args      []DecodedArg `json:"args"`
+}
+
+type DecodedArg struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
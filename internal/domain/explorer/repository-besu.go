@@ -0,0 +1,253 @@
+package explorerDomain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type ExplorerRepositoryBesu struct {
+	ctx     *context.Context
+	client  *besuConfig.EthClient
+	decoder *decoder
+}
+
+// NewRepositoryBesu initializes a new instance of ExplorerRepositoryBesu.
+// The known ABIs are the smart contract one (SMART_CONTRACT_ABI_PATH) plus the
+// optional comma-separated EXPLORER_ABI_PATHS.
+// Parameters:
+//   - ctx: The context for node operations.
+//   - client: The Ethereum client configuration.
+//
+// Returns:
+//   - A pointer to ExplorerRepositoryBesu if successful.
+//   - An error if one of the ABI files can't be read.
+func NewRepositoryBesu(ctx *context.Context, client *besuConfig.EthClient) (*ExplorerRepositoryBesu, error) {
+	abiPaths := []string{os.Getenv("SMART_CONTRACT_ABI_PATH")}
+	for _, path := range strings.Split(os.Getenv("EXPLORER_ABI_PATHS"), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			abiPaths = append(abiPaths, path)
+		}
+	}
+	abis := make([]*abi.ABI, len(abiPaths))
+	for i, path := range abiPaths {
+		contractABI, err := besuConfig.ReadABI(path)
+		if err != nil {
+			return nil, err
+		}
+		abis[i] = contractABI
+	}
+
+	return &ExplorerRepositoryBesu{
+		ctx:     ctx,
+		client:  client,
+		decoder: &decoder{abis: abis},
+	}, nil
+}
+
+// rpcBlock is a block as returned by eth_getBlockBy*. It is decoded by hand rather
+// than with types.Block because go-ethereum recomputes the block hash from the
+// header, which doesn't match the QBFT hash computed by Besu.
+type rpcBlock struct {
+	Number       hexutil.Uint64   `json:"number"`
+	Hash         common.Hash      `json:"hash"`
+	ParentHash   common.Hash      `json:"parentHash"`
+	Timestamp    hexutil.Uint64   `json:"timestamp"`
+	Miner        common.Address   `json:"miner"`
+	GasUsed      hexutil.Uint64   `json:"gasUsed"`
+	GasLimit     hexutil.Uint64   `json:"gasLimit"`
+	Transactions []rpcTransaction `json:"transactions"`
+}
+
+type rpcTransaction struct {
+	tx *types.Transaction
+	txExtraInfo
+}
+
+type txExtraInfo struct {
+	BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   *common.Hash    `json:"blockHash"`
+	From        common.Address  `json:"from"`
+}
+
+func (tx *rpcTransaction) UnmarshalJSON(msg []byte) error {
+	if err := json.Unmarshal(msg, &tx.tx); err != nil {
+		return err
+	}
+	return json.Unmarshal(msg, &tx.txExtraInfo)
+}
+
+// nodeError maps the errors of the node pool to domain errors.
+func nodeError(err error) error {
+	switch {
+	case errors.Is(err, besuConfig.ErrNoHealthyNode):
+		return domain.ErrNodeUnavailable
+	case errors.Is(err, ethereum.NotFound):
+		return domain.ErrDataNotFound
+	}
+	return domain.ErrNodeRPC
+}
+
+// callObject performs a raw RPC call whose result is an object, null meaning not found.
+func (r *ExplorerRepositoryBesu) callObject(result any, method string, args ...any) error {
+	var raw json.RawMessage
+	err := r.client.CallContext(*r.ctx, &raw, method, args...)
+	if err != nil {
+		slog.Error("Error calling Besu node rpc", "method", method, "args", args, "error", err.Error())
+		return nodeError(err)
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return domain.ErrDataNotFound
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		slog.Error("Error decoding Besu node rpc response", "method", method, "error", err.Error())
+		return domain.ErrNodeRPC
+	}
+	return nil
+}
+
+func (r *ExplorerRepositoryBesu) toTransaction(rpcTx *rpcTransaction) Transaction {
+	tx := rpcTx.tx
+	transaction := Transaction{
+		Hash:     tx.Hash().Hex(),
+		From:     rpcTx.From.Hex(),
+		Nonce:    tx.Nonce(),
+		Value:    tx.Value(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Type:     tx.Type(),
+		Input:    hexutil.Encode(tx.Data()),
+		Decoded:  r.decoder.decodeInput(tx.Data()),
+	}
+	if tx.To() != nil {
+		to := tx.To().Hex()
+		transaction.To = &to
+	}
+	if rpcTx.BlockHash != nil {
+		blockHash := rpcTx.BlockHash.Hex()
+		blockNumber := uint64(*rpcTx.BlockNumber)
+		transaction.BlockHash = &blockHash
+		transaction.BlockNumber = &blockNumber
+	}
+	return transaction
+}
+
+func (r *ExplorerRepositoryBesu) toBlock(block *rpcBlock) Block {
+	transactions := make([]Transaction, len(block.Transactions))
+	for i := range block.Transactions {
+		transactions[i] = r.toTransaction(&block.Transactions[i])
+	}
+	return Block{
+		Number:       uint64(block.Number),
+		Hash:         block.Hash.Hex(),
+		ParentHash:   block.ParentHash.Hex(),
+		Timestamp:    time.Unix(int64(block.Timestamp), 0).UTC(),
+		Miner:        block.Miner.Hex(),
+		GasUsed:      uint64(block.GasUsed),
+		GasLimit:     uint64(block.GasLimit),
+		Transactions: transactions,
+	}
+}
+
+// GetBlockByNumber retrieves a block, with its transactions, by number or tag.
+// Parameters:
+//   - number: The block number or tag ("latest", "pending", ...).
+//
+// Returns:
+//   - The Block with its decoded transactions.
+//   - domain.ErrDataNotFound if there is no such block, or another error if the RPC call fails.
+func (r *ExplorerRepositoryBesu) GetBlockByNumber(number rpc.BlockNumber) (Block, error) {
+	var block rpcBlock
+	if err := r.callObject(&block, "eth_getBlockByNumber", number.String(), true); err != nil {
+		return Block{}, err
+	}
+	return r.toBlock(&block), nil
+}
+
+// GetBlockByHash retrieves a block, with its transactions, by hash.
+// Parameters:
+//   - hash: The block hash.
+//
+// Returns:
+//   - The Block with its decoded transactions.
+//   - domain.ErrDataNotFound if there is no such block, or another error if the RPC call fails.
+func (r *ExplorerRepositoryBesu) GetBlockByHash(hash common.Hash) (Block, error) {
+	var block rpcBlock
+	if err := r.callObject(&block, "eth_getBlockByHash", hash, true); err != nil {
+		return Block{}, err
+	}
+	return r.toBlock(&block), nil
+}
+
+// GetTransaction retrieves a transaction by hash, decoding its input with the known ABIs.
+// Parameters:
+//   - hash: The transaction hash.
+//
+// Returns:
+//   - The Transaction (without block while it is pending).
+//   - domain.ErrDataNotFound if there is no such transaction, or another error if the RPC call fails.
+func (r *ExplorerRepositoryBesu) GetTransaction(hash common.Hash) (Transaction, error) {
+	var tx rpcTransaction
+	if err := r.callObject(&tx, "eth_getTransactionByHash", hash); err != nil {
+		return Transaction{}, err
+	}
+	return r.toTransaction(&tx), nil
+}
+
+// GetReceipt retrieves the receipt of a mined transaction, decoding its logs with the known ABIs.
+// Parameters:
+//   - hash: The transaction hash.
+//
+// Returns:
+//   - The Receipt with its decoded logs.
+//   - domain.ErrDataNotFound if the transaction is unknown or still pending, or another error if the RPC call fails.
+func (r *ExplorerRepositoryBesu) GetReceipt(hash common.Hash) (Receipt, error) {
+	receipt, err := r.client.TransactionReceipt(*r.ctx, hash)
+	if err != nil {
+		slog.Error("Error getting transaction receipt", "hash", hash.Hex(), "error", err.Error())
+		return Receipt{}, nodeError(err)
+	}
+
+	logs := make([]Log, len(receipt.Logs))
+	for i, log := range receipt.Logs {
+		topics := make([]string, len(log.Topics))
+		for j, topic := range log.Topics {
+			topics[j] = topic.Hex()
+		}
+		logs[i] = Log{
+			Index:   log.Index,
+			Address: log.Address.Hex(),
+			Topics:  topics,
+			Data:    hexutil.Encode(log.Data),
+			Decoded: r.decoder.decodeLog(log),
+		}
+	}
+	result := Receipt{
+		TransactionHash:   receipt.TxHash.Hex(),
+		BlockHash:         receipt.BlockHash.Hex(),
+		BlockNumber:       receipt.BlockNumber.Uint64(),
+		Status:            receipt.Status,
+		GasUsed:           receipt.GasUsed,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		Logs:              logs,
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		contractAddress := receipt.ContractAddress.Hex()
+		result.ContractAddress = &contractAddress
+	}
+	return result, nil
+}
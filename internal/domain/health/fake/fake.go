@@ -0,0 +1,87 @@
+// Package healthFake provides in-memory implementations of the health probes for unit tests.
+package healthFake
+
+import (
+	"math/big"
+	"sync"
+
+	"goledger-challenge-besu/internal/domain/health"
+)
+
+// Database is an in-memory DatabaseProbe, at schema Version out of Latest.
+type Database struct {
+	mu sync.Mutex
+
+	Version uint
+	Latest  uint
+	Dirty   bool
+	PingErr error
+	Pings   int // calls to Ping
+}
+
+func (d *Database) Ping() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Pings++
+	return d.PingErr
+}
+
+func (d *Database) SchemaVersion() (uint, bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.Version, d.Dirty, nil
+}
+
+func (d *Database) LatestSchemaVersion() (uint, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.Latest, nil
+}
+
+// Node is an in-memory NodeProbe. The fields may be changed between the probes
+// through Set.
+type Node struct {
+	mu sync.Mutex
+
+	chainID *big.Int
+	block   uint64
+	peers   uint64
+	err     error
+}
+
+func NewNode(chainID int64, block uint64, peers uint64) *Node {
+	return &Node{chainID: big.NewInt(chainID), block: block, peers: peers}
+}
+
+// Set changes the state of the node, err failing every call.
+func (n *Node) Set(chainID int64, block uint64, peers uint64, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.chainID, n.block, n.peers, n.err = big.NewInt(chainID), block, peers, err
+}
+
+func (n *Node) ChainID() (*big.Int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return nil, n.err
+	}
+	return new(big.Int).Set(n.chainID), nil
+}
+
+func (n *Node) BlockNumber() (uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.block, n.err
+}
+
+func (n *Node) PeerCount() (uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.peers, n.err
+}
+
+var (
+	_ healthDomain.DatabaseProbe = (*Database)(nil)
+	_ healthDomain.NodeProbe     = (*Node)(nil)
+)
@@ -0,0 +1,29 @@
+package healthDomain
+
+import (
+	"time"
+)
+
+// Status is the state of the service or of one of its dependencies.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Check is the result of the check of a dependency.
+type Check struct {
+	Status    Status         `json:"status"`
+	LatencyMs float64        `json:"latencyMs"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// Report is the readiness of the service: up when every dependency is.
+type Report struct {
+	Status    Status           `json:"status"`
+	Checks    map[string]Check `json:"checks"`
+	CheckedAt time.Time        `json:"checkedAt"`
+	Cached    bool             `json:"cached"` // the report of a previous probe, see HEALTH_CACHE_TTL
+}
@@ -0,0 +1,93 @@
+package healthDomain
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type HealthRepositoryBesu struct {
+	ctx     *context.Context
+	client  *besuConfig.EthClient
+	timeout time.Duration
+}
+
+// NewRepositoryBesu initializes a new instance of HealthRepositoryBesu.
+// Parameters:
+//   - ctx: The context for node operations.
+//   - client: The Ethereum client configuration (pool of Besu nodes).
+//
+// Returns:
+//   - A pointer to HealthRepositoryBesu if successful.
+//   - An error if HEALTH_CHECK_TIMEOUT is invalid.
+func NewRepositoryBesu(ctx *context.Context, client *besuConfig.EthClient) (*HealthRepositoryBesu, error) {
+	timeout, err := checkTimeout()
+	if err != nil {
+		return nil, err
+	}
+	return &HealthRepositoryBesu{
+		ctx:     ctx,
+		client:  client,
+		timeout: timeout,
+	}, nil
+}
+
+// nodeError maps the errors of the node calls to domain errors.
+func nodeError(err error) error {
+	if errors.Is(err, besuConfig.ErrNoHealthyNode) || errors.Is(err, besuConfig.ErrNodeNotConnected) {
+		return domain.ErrNodeUnavailable
+	}
+	return domain.ErrNodeRPC
+}
+
+// ChainID reads the chain ID of the network (eth_chainId).
+// Returns:
+//   - The chain ID.
+//   - domain.ErrNodeUnavailable if no node is healthy, or domain.ErrNodeRPC if the call fails.
+func (r *HealthRepositoryBesu) ChainID() (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(*r.ctx, r.timeout)
+	defer cancel()
+	chainID, err := r.client.ChainID(ctx)
+	if err != nil {
+		slog.Error("Error getting chain id from eth client", "error", err.Error())
+		return nil, nodeError(err)
+	}
+	return chainID, nil
+}
+
+// BlockNumber reads the number of the latest block (eth_blockNumber).
+// Returns:
+//   - The block number.
+//   - domain.ErrNodeUnavailable if no node is healthy, or domain.ErrNodeRPC if the call fails.
+func (r *HealthRepositoryBesu) BlockNumber() (uint64, error) {
+	ctx, cancel := context.WithTimeout(*r.ctx, r.timeout)
+	defer cancel()
+	block, err := r.client.BlockNumber(ctx)
+	if err != nil {
+		slog.Error("Error getting block number from eth client", "error", err.Error())
+		return 0, nodeError(err)
+	}
+	return block, nil
+}
+
+// PeerCount reads the number of peers of a node (net_peerCount).
+// Returns:
+//   - The peer count.
+//   - domain.ErrNodeUnavailable if no node is healthy, or domain.ErrNodeRPC if the call fails.
+func (r *HealthRepositoryBesu) PeerCount() (uint64, error) {
+	ctx, cancel := context.WithTimeout(*r.ctx, r.timeout)
+	defer cancel()
+	var count hexutil.Uint64
+	if err := r.client.CallContext(ctx, &count, "net_peerCount"); err != nil {
+		slog.Error("Error getting peer count from eth client", "error", err.Error())
+		return 0, nodeError(err)
+	}
+	return uint64(count), nil
+}
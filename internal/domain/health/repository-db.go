@@ -0,0 +1,77 @@
+package healthDomain
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+)
+
+type HealthRepositoryDB struct {
+	ctx     *context.Context
+	db      *dbConfig.DB
+	timeout time.Duration
+}
+
+// NewRepositoryDB initializes a new instance of HealthRepositoryDB.
+// Parameters:
+//   - ctx: The context for database operations.
+//   - db: The database configuration to use.
+//
+// Returns:
+//   - A pointer to HealthRepositoryDB if successful.
+//   - An error if HEALTH_CHECK_TIMEOUT is invalid.
+func NewRepositoryDB(ctx *context.Context, db *dbConfig.DB) (*HealthRepositoryDB, error) {
+	timeout, err := checkTimeout()
+	if err != nil {
+		return nil, err
+	}
+	return &HealthRepositoryDB{
+		ctx:     ctx,
+		db:      db,
+		timeout: timeout,
+	}, nil
+}
+
+// Ping checks that the database answers.
+// Returns:
+//   - domain.ErrInternal if it doesn't within the check timeout.
+func (r *HealthRepositoryDB) Ping() error {
+	ctx, cancel := context.WithTimeout(*r.ctx, r.timeout)
+	defer cancel()
+	if err := r.db.Ping(ctx); err != nil {
+		slog.Error("Error pinging db", "error", err.Error())
+		return domain.ErrInternal
+	}
+	return nil
+}
+
+// SchemaVersion reads the version of the last migration applied.
+// Returns:
+//   - The version, and whether its migration failed halfway (dirty).
+//   - domain.ErrInternal if the migrations table can't be read.
+func (r *HealthRepositoryDB) SchemaVersion() (uint, bool, error) {
+	ctx, cancel := context.WithTimeout(*r.ctx, r.timeout)
+	defer cancel()
+	version, dirty, err := r.db.SchemaVersion(ctx)
+	if err != nil {
+		slog.Error("Error reading schema version from db", "error", err.Error())
+		return 0, false, domain.ErrInternal
+	}
+	return version, dirty, nil
+}
+
+// LatestSchemaVersion returns the version of the last migration of the binary.
+// Returns:
+//   - The version.
+//   - domain.ErrInternal if the embedded migrations can't be read.
+func (r *HealthRepositoryDB) LatestSchemaVersion() (uint, error) {
+	version, err := r.db.LatestMigration()
+	if err != nil {
+		slog.Error("Error reading the latest migration", "error", err.Error())
+		return 0, domain.ErrInternal
+	}
+	return version, nil
+}
@@ -0,0 +1,42 @@
+package healthDomain
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+// DatabaseProbe checks the database the service runs on.
+// It is implemented by HealthRepositoryDB.
+type DatabaseProbe interface {
+	Ping() error
+	SchemaVersion() (uint, bool, error)
+	LatestSchemaVersion() (uint, error)
+}
+
+// NodeProbe checks the Besu nodes the service sends its requests to.
+// It is implemented by HealthRepositoryBesu.
+type NodeProbe interface {
+	ChainID() (*big.Int, error)
+	BlockNumber() (uint64, error)
+	PeerCount() (uint64, error)
+}
+
+var (
+	_ DatabaseProbe = (*HealthRepositoryDB)(nil)
+	_ NodeProbe     = (*HealthRepositoryBesu)(nil)
+)
+
+// checkTimeout reads HEALTH_CHECK_TIMEOUT, the time each probe call has (2s by default).
+func checkTimeout() (time.Duration, error) {
+	env := os.Getenv("HEALTH_CHECK_TIMEOUT")
+	if env == "" {
+		return 2 * time.Second, nil
+	}
+	timeout, err := time.ParseDuration(env)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid HEALTH_CHECK_TIMEOUT %q", env)
+	}
+	return timeout, nil
+}
@@ -0,0 +1,92 @@
+// Package idempotencyFake provides in-memory implementations of the idempotency repositories for unit tests.
+package idempotencyFake
+
+import (
+	"sync"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/idempotency"
+)
+
+// Store is an in-memory IdempotencyStore.
+type Store struct {
+	mu      sync.Mutex
+	records map[string]idempotencyDomain.Record
+
+	Err error
+}
+
+func NewStore() *Store {
+	return &Store{records: map[string]idempotencyDomain.Record{}}
+}
+
+func key(client string, key string) string {
+	return client + "|" + key
+}
+
+func (s *Store) Begin(record idempotencyDomain.Record) (*idempotencyDomain.Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, false, s.Err
+	}
+	k := key(record.Client, record.Key)
+	if existing, ok := s.records[k]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		leaseOver := record.Lease > 0 && existing.State == idempotencyDomain.StateInProgress &&
+			!existing.CreatedAt.Add(record.Lease).After(record.CreatedAt)
+		if !leaseOver {
+			return &existing, false, nil
+		}
+	}
+	record.State = idempotencyDomain.StateInProgress
+	s.records[k] = record
+	return &record, true, nil
+}
+
+func (s *Store) Complete(client string, idempotencyKey string, statusCode int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := key(client, idempotencyKey)
+	record, ok := s.records[k]
+	if !ok || record.State != idempotencyDomain.StateInProgress {
+		return domain.ErrDataNotFound
+	}
+	record.State = idempotencyDomain.StateCompleted
+	record.StatusCode, record.ContentType, record.ResponseBody = statusCode, contentType, body
+	s.records[k] = record
+	return nil
+}
+
+func (s *Store) Release(client string, idempotencyKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := key(client, idempotencyKey)
+	if record, ok := s.records[k]; ok && record.State == idempotencyDomain.StateInProgress {
+		delete(s.records, k)
+	}
+	return nil
+}
+
+func (s *Store) Purge(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var purged int64
+	for k, record := range s.records {
+		if !record.ExpiresAt.After(now) {
+			delete(s.records, k)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// Get returns the record of an idempotency key, if any.
+func (s *Store) Get(client string, idempotencyKey string) (idempotencyDomain.Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key(client, idempotencyKey)]
+	return record, ok
+}
+
+var _ idempotencyDomain.IdempotencyStore = (*Store)(nil)
@@ -0,0 +1,31 @@
+package idempotencyDomain
+
+import (
+	"time"
+)
+
+// State is the progress of the request of an idempotency key.
+type State string
+
+const (
+	StateInProgress State = "in_progress"
+	StateCompleted  State = "completed"
+)
+
+// Record is a request made with an idempotency key, and its response once completed.
+type Record struct {
+	Client       string
+	Key          string
+	Route        string
+	Fingerprint  string
+	State        State
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	// Lease is how long a request in progress holds the key, from its CreatedAt, before
+	// another one may claim it (e.g. the instance running it stopped); 0 holds it until
+	// it expires. Not stored: the requests of a key share its route, and its timeout.
+	Lease time.Duration
+}
@@ -0,0 +1,213 @@
+package idempotencyDomain
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+type IdempotencyRepositoryDB struct {
+	ctx *context.Context
+	db  *dbConfig.DB
+}
+
+// NewRepositoryDB initializes a new instance of IdempotencyRepositoryDB.
+// Parameters:
+//   - ctx: The context for database operations.
+//   - db: The database configuration to use.
+//
+// Returns:
+//   - A pointer to IdempotencyRepositoryDB.
+//   - An error, reserved for future initialization failures.
+func NewRepositoryDB(ctx *context.Context, db *dbConfig.DB) (*IdempotencyRepositoryDB, error) {
+	return &IdempotencyRepositoryDB{
+		ctx: ctx,
+		db:  db,
+	}, nil
+}
+
+var recordColumns = []string{
+	"client", "idempotency_key", "route", "fingerprint", "state",
+	"status_code", "content_type", "response_body", "created_at", "expires_at",
+}
+
+// Begin claims an idempotency key for a request, with an INSERT ... ON CONFLICT DO
+// NOTHING so only one of concurrent requests gets it. An expired record of the key,
+// or one in progress past the lease, is deleted and the key claimed again.
+// Parameters:
+//   - record: The request, in progress, with its CreatedAt, ExpiresAt and Lease.
+//
+// Returns:
+//   - The record of the key: the new one if claimed, or the one of the request that
+//     claimed it before.
+//   - Whether the key was claimed.
+//   - An error if a query fails.
+func (r *IdempotencyRepositoryDB) Begin(record Record) (*Record, bool, error) {
+	record.State = StateInProgress
+	record.CreatedAt = record.CreatedAt.UTC().Truncate(time.Second)
+	record.ExpiresAt = record.ExpiresAt.UTC().Truncate(time.Second)
+
+	insert := r.db.QueryBuilder.Insert("idempotency_keys").
+		Columns("client", "idempotency_key", "route", "fingerprint", "state", "created_at", "expires_at").
+		Values(record.Client, record.Key, record.Route, record.Fingerprint, string(record.State), record.CreatedAt, record.ExpiresAt).
+		Suffix("ON CONFLICT (client, idempotency_key) DO NOTHING")
+	insertSQL, insertArgs, err := insert.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to insert idempotency key on db", "error", err.Error())
+		return nil, false, domain.ErrInvalidSQL
+	}
+
+	// a record expiring or deleted between the statements is retried, twice at most
+	for range 3 {
+		tag, err := r.db.Exec(*r.ctx, insertSQL, insertArgs...)
+		if err != nil {
+			slog.Error("Error inserting idempotency key on db", "sql", insertSQL, "error", err.Error())
+			return nil, false, domain.ErrInternal
+		}
+		if tag.RowsAffected() == 1 {
+			return &record, true, nil
+		}
+
+		existing, err := r.get(record.Client, record.Key)
+		if err == domain.ErrDataNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		// the records in progress since before leasedAt lost their lease
+		leasedAt := record.CreatedAt.Add(-record.Lease)
+		leaseOver := record.Lease > 0 && existing.State == StateInProgress && !existing.CreatedAt.After(leasedAt)
+		if existing.ExpiresAt.After(record.CreatedAt) && !leaseOver {
+			return existing, false, nil
+		}
+		if leaseOver {
+			slog.Warn("Idempotency key in progress past its lease, claimed again", "client", record.Client, "route", record.Route)
+		}
+		stale := sq.Or{sq.LtOrEq{"expires_at": record.CreatedAt}}
+		if record.Lease > 0 {
+			stale = append(stale, sq.And{sq.Eq{"state": string(StateInProgress)}, sq.LtOrEq{"created_at": leasedAt}})
+		}
+		if err = r.delete(sq.Eq{"client": record.Client, "idempotency_key": record.Key}, stale); err != nil {
+			return nil, false, err
+		}
+	}
+	slog.Error("Error claiming idempotency key on db, it keeps changing", "client", record.Client)
+	return nil, false, domain.ErrConflictingData
+}
+
+func (r *IdempotencyRepositoryDB) get(client string, key string) (*Record, error) {
+	query := r.db.QueryBuilder.Select(recordColumns...).From("idempotency_keys").
+		Where(sq.Eq{"client": client, "idempotency_key": key})
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to get idempotency key from db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
+	}
+
+	var record Record
+	var state string
+	err = r.db.QueryRow(*r.ctx, sql, args...).Scan(&record.Client, &record.Key, &record.Route, &record.Fingerprint, &state,
+		&record.StatusCode, &record.ContentType, &record.ResponseBody, &record.CreatedAt, &record.ExpiresAt)
+	if err == pgx.ErrNoRows {
+		return nil, domain.ErrDataNotFound
+	}
+	if err != nil {
+		slog.Error("Error getting idempotency key from db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	record.State = State(state)
+	return &record, nil
+}
+
+func (r *IdempotencyRepositoryDB) delete(where ...sq.Sqlizer) error {
+	query := r.db.QueryBuilder.Delete("idempotency_keys")
+	for _, condition := range where {
+		query = query.Where(condition)
+	}
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to delete idempotency key on db", "error", err.Error())
+		return domain.ErrInvalidSQL
+	}
+	if _, err = r.db.Exec(*r.ctx, sql, args...); err != nil {
+		slog.Error("Error deleting idempotency key on db", "sql", sql, "error", err.Error())
+		return domain.ErrInternal
+	}
+	return nil
+}
+
+// Complete stores the response of the request of an idempotency key, to replay it.
+// Parameters:
+//   - client: The client identifier.
+//   - key: The idempotency key.
+//   - statusCode: The status code of the response.
+//   - contentType: The Content-Type of the response.
+//   - body: The body of the response.
+//
+// Returns:
+//   - domain.ErrDataNotFound if the key isn't in progress, or another error if the update fails.
+func (r *IdempotencyRepositoryDB) Complete(client string, key string, statusCode int, contentType string, body []byte) error {
+	query := r.db.QueryBuilder.Update("idempotency_keys").
+		Set("state", string(StateCompleted)).
+		Set("status_code", statusCode).
+		Set("content_type", contentType).
+		Set("response_body", body).
+		Where(sq.Eq{"client": client, "idempotency_key": key, "state": string(StateInProgress)})
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to complete idempotency key on db", "error", err.Error())
+		return domain.ErrInvalidSQL
+	}
+	tag, err := r.db.Exec(*r.ctx, sql, args...)
+	if err != nil {
+		slog.Error("Error completing idempotency key on db", "sql", sql, "error", err.Error())
+		return domain.ErrInternal
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDataNotFound
+	}
+	return nil
+}
+
+// Release frees an idempotency key in progress, so the request can be retried.
+// Parameters:
+//   - client: The client identifier.
+//   - key: The idempotency key.
+//
+// Returns:
+//   - An error if the delete fails.
+func (r *IdempotencyRepositoryDB) Release(client string, key string) error {
+	return r.delete(sq.Eq{"client": client, "idempotency_key": key, "state": string(StateInProgress)})
+}
+
+// Purge deletes the expired idempotency keys.
+// Parameters:
+//   - now: The current time.
+//
+// Returns:
+//   - The number of keys deleted.
+//   - An error if the delete fails.
+func (r *IdempotencyRepositoryDB) Purge(now time.Time) (int64, error) {
+	query := r.db.QueryBuilder.Delete("idempotency_keys").
+		Where(sq.LtOrEq{"expires_at": now.UTC().Truncate(time.Second)})
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to purge idempotency keys on db", "error", err.Error())
+		return 0, domain.ErrInvalidSQL
+	}
+	tag, err := r.db.Exec(*r.ctx, sql, args...)
+	if err != nil {
+		slog.Error("Error purging idempotency keys on db", "sql", sql, "error", err.Error())
+		return 0, domain.ErrInternal
+	}
+	return tag.RowsAffected(), nil
+}
+
+var _ IdempotencyStore = (*IdempotencyRepositoryDB)(nil)
@@ -0,0 +1,148 @@
+package idempotencyDomain
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+func TestFingerprint(t *testing.T) {
+	const path = "/api/v1/smart-contract/set-value"
+	body := Fingerprint("POST", path, []byte(`{"value": 10, "privateKey": "abc"}`))
+	if same := Fingerprint("POST", path, []byte(`{"privateKey":"abc","value":10}`)); same != body {
+		t.Error("Fingerprint() differs for the same JSON serialized differently")
+	}
+	for name, other := range map[string]string{
+		"body":   Fingerprint("POST", path, []byte(`{"value": 11, "privateKey": "abc"}`)),
+		"number": Fingerprint("POST", path, []byte(`{"value": 10.0, "privateKey": "abc"}`)),
+		"path":   Fingerprint("POST", "/api/v1/smart-contract/sync", []byte(`{"value": 10, "privateKey": "abc"}`)),
+		"method": Fingerprint("PUT", path, []byte(`{"value": 10, "privateKey": "abc"}`)),
+	} {
+		if other == body {
+			t.Errorf("Fingerprint() is the same for another %s", name)
+		}
+	}
+	if Fingerprint("POST", path, []byte("not json")) == Fingerprint("POST", path, []byte("not  json")) {
+		t.Error("Fingerprint() of bodies that aren't JSON ignores their bytes")
+	}
+}
+
+// TestRepositoryDB runs the repository on the embedded SQLite backend.
+func TestRepositoryDB(t *testing.T) {
+	t.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "app.db"))
+	ctx := context.Background()
+	db, err := dbConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err = db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	repository, _ := NewRepositoryDB(&ctx, db)
+
+	now := time.Now()
+	request := func(client string, key string, at time.Time) Record {
+		return Record{Client: client, Key: key, Route: "POST /api/v1/smart-contract/set-value", Fingerprint: "f1",
+			CreatedAt: at, ExpiresAt: at.Add(time.Hour)}
+	}
+
+	record, claimed, err := repository.Begin(request("key:1", "k1", now))
+	if err != nil || !claimed || record.State != StateInProgress {
+		t.Fatalf("Begin() = %+v, %v, %v, want the key claimed", record, claimed, err)
+	}
+	record, claimed, err = repository.Begin(request("key:1", "k1", now))
+	if err != nil || claimed || record.State != StateInProgress || record.Fingerprint != "f1" {
+		t.Fatalf("Begin() of a key in progress = %+v, %v, %v, want the record in progress", record, claimed, err)
+	}
+	// the keys are per client
+	if _, claimed, _ = repository.Begin(request("key:2", "k1", now)); !claimed {
+		t.Error("Begin() of the key of another client didn't claim it")
+	}
+
+	if err = repository.Complete("key:1", "k1", 200, "application/json", []byte(`"done"`)); err != nil {
+		t.Fatal(err)
+	}
+	record, claimed, err = repository.Begin(request("key:1", "k1", now))
+	if err != nil || claimed || record.State != StateCompleted || record.StatusCode != 200 ||
+		record.ContentType != "application/json" || string(record.ResponseBody) != `"done"` {
+		t.Fatalf("Begin() of a completed key = %+v, %v, %v, want the response", record, claimed, err)
+	}
+	if err = repository.Complete("key:1", "k1", 500, "", nil); err != domain.ErrDataNotFound {
+		t.Errorf("Complete() of a completed key error = %v, want %v", err, domain.ErrDataNotFound)
+	}
+	// a completed key isn't released
+	repository.Release("key:1", "k1")
+	if _, claimed, _ = repository.Begin(request("key:1", "k1", now)); claimed {
+		t.Error("Release() freed a completed key")
+	}
+
+	// a released key can be claimed again
+	repository.Release("key:2", "k1")
+	if _, claimed, _ = repository.Begin(request("key:2", "k1", now)); !claimed {
+		t.Error("Begin() of a released key didn't claim it")
+	}
+
+	// an expired key is claimed again
+	later := now.Add(2 * time.Hour)
+	if record, claimed, err = repository.Begin(request("key:1", "k1", later)); err != nil || !claimed || record.State != StateInProgress {
+		t.Errorf("Begin() of an expired key = %+v, %v, %v, want the key claimed", record, claimed, err)
+	}
+	if purged, err := repository.Purge(later); err != nil || purged != 1 {
+		t.Errorf("Purge() = %d, %v, want the key of key:2 purged", purged, err)
+	}
+
+	// a key in progress is claimed again past its lease, not within it
+	leased := request("key:4", "k1", now)
+	leased.Lease = time.Minute
+	if _, claimed, _ = repository.Begin(leased); !claimed {
+		t.Fatal("Begin() of a new key didn't claim it")
+	}
+	leased.CreatedAt = now.Add(30 * time.Second)
+	if _, claimed, _ = repository.Begin(leased); claimed {
+		t.Error("Begin() within the lease claimed the key in progress")
+	}
+	leased.CreatedAt = now.Add(2 * time.Minute)
+	if record, claimed, err = repository.Begin(leased); err != nil || !claimed || record.State != StateInProgress {
+		t.Errorf("Begin() past the lease = %+v, %v, %v, want the key claimed", record, claimed, err)
+	}
+	// a completed key is kept past the lease
+	repository.Complete("key:4", "k1", 200, "application/json", []byte(`"done"`))
+	leased.CreatedAt = now.Add(5 * time.Minute)
+	if record, claimed, _ = repository.Begin(leased); claimed || record.State != StateCompleted {
+		t.Errorf("Begin() of a completed key past the lease = %+v, %v, want the response", record, claimed)
+	}
+
+	// only one of concurrent requests claims a key
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	claims := 0
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, claimed, err := repository.Begin(request("key:3", "k1", now)); err == nil && claimed {
+				mu.Lock()
+				claims++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if claims != 1 {
+		t.Errorf("%d concurrent requests claimed the key, want 1", claims)
+	}
+}
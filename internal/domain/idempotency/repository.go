@@ -0,0 +1,37 @@
+package idempotencyDomain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// IdempotencyStore keeps the requests made with an idempotency key, per client.
+// It is implemented by IdempotencyRepositoryDB.
+type IdempotencyStore interface {
+	Begin(record Record) (*Record, bool, error)
+	Complete(client string, key string, statusCode int, contentType string, body []byte) error
+	Release(client string, key string) error
+	Purge(now time.Time) (int64, error)
+}
+
+// Fingerprint identifies a request by its method, path and body, so a key can't be
+// reused for another request. JSON bodies are compacted and their keys sorted first,
+// so the retries of a client that serializes them differently still match.
+// Only the hash is stored: the bodies may hold secrets (e.g. the private key of set-value).
+func Fingerprint(method string, path string, body []byte) string {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil && !decoder.More() {
+		if canonical, err := json.Marshal(value); err == nil {
+			body = canonical
+		}
+	}
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
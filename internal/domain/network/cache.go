@@ -0,0 +1,57 @@
+package networkDomain
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// ttlCache keeps the responses of the introspection calls for a short time,
+// so dashboards polling the API don't hammer the nodes.
+type ttlCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// cached returns the value stored under key, calling fetch (and storing its result) when
+// it is missing or expired. Errors are not cached.
+func cached[T any](c *ttlCache, key string, fetch func() (T, error)) (T, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.value.(T), nil
+	}
+
+	value, err := fetch()
+	if err != nil || c.ttl <= 0 {
+		return value, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, expiresAt: now.Add(c.ttl)}
+	return value, nil
+}
+
+// clear drops every entry, so the next reads see the changes made through the API.
+func (c *ttlCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
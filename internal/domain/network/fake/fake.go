@@ -0,0 +1,31 @@
+// Package networkFake provides in-memory implementations of the network repositories for unit tests.
+package networkFake
+
+import (
+	"goledger-challenge-besu/internal/domain/network"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Allowlist is an in-memory AccountAllowlist. Like Besu, an empty allowlist allows every account.
+type Allowlist struct {
+	Accounts []common.Address
+	Err      error
+}
+
+func (a *Allowlist) IsAccountAllowlisted(account common.Address) (bool, error) {
+	if a.Err != nil {
+		return false, a.Err
+	}
+	if len(a.Accounts) == 0 {
+		return true, nil
+	}
+	for _, allowed := range a.Accounts {
+		if allowed == account {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+var _ networkDomain.AccountAllowlist = (*Allowlist)(nil)
@@ -0,0 +1,106 @@
+package networkDomain
+
+import (
+	"time"
+)
+
+type NodeStatus struct {
+	URL         string    `json:"url"`
+	State       string    `json:"state"`
+	StateSince  time.Time `json:"stateSince"`
+	Reconnects  uint64    `json:"reconnects"`
+	Healthy     bool      `json:"healthy"`
+	BlockNumber uint64    `json:"blockNumber"`
+	PeerCount   uint64    `json:"peerCount"`
+	Syncing     bool      `json:"syncing"`
+	LatencyMs   float64   `json:"latencyMs"`
+	LastError   string    `json:"lastError,omitempty"`
+	CheckedAt   time.Time `json:"checkedAt"`
+}
+
+type NetworkStatus struct {
+	Available bool         `json:"available"`
+	Nodes     []NodeStatus `json:"nodes"`
+}
+
+type Validators struct {
+	Block      string   `json:"block"`
+	Validators []string `json:"validators"`
+}
+
+type SignerMetric struct {
+	Address                 string `json:"address"`
+	ProposedBlockCount      uint64 `json:"proposedBlockCount"`
+	LastProposedBlockNumber uint64 `json:"lastProposedBlockNumber"`
+}
+
+type Peer struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Enode         string   `json:"enode"`
+	Caps          []string `json:"caps"`
+	LocalAddress  string   `json:"localAddress"`
+	RemoteAddress string   `json:"remoteAddress"`
+}
+
+type PeerCount struct {
+	Count uint64 `json:"count"`
+}
+
+type SyncStatus struct {
+	Syncing       bool   `json:"syncing"`
+	StartingBlock uint64 `json:"startingBlock,omitempty"`
+	CurrentBlock  uint64 `json:"currentBlock,omitempty"`
+	HighestBlock  uint64 `json:"highestBlock,omitempty"`
+}
+
+type TxPoolStatistics struct {
+	MaxSize     uint64 `json:"maxSize"`
+	LocalCount  uint64 `json:"localCount"`
+	RemoteCount uint64 `json:"remoteCount"`
+}
+
+const (
+	VoteActionProposeAdd    = "propose_add"
+	VoteActionProposeRemove = "propose_remove"
+	VoteActionDiscard       = "discard"
+)
+
+// VoteResult is the outcome of a validator vote action on a single node.
+type VoteResult struct {
+	Node      string `json:"node"`
+	Action    string `json:"action"`
+	Validator string `json:"validator"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+}
+
+type PendingVote struct {
+	Validator string `json:"validator"`
+	Add       bool   `json:"add"`
+}
+
+type NodePendingVotes struct {
+	Node  string        `json:"node"`
+	Votes []PendingVote `json:"votes"`
+	Error string        `json:"error,omitempty"`
+}
+
+type ValidatorVotes struct {
+	Validators []string           `json:"validators"`
+	Pending    []NodePendingVotes `json:"pending"`
+}
+
+// NodeAllowlist is the local permissioning allowlist (accounts or enode URLs) of a single node.
+type NodeAllowlist struct {
+	Node    string   `json:"node"`
+	Entries []string `json:"entries"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// PermissioningResult is the outcome of an allowlist change on a single node.
+type PermissioningResult struct {
+	Node    string `json:"node"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
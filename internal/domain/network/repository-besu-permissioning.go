@@ -0,0 +1,173 @@
+package networkDomain
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// getAllowlists reads an allowlist (perm_getAccountsAllowlist or perm_getNodesAllowlist) from every configured node.
+func (r *NetworkRepositoryBesu) getAllowlists(method string) []NodeAllowlist {
+	return forEachNode(r, func(index int, url string) NodeAllowlist {
+		allowlist := NodeAllowlist{Node: url, Entries: []string{}}
+		var entries []string
+		err := r.client.CallNode(*r.ctx, index, &entries, method)
+		if err != nil {
+			slog.Error("Error calling Besu node rpc", "method", method, "node", url, "error", err.Error())
+			allowlist.Error = rpcError(err).Error()
+			return allowlist
+		}
+		if entries != nil {
+			allowlist.Entries = entries
+		}
+		return allowlist
+	})
+}
+
+// changeAllowlists adds or removes entries of an allowlist on every configured node.
+// Besu answers "Success" when the change was applied.
+func (r *NetworkRepositoryBesu) changeAllowlists(method string, entries []string) []PermissioningResult {
+	defer r.cache.clear()
+	return forEachNode(r, func(index int, url string) PermissioningResult {
+		result := PermissioningResult{Node: url}
+		var status string
+		err := r.client.CallNode(*r.ctx, index, &status, method, entries)
+		switch {
+		case err != nil:
+			slog.Error("Error calling Besu node rpc", "method", method, "node", url, "error", err.Error())
+			// errors such as "Cannot add duplicate account" come from the node, keep them visible
+			var rpcErr rpc.Error
+			if errors.As(err, &rpcErr) {
+				result.Error = rpcErr.Error()
+			} else {
+				result.Error = rpcError(err).Error()
+			}
+		case status != "Success":
+			result.Error = status
+		default:
+			result.Success = true
+		}
+		return result
+	})
+}
+
+// GetAccountsAllowlists retrieves the accounts allowlist of every configured node (perm_getAccountsAllowlist).
+// Returns:
+//   - The NodeAllowlist of each node, with the error of the nodes that couldn't be asked.
+func (r *NetworkRepositoryBesu) GetAccountsAllowlists() []NodeAllowlist {
+	return r.getAllowlists("perm_getAccountsAllowlist")
+}
+
+// AddAccountsToAllowlist adds accounts to the allowlist of every configured node (perm_addAccountsToAllowlist).
+// Parameters:
+//   - accounts: The accounts to add.
+//
+// Returns:
+//   - The PermissioningResult of each node.
+func (r *NetworkRepositoryBesu) AddAccountsToAllowlist(accounts []common.Address) []PermissioningResult {
+	return r.changeAllowlists("perm_addAccountsToAllowlist", addressesToHex(accounts))
+}
+
+// RemoveAccountsFromAllowlist removes accounts from the allowlist of every configured node (perm_removeAccountsFromAllowlist).
+// Parameters:
+//   - accounts: The accounts to remove.
+//
+// Returns:
+//   - The PermissioningResult of each node.
+func (r *NetworkRepositoryBesu) RemoveAccountsFromAllowlist(accounts []common.Address) []PermissioningResult {
+	return r.changeAllowlists("perm_removeAccountsFromAllowlist", addressesToHex(accounts))
+}
+
+// GetNodesAllowlists retrieves the nodes allowlist of every configured node (perm_getNodesAllowlist).
+// Returns:
+//   - The NodeAllowlist (enode URLs) of each node, with the error of the nodes that couldn't be asked.
+func (r *NetworkRepositoryBesu) GetNodesAllowlists() []NodeAllowlist {
+	return r.getAllowlists("perm_getNodesAllowlist")
+}
+
+// AddNodesToAllowlist adds enode URLs to the allowlist of every configured node (perm_addNodesToAllowlist).
+// Parameters:
+//   - enodes: The enode URLs to add.
+//
+// Returns:
+//   - The PermissioningResult of each node.
+func (r *NetworkRepositoryBesu) AddNodesToAllowlist(enodes []string) []PermissioningResult {
+	return r.changeAllowlists("perm_addNodesToAllowlist", enodes)
+}
+
+// RemoveNodesFromAllowlist removes enode URLs from the allowlist of every configured node (perm_removeNodesFromAllowlist).
+// Parameters:
+//   - enodes: The enode URLs to remove.
+//
+// Returns:
+//   - The PermissioningResult of each node.
+func (r *NetworkRepositoryBesu) RemoveNodesFromAllowlist(enodes []string) []PermissioningResult {
+	return r.changeAllowlists("perm_removeNodesFromAllowlist", enodes)
+}
+
+// IsAccountAllowlisted checks if an account may send transactions, according to the
+// accounts allowlist of a healthy node. Nodes without local account permissioning
+// (PERM api disabled, or allowlisting not enabled) answer with an error, and
+// then every account is allowed, as is an empty allowlist. Any other error fails
+// the check, so that a failing node doesn't let every signer through.
+// Parameters:
+//   - account: The account to check.
+//
+// Returns:
+//   - True if the account is allowed to send transactions.
+//   - An error if no node could be asked, or the node failed to answer.
+func (r *NetworkRepositoryBesu) IsAccountAllowlisted(account common.Address) (bool, error) {
+	allowlist, err := cached(r.cache, "perm_getAccountsAllowlist", func() ([]common.Address, error) {
+		var allowlist []common.Address
+		err := r.client.CallContext(*r.ctx, &allowlist, "perm_getAccountsAllowlist")
+		return allowlist, err
+	})
+	if err != nil {
+		if !accountsPermissioningEnforced(err) {
+			slog.Debug("Accounts permissioning not enforced by the node", "error", err.Error())
+			return true, nil
+		}
+		slog.Error("Error getting accounts allowlist", "error", err.Error())
+		return false, rpcError(err)
+	}
+	if len(allowlist) == 0 {
+		return true, nil
+	}
+	for _, allowed := range allowlist {
+		if allowed == account {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// accountsPermissioningEnforced tells whether the error of perm_getAccountsAllowlist
+// comes from a node enforcing account permissioning. Only the nodes without the PERM
+// api (-32601 method not found, -32604 method not enabled), or with the api but
+// without an accounts allowlist (-32000 "Account allowlisting has not been enabled"),
+// don't enforce it.
+func accountsPermissioningEnforced(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return true
+	}
+	switch rpcErr.ErrorCode() {
+	case -32601, -32604:
+		return false
+	case -32000:
+		return !strings.Contains(strings.ToLower(rpcErr.Error()), "has not been enabled")
+	default:
+		return true
+	}
+}
+
+func addressesToHex(addresses []common.Address) []string {
+	hexes := make([]string, len(addresses))
+	for i, address := range addresses {
+		hexes[i] = address.Hex()
+	}
+	return hexes
+}
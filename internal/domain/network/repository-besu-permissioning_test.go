@@ -0,0 +1,46 @@
+package networkDomain
+
+import (
+	"errors"
+	"testing"
+
+	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/internal/domain"
+)
+
+// rpcErr is an error answered by a node.
+type rpcErr struct {
+	code    int
+	message string
+}
+
+func (e rpcErr) Error() string  { return e.message }
+func (e rpcErr) ErrorCode() int { return e.code }
+
+func TestAccountsPermissioningEnforced(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"method not found", rpcErr{-32601, "Method not found"}, false},
+		{"method not enabled", rpcErr{-32604, "Method not enabled"}, false},
+		{"allowlisting not enabled", rpcErr{-32000, "Account allowlisting has not been enabled"}, false},
+		{"other server error", rpcErr{-32000, "Error reloading permissions file"}, true},
+		{"internal error", rpcErr{-32603, "Internal error"}, true},
+		{"no healthy node", besuConfig.ErrNoHealthyNode, true},
+		{"transport error", errors.New("connection refused"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accountsPermissioningEnforced(tt.err); got != tt.want {
+				t.Errorf("accountsPermissioningEnforced(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	// the errors of the nodes enforcing it fail the check
+	if err := rpcError(rpcErr{-32603, "Internal error"}); err != domain.ErrNodeRPC {
+		t.Errorf("rpcError() = %v, want %v", err, domain.ErrNodeRPC)
+	}
+}
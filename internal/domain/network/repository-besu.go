@@ -0,0 +1,391 @@
+package networkDomain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type NetworkRepositoryBesu struct {
+	ctx    *context.Context
+	client *besuConfig.EthClient
+	cache  *ttlCache
+}
+
+// NewRepositoryBesu initializes a new instance of NetworkRepositoryBesu.
+// Parameters:
+//   - ctx: The context for node operations.
+//   - client: The Ethereum client configuration (pool of Besu nodes).
+//
+// Returns:
+//   - A pointer to NetworkRepositoryBesu if successful.
+//   - An error if the introspection cache TTL is invalid.
+func NewRepositoryBesu(ctx *context.Context, client *besuConfig.EthClient) (*NetworkRepositoryBesu, error) {
+	cacheTTL := 2 * time.Second
+	if env := os.Getenv("BESU_INTROSPECTION_CACHE_TTL"); env != "" {
+		ttl, err := time.ParseDuration(env)
+		if err != nil {
+			slog.Error("Error reading introspection cache ttl", "value", env, "error", err.Error())
+			return nil, errors.New("Invalid introspection cache ttl")
+		}
+		cacheTTL = ttl
+	}
+
+	return &NetworkRepositoryBesu{
+		ctx:    ctx,
+		client: client,
+		cache:  newTTLCache(cacheTTL),
+	}, nil
+}
+
+// redactURL hides credentials that may be embedded in a node URL.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Redacted()
+}
+
+// rpcError maps the errors of a raw RPC call to domain errors.
+func rpcError(err error) error {
+	switch {
+	case errors.Is(err, besuConfig.ErrNoHealthyNode), errors.Is(err, besuConfig.ErrNodeNotConnected):
+		return domain.ErrNodeUnavailable
+	case errors.Is(err, besuConfig.ErrUnknownNode):
+		return domain.ErrDataNotFound
+	}
+	// -32601: method not found, -32604: method not enabled (Besu, when the API isn't in --rpc-http-api)
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && (rpcErr.ErrorCode() == -32601 || rpcErr.ErrorCode() == -32604) {
+		return domain.ErrNodeMethodDisabled
+	}
+	return domain.ErrNodeRPC
+}
+
+// call performs a cached raw RPC call, on the given node or, when node is nil, on any healthy node.
+func call[T any](r *NetworkRepositoryBesu, node *int, method string, args ...any) (T, error) {
+	key := fmt.Sprint(method, args)
+	if node != nil {
+		key = fmt.Sprint(*node, key)
+	}
+	result, err := cached(r.cache, key, func() (T, error) {
+		var result T
+		var err error
+		if node != nil {
+			err = r.client.CallNode(*r.ctx, *node, &result, method, args...)
+		} else {
+			err = r.client.CallContext(*r.ctx, &result, method, args...)
+		}
+		return result, err
+	})
+	if err != nil {
+		slog.Error("Error calling Besu node rpc", "call", key, "error", err.Error())
+		return result, rpcError(err)
+	}
+	return result, nil
+}
+
+// GetStatus returns the connection and health state of every configured Besu node.
+// Returns:
+//   - The NetworkStatus, available if at least one node can serve requests.
+func (r *NetworkRepositoryBesu) GetStatus() NetworkStatus {
+	nodes := r.client.Nodes()
+	status := NetworkStatus{
+		Available: r.client.Available(),
+		Nodes:     make([]NodeStatus, len(nodes)),
+	}
+	for i, node := range nodes {
+		status.Nodes[i] = NodeStatus{
+			URL:         redactURL(node.URL),
+			State:       node.State,
+			StateSince:  node.StateSince,
+			Reconnects:  node.Reconnects,
+			Healthy:     node.Healthy,
+			BlockNumber: node.BlockNumber,
+			PeerCount:   node.PeerCount,
+			Syncing:     node.Syncing,
+			LatencyMs:   float64(node.Latency) / float64(time.Millisecond),
+			LastError:   node.LastError,
+			CheckedAt:   node.CheckedAt,
+		}
+	}
+	return status
+}
+
+// GetValidators retrieves the QBFT validators at the given block (qbft_getValidatorsByBlockNumber).
+// Parameters:
+//   - block: The block to get the validator set at.
+//
+// Returns:
+//   - The Validators with the addresses of the validator set.
+//   - An error if the RPC call fails.
+func (r *NetworkRepositoryBesu) GetValidators(block rpc.BlockNumber) (Validators, error) {
+	addresses, err := call[[]common.Address](r, nil, "qbft_getValidatorsByBlockNumber", block.String())
+	if err != nil {
+		return Validators{}, err
+	}
+	validators := Validators{Block: block.String(), Validators: make([]string, len(addresses))}
+	for i, address := range addresses {
+		validators.Validators[i] = address.Hex()
+	}
+	return validators, nil
+}
+
+// GetSignerMetrics retrieves how many blocks each validator proposed in a block range (qbft_getSignerMetrics).
+// Parameters:
+//   - fromBlock: The first block of the range (nil lets Besu use the last 100 blocks).
+//   - toBlock: The last block of the range (nil means "latest").
+//
+// Returns:
+//   - The SignerMetric of each validator.
+//   - An error if the RPC call fails.
+func (r *NetworkRepositoryBesu) GetSignerMetrics(fromBlock, toBlock *rpc.BlockNumber) ([]SignerMetric, error) {
+	var args []any
+	if fromBlock != nil {
+		args = append(args, fromBlock.String())
+		if toBlock != nil {
+			args = append(args, toBlock.String())
+		}
+	}
+	type signerMetric struct {
+		Address                 common.Address `json:"address"`
+		ProposedBlockCount      hexutil.Uint64 `json:"proposedBlockCount"`
+		LastProposedBlockNumber hexutil.Uint64 `json:"lastProposedBlockNumber"`
+	}
+	raw, err := call[[]signerMetric](r, nil, "qbft_getSignerMetrics", args...)
+	if err != nil {
+		return nil, err
+	}
+	metrics := make([]SignerMetric, len(raw))
+	for i, metric := range raw {
+		metrics[i] = SignerMetric{
+			Address:                 metric.Address.Hex(),
+			ProposedBlockCount:      uint64(metric.ProposedBlockCount),
+			LastProposedBlockNumber: uint64(metric.LastProposedBlockNumber),
+		}
+	}
+	return metrics, nil
+}
+
+// GetPeers retrieves the peers connected to a node (admin_peers, requires the ADMIN api).
+// Parameters:
+//   - node: The index of the node to ask, or nil for any healthy node.
+//
+// Returns:
+//   - The Peer list.
+//   - An error if the RPC call fails.
+func (r *NetworkRepositoryBesu) GetPeers(node *int) ([]Peer, error) {
+	type peer struct {
+		ID      string   `json:"id"`
+		Name    string   `json:"name"`
+		Enode   string   `json:"enode"`
+		Caps    []string `json:"caps"`
+		Network struct {
+			LocalAddress  string `json:"localAddress"`
+			RemoteAddress string `json:"remoteAddress"`
+		} `json:"network"`
+	}
+	raw, err := call[[]peer](r, node, "admin_peers")
+	if err != nil {
+		return nil, err
+	}
+	peers := make([]Peer, len(raw))
+	for i, peer := range raw {
+		peers[i] = Peer{
+			ID:            peer.ID,
+			Name:          peer.Name,
+			Enode:         peer.Enode,
+			Caps:          peer.Caps,
+			LocalAddress:  peer.Network.LocalAddress,
+			RemoteAddress: peer.Network.RemoteAddress,
+		}
+	}
+	return peers, nil
+}
+
+// GetPeerCount retrieves the number of peers connected to a node (net_peerCount).
+// Parameters:
+//   - node: The index of the node to ask, or nil for any healthy node.
+//
+// Returns:
+//   - The PeerCount.
+//   - An error if the RPC call fails.
+func (r *NetworkRepositoryBesu) GetPeerCount(node *int) (PeerCount, error) {
+	count, err := call[hexutil.Uint64](r, node, "net_peerCount")
+	if err != nil {
+		return PeerCount{}, err
+	}
+	return PeerCount{Count: uint64(count)}, nil
+}
+
+// GetSyncStatus retrieves the synchronization progress of a node (eth_syncing).
+// Parameters:
+//   - node: The index of the node to ask, or nil for any healthy node.
+//
+// Returns:
+//   - The SyncStatus, with the progress blocks only while syncing.
+//   - An error if the RPC call fails.
+func (r *NetworkRepositoryBesu) GetSyncStatus(node *int) (SyncStatus, error) {
+	// eth_syncing answers false when the node is in sync, or an object with the progress otherwise
+	raw, err := call[json.RawMessage](r, node, "eth_syncing")
+	if err != nil {
+		return SyncStatus{}, err
+	}
+	var syncing bool
+	if err := json.Unmarshal(raw, &syncing); err == nil {
+		return SyncStatus{Syncing: syncing}, nil
+	}
+	var progress struct {
+		StartingBlock hexutil.Uint64 `json:"startingBlock"`
+		CurrentBlock  hexutil.Uint64 `json:"currentBlock"`
+		HighestBlock  hexutil.Uint64 `json:"highestBlock"`
+	}
+	if err := json.Unmarshal(raw, &progress); err != nil {
+		slog.Error("Error decoding eth_syncing response", "response", string(raw), "error", err.Error())
+		return SyncStatus{}, domain.ErrNodeRPC
+	}
+	return SyncStatus{
+		Syncing:       true,
+		StartingBlock: uint64(progress.StartingBlock),
+		CurrentBlock:  uint64(progress.CurrentBlock),
+		HighestBlock:  uint64(progress.HighestBlock),
+	}, nil
+}
+
+// GetTxPoolStatistics retrieves the transaction pool counters of a node (txpool_besuStatistics, requires the TXPOOL api).
+// Parameters:
+//   - node: The index of the node to ask, or nil for any healthy node.
+//
+// Returns:
+//   - The TxPoolStatistics.
+//   - An error if the RPC call fails.
+func (r *NetworkRepositoryBesu) GetTxPoolStatistics(node *int) (TxPoolStatistics, error) {
+	return call[TxPoolStatistics](r, node, "txpool_besuStatistics")
+}
+
+// forEachNode runs fn concurrently on every configured node, returning the results in the BESU_URL order.
+func forEachNode[T any](r *NetworkRepositoryBesu, fn func(index int, url string) T) []T {
+	nodes := r.client.Nodes()
+	results := make([]T, len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = fn(i, redactURL(node.URL))
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// voteOnNode runs a vote RPC method (which answers true on success) on a single node.
+func (r *NetworkRepositoryBesu) voteOnNode(index int, url string, action string, validator common.Address, method string, args ...any) VoteResult {
+	result := VoteResult{Node: url, Action: action, Validator: validator.Hex()}
+	var ok bool
+	err := r.client.CallNode(*r.ctx, index, &ok, method, args...)
+	switch {
+	case err != nil:
+		slog.Error("Error calling Besu node rpc", "method", method, "node", url, "error", err.Error())
+		result.Error = rpcError(err).Error()
+	case !ok:
+		result.Error = "node refused the vote"
+	default:
+		result.Success = true
+	}
+	return result
+}
+
+// ProposeValidatorVote proposes adding or removing a validator on every configured node (qbft_proposeValidatorVote).
+// Parameters:
+//   - validator: The address of the validator.
+//   - add: True to vote for adding the validator, false to vote for removing it.
+//
+// Returns:
+//   - The VoteResult of each node.
+func (r *NetworkRepositoryBesu) ProposeValidatorVote(validator common.Address, add bool) []VoteResult {
+	action := VoteActionProposeRemove
+	if add {
+		action = VoteActionProposeAdd
+	}
+	return forEachNode(r, func(index int, url string) VoteResult {
+		return r.voteOnNode(index, url, action, validator, "qbft_proposeValidatorVote", validator, add)
+	})
+}
+
+// DiscardValidatorVotes discards pending votes on every configured node (qbft_discardValidatorVote).
+// Parameters:
+//   - validator: The validator whose vote is discarded, or nil to discard every pending vote of each node.
+//
+// Returns:
+//   - The VoteResult of each discarded vote on each node.
+func (r *NetworkRepositoryBesu) DiscardValidatorVotes(validator *common.Address) []VoteResult {
+	results := forEachNode(r, func(index int, url string) []VoteResult {
+		validators := []common.Address{}
+		if validator != nil {
+			validators = append(validators, *validator)
+		} else {
+			pending, err := r.getPendingVotes(index)
+			if err != nil {
+				return []VoteResult{{Node: url, Action: VoteActionDiscard, Error: rpcError(err).Error()}}
+			}
+			for address := range pending {
+				validators = append(validators, address)
+			}
+		}
+
+		results := make([]VoteResult, len(validators))
+		for i, address := range validators {
+			results[i] = r.voteOnNode(index, url, VoteActionDiscard, address, "qbft_discardValidatorVote", address)
+		}
+		return results
+	})
+	flattened := []VoteResult{}
+	for _, nodeResults := range results {
+		flattened = append(flattened, nodeResults...)
+	}
+	return flattened
+}
+
+func (r *NetworkRepositoryBesu) getPendingVotes(index int) (map[common.Address]bool, error) {
+	var pending map[common.Address]bool
+	err := r.client.CallNode(*r.ctx, index, &pending, "qbft_getPendingVotes")
+	return pending, err
+}
+
+// GetPendingVotes retrieves the votes each configured node will cast when proposing blocks (qbft_getPendingVotes).
+// Returns:
+//   - The NodePendingVotes of each node, with the error of the nodes that couldn't be asked.
+func (r *NetworkRepositoryBesu) GetPendingVotes() []NodePendingVotes {
+	return forEachNode(r, func(index int, url string) NodePendingVotes {
+		votes := NodePendingVotes{Node: url, Votes: []PendingVote{}}
+		pending, err := r.getPendingVotes(index)
+		if err != nil {
+			slog.Error("Error calling Besu node rpc", "method", "qbft_getPendingVotes", "node", url, "error", err.Error())
+			votes.Error = rpcError(err).Error()
+			return votes
+		}
+		for address, add := range pending {
+			votes.Votes = append(votes.Votes, PendingVote{Validator: address.Hex(), Add: add})
+		}
+		sort.Slice(votes.Votes, func(i, j int) bool {
+			return votes.Votes[i].Validator < votes.Votes[j].Validator
+		})
+		return votes
+	})
+}
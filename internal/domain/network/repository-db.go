@@ -0,0 +1,61 @@
+package networkDomain
+
+import (
+	"context"
+	"log/slog"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+)
+
+type NetworkRepositoryDB struct {
+	ctx *context.Context
+	db  *dbConfig.DB
+}
+
+// NewRepositoryDB initializes a new instance of NetworkRepositoryDB.
+// Parameters:
+//   - ctx: The context for database operations.
+//   - db: The database configuration to use.
+//
+// Returns:
+//   - A pointer to NetworkRepositoryDB.
+//   - An error, reserved for future initialization failures.
+func NewRepositoryDB(ctx *context.Context, db *dbConfig.DB) (*NetworkRepositoryDB, error) {
+	return &NetworkRepositoryDB{
+		ctx: ctx,
+		db:  db,
+	}, nil
+}
+
+// CreateValidatorVotes stores the audit records of validator vote actions, one per node.
+// Parameters:
+//   - results: The VoteResult of each node.
+//   - requestedBy: Who requested the action (client IP).
+//
+// Returns:
+//   - An error if the SQL generation or the insert fails.
+func (r *NetworkRepositoryDB) CreateValidatorVotes(results []VoteResult, requestedBy string) error {
+	if len(results) == 0 {
+		return nil
+	}
+	query := r.db.QueryBuilder.Insert("validator_votes").Columns("action", "validator", "node", "success", "error", "requested_by")
+	for _, result := range results {
+		var voteError *string
+		if result.Error != "" {
+			voteError = &result.Error
+		}
+		query = query.Values(result.Action, result.Validator, result.Node, result.Success, voteError, requestedBy)
+	}
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to insert validator votes on db", "error", err.Error())
+		return domain.ErrInvalidSQL
+	}
+	_, err = r.db.Exec(*r.ctx, sql, args...)
+	if err != nil {
+		slog.Error("Error inserting validator votes on db", "sql", sql, "error", err.Error())
+		return domain.ErrInternal
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package networkDomain
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AccountAllowlist tells whether an account may send transactions to the nodes.
+// It is implemented by NetworkRepositoryBesu.
+type AccountAllowlist interface {
+	IsAccountAllowlisted(account common.Address) (bool, error)
+}
+
+var _ AccountAllowlist = (*NetworkRepositoryBesu)(nil)
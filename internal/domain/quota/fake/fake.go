@@ -0,0 +1,59 @@
+// Package quotaFake provides in-memory implementations of the quota repositories for unit tests.
+package quotaFake
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/quota"
+)
+
+// Store is an in-memory QuotaStore.
+type Store struct {
+	mu   sync.Mutex
+	used map[string]uint64
+
+	Err error
+}
+
+func NewStore() *Store {
+	return &Store{used: map[string]uint64{}}
+}
+
+func key(client string, route string, day time.Time) string {
+	return fmt.Sprintf("%s|%s|%s", client, route, day.Format(time.DateOnly))
+}
+
+func (s *Store) Consume(client string, route string, day time.Time, limit uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return 0, s.Err
+	}
+	k := key(client, route, day)
+	if s.used[k] >= limit {
+		return limit, domain.ErrQuotaExceeded
+	}
+	s.used[k]++
+	return s.used[k], nil
+}
+
+func (s *Store) Release(client string, route string, day time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if k := key(client, route, day); s.used[k] > 0 {
+		s.used[k]--
+	}
+	return nil
+}
+
+// Used returns the requests counted for a client on a route and day.
+func (s *Store) Used(client string, route string, day time.Time) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.used[key(client, route, day)]
+}
+
+var _ quotaDomain.QuotaStore = (*Store)(nil)
@@ -0,0 +1,101 @@
+package quotaDomain
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+type QuotaRepositoryDB struct {
+	ctx *context.Context
+	db  *dbConfig.DB
+}
+
+// NewRepositoryDB initializes a new instance of QuotaRepositoryDB.
+// Parameters:
+//   - ctx: The context for database operations.
+//   - db: The database configuration to use.
+//
+// Returns:
+//   - A pointer to QuotaRepositoryDB.
+//   - An error, reserved for future initialization failures.
+func NewRepositoryDB(ctx *context.Context, db *dbConfig.DB) (*QuotaRepositoryDB, error) {
+	return &QuotaRepositoryDB{
+		ctx: ctx,
+		db:  db,
+	}, nil
+}
+
+// Consume counts a request against the daily quota of a client on a route, with a
+// single INSERT ... ON CONFLICT DO UPDATE statement guarded by the limit, so
+// concurrent requests (and instances of the service) never go over it.
+// Parameters:
+//   - client: The client identifier.
+//   - route: The route (method and path).
+//   - day: The quota day (Day).
+//   - limit: The daily quota.
+//
+// Returns:
+//   - The requests used that day, this one included.
+//   - domain.ErrQuotaExceeded if the quota is used up, or another error if the upsert fails.
+func (r *QuotaRepositoryDB) Consume(client string, route string, day time.Time, limit uint64) (uint64, error) {
+	if limit == 0 {
+		return 0, domain.ErrQuotaExceeded
+	}
+	query := r.db.QueryBuilder.Insert("client_quotas").
+		Columns("client", "route", "day", "used").
+		Values(client, route, day, 1).
+		Suffix("ON CONFLICT (client, route, day) DO UPDATE SET used = client_quotas.used + 1, updated_at = CURRENT_TIMESTAMP "+
+			"WHERE client_quotas.used < ? RETURNING used", limit)
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to consume quota on db", "error", err.Error())
+		return 0, domain.ErrInvalidSQL
+	}
+
+	var used uint64
+	err = r.db.QueryRow(*r.ctx, sql, args...).Scan(&used)
+	if err == pgx.ErrNoRows {
+		// the guard skipped the update, the quota is used up
+		return limit, domain.ErrQuotaExceeded
+	}
+	if err != nil {
+		slog.Error("Error consuming quota on db", "sql", sql, "error", err.Error())
+		return 0, domain.ErrInternal
+	}
+	return used, nil
+}
+
+// Release gives back a request counted by Consume, e.g. when it failed.
+// Parameters:
+//   - client: The client identifier.
+//   - route: The route (method and path).
+//   - day: The quota day the request was counted on.
+//
+// Returns:
+//   - An error if the update fails.
+func (r *QuotaRepositoryDB) Release(client string, route string, day time.Time) error {
+	query := r.db.QueryBuilder.Update("client_quotas").
+		Set("used", sq.Expr("used - 1")).
+		Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"client": client, "route": route, "day": day}).
+		Where(sq.Gt{"used": 0})
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error generating query sql to release quota on db", "error", err.Error())
+		return domain.ErrInvalidSQL
+	}
+	if _, err = r.db.Exec(*r.ctx, sql, args...); err != nil {
+		slog.Error("Error releasing quota on db", "sql", sql, "error", err.Error())
+		return domain.ErrInternal
+	}
+	return nil
+}
+
+var _ QuotaStore = (*QuotaRepositoryDB)(nil)
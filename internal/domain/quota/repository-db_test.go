@@ -0,0 +1,92 @@
+package quotaDomain
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/internal/domain"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+func TestDay(t *testing.T) {
+	at := time.Date(2025, 3, 1, 23, 30, 0, 0, time.FixedZone("BRT", -3*3600))
+	if day := Day(at); !day.Equal(time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Day(%v) = %v, want 2025-03-02 UTC", at, day)
+	}
+}
+
+// TestRepositoryDB runs the repository on the embedded SQLite backend.
+func TestRepositoryDB(t *testing.T) {
+	t.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "app.db"))
+	ctx := context.Background()
+	db, err := dbConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err = db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	repository, _ := NewRepositoryDB(&ctx, db)
+
+	const route = "POST /api/v1/smart-contract/set-value"
+	today := Day(time.Now())
+	for want := uint64(1); want <= 3; want++ {
+		if used, err := repository.Consume("key:1", route, today, 3); err != nil || used != want {
+			t.Fatalf("Consume() = %d, %v, want %d", used, err, want)
+		}
+	}
+	if _, err = repository.Consume("key:1", route, today, 3); err != domain.ErrQuotaExceeded {
+		t.Fatalf("Consume() over the quota error = %v, want %v", err, domain.ErrQuotaExceeded)
+	}
+
+	// the quotas are per client, route and day
+	if used, err := repository.Consume("key:2", route, today, 3); err != nil || used != 1 {
+		t.Errorf("Consume() of another client = %d, %v, want 1", used, err)
+	}
+	if used, err := repository.Consume("key:1", "POST /api/v1/smart-contract/sync", today, 3); err != nil || used != 1 {
+		t.Errorf("Consume() of another route = %d, %v, want 1", used, err)
+	}
+	if used, err := repository.Consume("key:1", route, today.AddDate(0, 0, 1), 3); err != nil || used != 1 {
+		t.Errorf("Consume() of the next day = %d, %v, want 1", used, err)
+	}
+
+	// a released request can be made again
+	if err = repository.Release("key:1", route, today); err != nil {
+		t.Fatal(err)
+	}
+	if used, err := repository.Consume("key:1", route, today, 3); err != nil || used != 3 {
+		t.Errorf("Consume() after Release() = %d, %v, want 3", used, err)
+	}
+
+	// concurrent requests never go over the quota
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	accepted := 0
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repository.Consume("key:3", route, today, 5); err == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if accepted != 5 {
+		t.Errorf("%d concurrent requests accepted, want 5", accepted)
+	}
+}
@@ -0,0 +1,18 @@
+package quotaDomain
+
+import (
+	"time"
+)
+
+// QuotaStore counts the requests of the clients against their daily quotas.
+// It is implemented by QuotaRepositoryDB.
+type QuotaStore interface {
+	Consume(client string, route string, day time.Time, limit uint64) (uint64, error)
+	Release(client string, route string, day time.Time) error
+}
+
+// Day returns the quota day (UTC) of an instant.
+func Day(at time.Time) time.Time {
+	year, month, day := at.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
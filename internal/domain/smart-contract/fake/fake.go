@@ -0,0 +1,267 @@
+// Package smartContractFake provides in-memory implementations of the smart contract
+// repositories (ValueReader, ValueWriter, ValueStore and TransactionStore) for unit tests.
+package smartContractFake
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"goledger-challenge-besu/internal/domain"
+	"goledger-challenge-besu/internal/domain/smart-contract"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Address is the address of the in-memory smart contract.
+var Address = common.HexToAddress("0x42699A7612A82f1d9C36148af9C77354759b210b")
+
+// Contract is an in-memory smart contract, implementing ValueReader and ValueWriter.
+// The transactions are mined as soon as they are sent, each in its own block.
+// The errors, when set, are returned by the matching methods.
+type Contract struct {
+	mu     sync.Mutex
+	value  *big.Int
+	values map[common.Address]*big.Int
+	block  uint64 // mined by each SendValue
+	mined  map[string]uint64
+
+	GetErr    error
+	GetsErr   error
+	SetErr    error         // returned by SendValue
+	WaitErr   error         // returned by WaitTransaction, the transaction stays pending
+	Revert    bool          // the transactions sent revert
+	Drop      bool          // the transactions sent are dropped
+	Mining    chan struct{} // when set, WaitTransaction waits for it to be closed, or for its context
+	CheckErr  error
+	SetValues []*big.Int // values sent by SendValue, in order
+}
+
+// NewContract returns a Contract storing value.
+// values are the contracts read by GetValues, the missing ones fail with "no contract code".
+func NewContract(value *big.Int, values map[common.Address]*big.Int) *Contract {
+	return &Contract{value: new(big.Int).Set(value), values: values}
+}
+
+func (c *Contract) GetValue(ctx context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.GetErr != nil {
+		return new(big.Int), c.GetErr
+	}
+	return new(big.Int).Set(c.value), nil
+}
+
+func (c *Contract) GetLatestValue(ctx context.Context) (*smartContractDomain.BlockValue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.GetErr != nil {
+		return nil, c.GetErr
+	}
+	return &smartContractDomain.BlockValue{
+		Value:       new(big.Int).Set(c.value),
+		BlockNumber: c.block,
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(c.block)).Hex(),
+	}, nil
+}
+
+func (c *Contract) GetValues(ctx context.Context, addresses []common.Address, block rpc.BlockNumber) ([]smartContractDomain.SmartContractValue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.GetsErr != nil {
+		return nil, c.GetsErr
+	}
+	values := make([]smartContractDomain.SmartContractValue, len(addresses))
+	for i, address := range addresses {
+		values[i].Address = address.Hex()
+		if value, ok := c.values[address]; ok {
+			values[i].Value = new(big.Int).Set(value)
+		} else {
+			values[i].Error = "no contract code at address"
+		}
+	}
+	return values, nil
+}
+
+func (c *Contract) CheckValue(ctx context.Context, value *big.Int) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.CheckErr != nil {
+		return false, c.CheckErr
+	}
+	return c.value.Cmp(value) == 0, nil
+}
+
+func (c *Contract) SendValue(ctx context.Context, value *big.Int, privateKey string) (*smartContractDomain.SentTransaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.SetErr != nil {
+		return nil, c.SetErr
+	}
+	nonce := uint64(len(c.SetValues))
+	c.SetValues = append(c.SetValues, new(big.Int).Set(value))
+	c.block++
+	if !c.Revert && !c.Drop {
+		c.value = new(big.Int).Set(value)
+	}
+	hash := crypto.Keccak256Hash(new(big.Int).SetUint64(nonce).Bytes()).Hex()
+	if c.mined == nil {
+		c.mined = map[string]uint64{}
+	}
+	c.mined[hash] = c.block
+	signer, _ := smartContractDomain.SignerAddress(privateKey)
+	return &smartContractDomain.SentTransaction{
+		Hash:     hash,
+		Signer:   signer.Hex(),
+		Contract: Address.Hex(),
+		Nonce:    nonce,
+		Method:   "set(" + value.String() + ")",
+		State:    smartContractDomain.TransactionPending,
+	}, nil
+}
+
+func (c *Contract) WaitTransaction(ctx context.Context, hash string) (smartContractDomain.TransactionState, uint64, error) {
+	c.mu.Lock()
+	mining := c.Mining
+	c.mu.Unlock()
+	if mining != nil {
+		select {
+		case <-mining:
+		case <-ctx.Done():
+			return smartContractDomain.TransactionPending, 0, domain.ErrBoundContractTransact
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.WaitErr != nil {
+		return smartContractDomain.TransactionPending, 0, c.WaitErr
+	}
+	block, ok := c.mined[hash]
+	if !ok {
+		return smartContractDomain.TransactionPending, 0, domain.ErrDataNotFound
+	}
+	if c.Drop {
+		return smartContractDomain.TransactionDropped, 0, nil
+	}
+	if c.Revert {
+		return smartContractDomain.TransactionReverted, block, nil
+	}
+	return smartContractDomain.TransactionMined, block, nil
+}
+
+// Store is an in-memory ValueStore and TransactionStore. Like the database, SyncValue
+// ignores the values of a block older than or equal to the stored one, and records the
+// others in Synced.
+type Store struct {
+	mu           sync.Mutex
+	synced       *smartContractDomain.SyncResult
+	transactions map[string]smartContractDomain.SentTransaction
+
+	SyncErr error
+	TxErr   error
+	Synced  []*big.Int
+}
+
+// NewStore returns an empty Store, never synchronized.
+func NewStore() *Store {
+	return &Store{}
+}
+
+func (s *Store) SyncValue(ctx context.Context, value smartContractDomain.BlockValue) (*smartContractDomain.SyncResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.SyncErr != nil {
+		return nil, s.SyncErr
+	}
+	if s.synced != nil && s.synced.BlockNumber >= value.BlockNumber {
+		result := *s.synced
+		result.Value = new(big.Int).Set(s.synced.Value)
+		result.Changed = false
+		return &result, nil
+	}
+	s.synced = &smartContractDomain.SyncResult{
+		Address:     Address.Hex(),
+		Value:       new(big.Int).Set(value.Value),
+		BlockNumber: value.BlockNumber,
+		BlockHash:   value.BlockHash,
+		Changed:     true,
+		UpdatedAt:   time.Now(),
+	}
+	s.Synced = append(s.Synced, new(big.Int).Set(value.Value))
+	result := *s.synced
+	result.Value = new(big.Int).Set(value.Value)
+	return &result, nil
+}
+
+func (s *Store) SaveTransaction(ctx context.Context, tx smartContractDomain.SentTransaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.TxErr != nil {
+		return s.TxErr
+	}
+	if s.transactions == nil {
+		s.transactions = map[string]smartContractDomain.SentTransaction{}
+	}
+	if _, ok := s.transactions[tx.Hash]; ok {
+		return domain.ErrConflictingData
+	}
+	tx.CreatedAt, tx.UpdatedAt = time.Now(), time.Now()
+	s.transactions[tx.Hash] = tx
+	return nil
+}
+
+func (s *Store) UpdateTransactionState(ctx context.Context, hash string, state smartContractDomain.TransactionState, blockNumber uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.TxErr != nil {
+		return s.TxErr
+	}
+	tx, ok := s.transactions[hash]
+	if !ok {
+		return domain.ErrDataNotFound
+	}
+	tx.State, tx.BlockNumber, tx.UpdatedAt = state, &blockNumber, time.Now()
+	if state == smartContractDomain.TransactionDropped {
+		tx.BlockNumber = nil
+	}
+	s.transactions[hash] = tx
+	return nil
+}
+
+func (s *Store) ListPendingTransactions(ctx context.Context) ([]smartContractDomain.SentTransaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.TxErr != nil {
+		return nil, s.TxErr
+	}
+	txs := []smartContractDomain.SentTransaction{}
+	for _, tx := range s.transactions {
+		if tx.State == smartContractDomain.TransactionPending {
+			txs = append(txs, tx)
+		}
+	}
+	return txs, nil
+}
+
+// Transactions returns the stored transactions, in no particular order.
+func (s *Store) Transactions() []smartContractDomain.SentTransaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txs := []smartContractDomain.SentTransaction{}
+	for _, tx := range s.transactions {
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+var (
+	_ smartContractDomain.ValueReader = (*Contract)(nil)
+	_ smartContractDomain.ValueWriter = (*Contract)(nil)
+	_ smartContractDomain.ValueStore  = (*Store)(nil)
+
+	_ smartContractDomain.TransactionStore = (*Store)(nil)
+)
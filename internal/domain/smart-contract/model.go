@@ -1,13 +1,67 @@
 package smartContractDomain
 
 import (
+	"math/big"
 	"time"
 )
 
 type SmartContractDB struct {
 	SmartContractId uint64
 	Address         string
-	Value           uint64
+	Value           *big.Int
+	BlockNumber     uint64
+	BlockHash       string
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
+
+// BlockValue is the value of the smart contract read at a given block.
+type BlockValue struct {
+	Value       *big.Int
+	BlockNumber uint64
+	BlockHash   string
+}
+
+// SyncResult is the value stored in the database after a sync.
+// Changed is false when the database already held the value of the same block or
+// of a newer one, in which case that stored value is returned and nothing is written.
+type SyncResult struct {
+	Address     string    `json:"address"`
+	Value       *big.Int  `json:"value"`
+	BlockNumber uint64    `json:"blockNumber"`
+	BlockHash   string    `json:"blockHash"`
+	Changed     bool      `json:"changed"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// SmartContractValue is the result of reading one contract inside a batch read.
+// Error is filled (and Value left empty) when that single read failed.
+type SmartContractValue struct {
+	Address string   `json:"address"`
+	Value   *big.Int `json:"value,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// TransactionState is the state of a transaction sent by the service.
+type TransactionState string
+
+const (
+	TransactionPending  TransactionState = "pending"
+	TransactionMined    TransactionState = "mined"
+	TransactionReverted TransactionState = "reverted"
+	TransactionDropped  TransactionState = "dropped" // no longer known to the nodes, never mined
+)
+
+// SentTransaction is a transaction sent by the service. It is stored while pending,
+// so a transaction still waited for at shutdown is tracked after the restart.
+type SentTransaction struct {
+	Hash        string
+	Signer      string
+	Contract    string // the address of the contract called, not stored
+	Nonce       uint64
+	Method      string // e.g. set(42)
+	State       TransactionState
+	BlockNumber *uint64 // nil while pending, and when dropped
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
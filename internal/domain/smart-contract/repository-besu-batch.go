@@ -0,0 +1,209 @@
+package smartContractDomain
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/configs/tracing"
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Canonical Multicall3 deployment address (same on every chain it was deployed with the presigned tx)
+const defaultMulticallAddress = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// Only the aggregate3 method of Multicall3 is needed here
+const multicallABI = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// multicall holds the Multicall3 bound contract and remembers whether it is
+// deployed on the connected chain (checked lazily, only once it succeeds).
+type multicall struct {
+	mu            sync.Mutex
+	deployed      *bool
+	address       common.Address
+	boundContract *bind.BoundContract
+}
+
+func newMulticall(client Backend) (*multicall, error) {
+	multicallHexAddress := os.Getenv("MULTICALL_ADDR")
+	if multicallHexAddress == "" {
+		multicallHexAddress = defaultMulticallAddress
+	}
+	if !common.IsHexAddress(multicallHexAddress) {
+		slog.Error("Error reading multicall addres", "error", "Multicall address in invalid format")
+		return nil, errors.New("Invalid multicall address")
+	}
+	multicallAddress := common.HexToAddress(multicallHexAddress)
+
+	abi, err := abi.JSON(strings.NewReader(multicallABI))
+	if err != nil {
+		slog.Error("Error parsing multicall abi", "error", err.Error())
+		return nil, err
+	}
+
+	return &multicall{
+		address:       multicallAddress,
+		boundContract: bind.NewBoundContract(multicallAddress, abi, client, client, client),
+	}, nil
+}
+
+// isDeployed reports if there is code at the Multicall3 address.
+// The answer is only cached after a successful lookup, so a node outage doesn't disable multicall forever.
+func (m *multicall) isDeployed(ctx context.Context, r *SmartContractRepositoryBesu) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deployed != nil {
+		return *m.deployed, nil
+	}
+	code, err := r.client.CodeAt(ctx, m.address, nil)
+	if err != nil {
+		return false, err
+	}
+	deployed := len(code) > 0
+	m.deployed = &deployed
+	return deployed, nil
+}
+
+// blockNumberArg converts a rpc.BlockNumber into the *big.Int expected by bind.CallOpts.
+// Latest maps to nil and the other tags to their negative values, which the eth client
+// translates back into "pending", "safe", "finalized" and "earliest".
+func blockNumberArg(block rpc.BlockNumber) *big.Int {
+	if block == rpc.LatestBlockNumber {
+		return nil
+	}
+	return big.NewInt(block.Int64())
+}
+
+// GetValues reads the value stored in many contracts with a single RPC round-trip.
+// It aggregates the calls through Multicall3 when it is deployed on the chain (and
+// at the block read), falling back to a JSON-RPC batch of eth_call requests otherwise.
+// Parameters:
+//   - ctx: The context of the caller, holding its span.
+//   - addresses: The addresses of the contracts (SimpleStorage ABI) to read.
+//   - block: The block to read the values at.
+//
+// Returns:
+//   - A slice with one SmartContractValue per address, in the same order. Single read failures are reported inside it.
+//   - An error if the whole batch fails.
+func (r *SmartContractRepositoryBesu) GetValues(ctx context.Context, addresses []common.Address, block rpc.BlockNumber) ([]SmartContractValue, error) {
+	ctx = tracingConfig.Join(*r.ctx, ctx)
+	if len(addresses) == 0 {
+		return []SmartContractValue{}, nil
+	}
+	callData, err := r.abi.Pack("get")
+	if err != nil {
+		slog.ErrorContext(ctx, "Error packing contract call data", "error", err.Error())
+		return nil, domain.ErrBoundContractCall
+	}
+
+	deployed, err := r.multicall.isDeployed(ctx, r)
+	if errors.Is(err, besuConfig.ErrNoHealthyNode) {
+		return nil, domain.ErrNodeUnavailable
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Error checking multicall deployment, falling back to rpc batch", "error", err.Error())
+	}
+	if deployed {
+		return r.getValuesMulticall(ctx, addresses, block, callData)
+	}
+	return r.getValuesBatch(ctx, addresses, block, callData)
+}
+
+func (r *SmartContractRepositoryBesu) getValuesMulticall(ctx context.Context, addresses []common.Address, block rpc.BlockNumber, callData []byte) ([]SmartContractValue, error) {
+	calls := make([]multicallCall, len(addresses))
+	for i, address := range addresses {
+		calls[i] = multicallCall{Target: address, AllowFailure: true, CallData: callData}
+	}
+
+	caller := bind.CallOpts{
+		BlockNumber: blockNumberArg(block),
+	}
+	var output []any
+	err := call(ctx, r.multicall.boundContract, r.multicall.address, &caller, &output, "aggregate3", calls)
+	if errors.Is(err, bind.ErrNoCode) {
+		// a block before the deployment of Multicall3, deployed only at the latest one
+		slog.InfoContext(ctx, "Multicall not deployed at the block, falling back to rpc batch", "block", block.String())
+		return r.getValuesBatch(ctx, addresses, block, callData)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error calling multicall contract (bound contract)", "block", block.String(), "error", err.Error())
+		return nil, besuError(err, domain.ErrBoundContractCall)
+	}
+	results := *abi.ConvertType(output[0], new([]multicallResult)).(*[]multicallResult)
+
+	values := make([]SmartContractValue, len(addresses))
+	for i, address := range addresses {
+		values[i] = r.unpackValue(address, results[i].ReturnData, results[i].Success)
+	}
+	return values, nil
+}
+
+func (r *SmartContractRepositoryBesu) getValuesBatch(ctx context.Context, addresses []common.Address, block rpc.BlockNumber, callData []byte) ([]SmartContractValue, error) {
+	outputs := make([]hexutil.Bytes, len(addresses))
+	batch := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []any{
+				map[string]any{"to": address, "data": hexutil.Bytes(callData)},
+				block.String(),
+			},
+			Result: &outputs[i],
+		}
+	}
+
+	err := r.client.BatchCallContext(ctx, batch)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error sending eth_call rpc batch", "block", block.String(), "error", err.Error())
+		return nil, besuError(err, domain.ErrBoundContractCall)
+	}
+
+	values := make([]SmartContractValue, len(addresses))
+	for i, address := range addresses {
+		if batch[i].Error != nil {
+			values[i] = SmartContractValue{Address: address.Hex(), Error: batch[i].Error.Error()}
+			continue
+		}
+		values[i] = r.unpackValue(address, outputs[i], true)
+	}
+	return values, nil
+}
+
+// unpackValue decodes the output of a single "get" call.
+// Empty output means the call hit an account without code (or a contract without the method).
+func (r *SmartContractRepositoryBesu) unpackValue(address common.Address, output []byte, success bool) SmartContractValue {
+	if !success {
+		return SmartContractValue{Address: address.Hex(), Error: "call reverted"}
+	}
+	if len(output) == 0 {
+		return SmartContractValue{Address: address.Hex(), Error: bind.ErrNoCode.Error()}
+	}
+	unpacked, err := r.abi.Unpack("get", output)
+	if err != nil {
+		return SmartContractValue{Address: address.Hex(), Error: err.Error()}
+	}
+	value := *abi.ConvertType(unpacked[0], new(*big.Int)).(**big.Int)
+	return SmartContractValue{Address: address.Hex(), Value: value}
+}
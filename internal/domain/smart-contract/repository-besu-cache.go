@@ -0,0 +1,196 @@
+package smartContractDomain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"goledger-challenge-besu/configs/cache"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// headSubscriber is implemented by the backends notifying the new chain heads
+// (besuConfig.EthClient, with WebSocket endpoints).
+type headSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// CachedBackend is a Backend caching the contract calls (eth_call) by contract,
+// method, arguments and block. The result of a call at a given block never changes,
+// so the calls at the latest block are made at the head tracked by the backend:
+// their entries are left behind as soon as a new head arrives, or as soon as one of
+// our transactions is mined (its receipt moves the head forward).
+// The other Backend methods are not cached.
+type CachedBackend struct {
+	Backend
+	ctx          *context.Context
+	cache        *cacheConfig.Cache
+	pollInterval time.Duration
+
+	mu   sync.Mutex
+	head uint64 // 0 while unknown
+}
+
+// NewCachedBackend wraps a Backend with the cache.
+// Parameters:
+//   - ctx: The context of the head tracking.
+//   - backend: The Backend to cache the calls of.
+//   - cache: The cache of the calls.
+//
+// Returns:
+//   - A pointer to CachedBackend if successful.
+//   - An error if CACHE_HEAD_POLL_INTERVAL is invalid.
+func NewCachedBackend(ctx *context.Context, backend Backend, cache *cacheConfig.Cache) (*CachedBackend, error) {
+	pollInterval := time.Second
+	if env := os.Getenv("CACHE_HEAD_POLL_INTERVAL"); env != "" {
+		interval, err := time.ParseDuration(env)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid CACHE_HEAD_POLL_INTERVAL %q", env)
+		}
+		pollInterval = interval
+	}
+	return &CachedBackend{Backend: backend, ctx: ctx, cache: cache, pollInterval: pollInterval}, nil
+}
+
+// Watch starts tracking the chain head, through a new heads subscription when the
+// backend supports it, by polling the latest header otherwise.
+func (b *CachedBackend) Watch() {
+	go b.watch()
+}
+
+func (b *CachedBackend) watch() {
+	// the subscription only notifies the heads following it
+	if _, err := b.latestHead(*b.ctx, true); err != nil {
+		slog.Warn("Error reading the chain head", "error", err.Error())
+	}
+	if subscriber, ok := b.Backend.(headSubscriber); ok {
+		heads := make(chan *types.Header)
+		subscription, err := subscriber.SubscribeNewHead(*b.ctx, heads)
+		if err == nil {
+			slog.Info("Tracking the chain head for the cache through a subscription")
+			defer subscription.Unsubscribe()
+			for {
+				select {
+				case header := <-heads:
+					b.advance(header.Number.Uint64())
+				case err = <-subscription.Err():
+					slog.Warn("New heads subscription ended, polling the chain head", "error", err)
+					b.poll()
+					return
+				case <-(*b.ctx).Done():
+					return
+				}
+			}
+		}
+		slog.Info("New heads subscription unavailable, polling the chain head", "interval", b.pollInterval.String(), "error", err.Error())
+	}
+	b.poll()
+}
+
+func (b *CachedBackend) poll() {
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := b.latestHead(*b.ctx, true); err != nil {
+				slog.Warn("Error polling the chain head", "error", err.Error())
+			}
+		case <-(*b.ctx).Done():
+			return
+		}
+	}
+}
+
+// advance moves the tracked head forward.
+func (b *CachedBackend) advance(head uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.head = max(b.head, head)
+}
+
+// latestHead returns the tracked head, reading it from the backend when it is
+// still unknown or when refresh is set.
+func (b *CachedBackend) latestHead(ctx context.Context, refresh bool) (uint64, error) {
+	b.mu.Lock()
+	head := b.head
+	b.mu.Unlock()
+	if head > 0 && !refresh {
+		return head, nil
+	}
+	header, err := b.Backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	b.advance(header.Number.Uint64())
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.head, nil
+}
+
+// callKey identifies a call by contract, method (selector), arguments and block.
+// The arguments are hashed, as the multicall ones can be long.
+func callKey(msg ethereum.CallMsg, block uint64) string {
+	method, args := msg.Data, []byte{}
+	if len(msg.Data) >= 4 {
+		method, args = msg.Data[:4], msg.Data[4:]
+	}
+	return fmt.Sprintf("eth_call:%s:%s:%x:%s:%d", msg.To.Hex(), msg.From.Hex(), method, crypto.Keccak256Hash(args).Hex(), block)
+}
+
+// CallContract executes the call at the given block (the tracked head when nil),
+// from the cache when it was already made. The pending and tagged blocks (negative
+// numbers) and the calls without a target are not cached.
+func (b *CachedBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if msg.To == nil || (blockNumber != nil && blockNumber.Sign() < 0) {
+		return b.Backend.CallContract(ctx, msg, blockNumber)
+	}
+
+	latest := blockNumber == nil
+	var block uint64
+	if latest {
+		head, err := b.latestHead(ctx, false)
+		if err != nil {
+			return b.Backend.CallContract(ctx, msg, nil)
+		}
+		block = head
+	} else {
+		block = blockNumber.Uint64()
+	}
+
+	key := callKey(msg, block)
+	if output, ok := b.cache.Get(ctx, key); ok {
+		return output, nil
+	}
+	output, err := b.Backend.CallContract(ctx, msg, new(big.Int).SetUint64(block))
+	if err != nil && latest {
+		// the node serving the call may be behind the tracked head
+		return b.Backend.CallContract(ctx, msg, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.cache.Set(ctx, key, bytes.Clone(output))
+	return output, nil
+}
+
+// TransactionReceipt returns the receipt of a transaction. The block of a mined
+// transaction becomes the tracked head, so the next calls at the latest block see it.
+func (b *CachedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := b.Backend.TransactionReceipt(ctx, txHash)
+	if err == nil && receipt != nil && receipt.BlockNumber != nil {
+		b.advance(receipt.BlockNumber.Uint64())
+	}
+	return receipt, err
+}
+
+var _ Backend = (*CachedBackend)(nil)
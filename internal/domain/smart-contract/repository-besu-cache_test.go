@@ -0,0 +1,126 @@
+package smartContractDomain
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/configs/cache"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// countingClient counts the contract calls reaching the chain.
+type countingClient struct {
+	*simulatedClient
+	calls atomic.Int64
+}
+
+func (c *countingClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.calls.Add(1)
+	return c.simulatedClient.CallContract(ctx, msg, blockNumber)
+}
+
+func newCachedBackend(t *testing.T, client Backend) *CachedBackend {
+	t.Helper()
+	t.Setenv("CACHE_URL", "memory://")
+	t.Setenv("CACHE_HEAD_POLL_INTERVAL", "10ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cache, err := cacheConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend, err := NewCachedBackend(&ctx, client, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backend
+}
+
+func TestCachedBackend(t *testing.T) {
+	client := &countingClient{simulatedClient: newSimulatedClient(t)}
+	contractAddress := deploy(t, client.simulatedClient, simpleStorageBytecode(t))
+	backend := newCachedBackend(t, client)
+	repository := newRepository(t, backend, contractAddress)
+
+	for range 3 {
+		if value, err := repository.GetValue(context.Background()); err != nil || value.Sign() != 0 {
+			t.Fatalf("GetValue() = %v, %v, want 0", value, err)
+		}
+	}
+	if equal, err := repository.CheckValue(context.Background(), big.NewInt(0)); err != nil || !equal {
+		t.Fatalf("CheckValue(0) = %v, %v, want true", equal, err)
+	}
+	if calls := client.calls.Load(); calls != 1 {
+		t.Errorf("%d calls reached the chain, want 1 (cached)", calls)
+	}
+
+	// our own write moves the head to its block, the next reads see it
+	if err := repository.SetValue(context.Background(), big.NewInt(42), aliceKey); err != nil {
+		t.Fatal(err)
+	}
+	calls := client.calls.Load()
+	if value, err := repository.GetValue(context.Background()); err != nil || value.Int64() != 42 {
+		t.Fatalf("GetValue() after SetValue = %v, %v, want 42", value, err)
+	}
+	repository.GetValue(context.Background())
+	if got := client.calls.Load() - calls; got != 1 {
+		t.Errorf("%d calls reached the chain after the write, want 1", got)
+	}
+
+	// the calls at a given block stay cached, whatever the head
+	header, _ := client.HeaderByNumber(context.Background(), nil)
+	first, err := repository.GetLatestValue(context.Background())
+	if err != nil || first.Value.Int64() != 42 || first.BlockNumber != header.Number.Uint64() {
+		t.Fatalf("GetLatestValue() = %+v, %v, want 42 at block %d", first, err, header.Number)
+	}
+
+	// a write made by someone else is seen once its block is the tracked head
+	other := newRepository(t, client.simulatedClient, contractAddress)
+	if err = other.SetValue(context.Background(), big.NewInt(7), bobKey); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := repository.GetValue(context.Background()); value.Int64() != 42 {
+		t.Errorf("GetValue() = %v before the new head, want the cached 42", value)
+	}
+	backend.Watch()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		value, err := repository.GetValue(context.Background())
+		if err == nil && value.Int64() == 7 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetValue() = %v, %v after the new head, want 7", value, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if stats := backend.cache.Stats(); stats.Hits == 0 || stats.Misses == 0 || stats.Errors != 0 {
+		t.Errorf("Stats() = %+v, want hits and misses", stats)
+	}
+}
+
+func TestCachedBackendErrors(t *testing.T) {
+	client := &countingClient{simulatedClient: newSimulatedClient(t)}
+	repository := newRepository(t, newCachedBackend(t, client), deploy(t, client.simulatedClient, revertingBytecode))
+
+	// reverted calls are not cached
+	for range 2 {
+		if _, err := repository.GetValue(context.Background()); err == nil {
+			t.Fatal("GetValue() of a reverting contract, want an error")
+		}
+	}
+	if calls := client.calls.Load(); calls < 2 {
+		t.Errorf("%d calls reached the chain, want the failed calls repeated", calls)
+	}
+
+	t.Setenv("CACHE_HEAD_POLL_INTERVAL", "soon")
+	ctx := context.Background()
+	if _, err := NewCachedBackend(&ctx, client, nil); err == nil {
+		t.Error("NewCachedBackend() with an invalid CACHE_HEAD_POLL_INTERVAL, want an error")
+	}
+}
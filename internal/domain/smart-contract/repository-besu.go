@@ -2,57 +2,58 @@ package smartContractDomain
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"math/big"
 	"os"
-	"strings"
+	"time"
 
 	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/configs/tracing"
 	"goledger-challenge-besu/internal/domain"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// SmartContractRepositoryBesu reads and writes the smart contract on the Besu nodes.
+// Its calls are canceled by the context of the repository only, the context of
+// each call holds the span they are traced under (see tracingConfig.Join).
 type SmartContractRepositoryBesu struct {
 	ctx           *context.Context
 	abi           *abi.ABI
 	boundContract *bind.BoundContract
 	address       common.Address
-	client        *besuConfig.EthClient
+	client        Backend
+	multicall     *multicall
+
+	waitInterval time.Duration // between the receipt polls of WaitTransaction
 }
 
+// dropAfter is the number of receipt polls in a row the nodes must not know a
+// transaction for, before it is considered dropped (evicted from the pools or
+// replaced), leaving the time for it to propagate to every node.
+const dropAfter = 5
+
 // NewRepositoryBesu initializes a new instance of SmartContractRepositoryBesu.
 // Parameters:
 //   - ctx: The context for contract operations.
-//   - client: The Ethereum client configuration.
+//   - client: The Ethereum client (the Besu node pool, or any other Backend).
 //
 // Returns:
 //   - A pointer to SmartContractRepositoryBesu if successful.
-//   - An error if there is an issue with the ABI or contract address.
-func NewRepositoryBesu(ctx *context.Context, client *besuConfig.EthClient) (*SmartContractRepositoryBesu, error) {
-	abiPath := os.Getenv("SMART_CONTRACT_ABI_PATH")
-	data, err := os.ReadFile(abiPath)
-	if err != nil {
-		slog.Error("Error reading contract abi file", "path", abiPath, "error", err.Error())
-		return nil, err
-	}
-	var contractDefinition struct {
-		Abi any `json:"abi"`
-	}
-	json.Unmarshal(data, &contractDefinition)
-	data, err = json.Marshal(contractDefinition.Abi)
-	if err != nil {
-		slog.Error("Error getting contract abi structure from abi file", "error", err.Error())
-		return nil, err
-	}
-	abi, err := abi.JSON(strings.NewReader(string(data)))
+//   - An error if there is an issue with the ABI, contract address or multicall address.
+func NewRepositoryBesu(ctx *context.Context, client Backend) (*SmartContractRepositoryBesu, error) {
+	abi, err := besuConfig.ReadABI(os.Getenv("SMART_CONTRACT_ABI_PATH"))
 	if err != nil {
-		slog.Error("Error converting contract abi json to string", "error", err.Error())
 		return nil, err
 	}
 
@@ -65,93 +66,284 @@ func NewRepositoryBesu(ctx *context.Context, client *besuConfig.EthClient) (*Sma
 
 	boundContract := bind.NewBoundContract(
 		contractAddress,
-		abi,
+		*abi,
 		client,
 		client,
 		client,
 	)
 
+	multicall, err := newMulticall(client)
+	if err != nil {
+		return nil, err
+	}
+
 	return &SmartContractRepositoryBesu{
 		ctx:           ctx,
-		abi:           &abi,
+		abi:           abi,
 		boundContract: boundContract,
 		address:       contractAddress,
 		client:        client,
+		multicall:     multicall,
+		waitInterval:  time.Second,
 	}, nil
 }
 
+// besuError maps a failure of the Besu node pool to domain.ErrNodeUnavailable,
+// so requests fail fast while every node is down. Other errors become domainErr.
+func besuError(err error, domainErr error) error {
+	if errors.Is(err, besuConfig.ErrNoHealthyNode) {
+		return domain.ErrNodeUnavailable
+	}
+	return domainErr
+}
+
+// call calls a method of a bound contract in a span, at the block of opts.
+func call(ctx context.Context, contract *bind.BoundContract, address common.Address, opts *bind.CallOpts, output *[]any, method string, args ...any) error {
+	attrs := []attribute.KeyValue{attribute.String("contract.address", address.Hex())}
+	if opts.BlockNumber != nil {
+		attrs = append(attrs, attribute.Int64("block.number", opts.BlockNumber.Int64()))
+	}
+	ctx, span := tracingConfig.Start(ctx, "BoundContract.Call "+method, attrs...)
+	opts.Context = ctx
+	err := contract.Call(opts, output, method, args...)
+	tracingConfig.End(span, err)
+	return err
+}
+
 // GetValue retrieves the current value stored in the smart contract.
+// Parameters:
+//   - ctx: The context of the caller, holding its span.
+//
 // Returns:
 //   - A pointer to a big.Int containing the value.
 //   - An error if the call to the bound contract fails.
-func (r *SmartContractRepositoryBesu) GetValue() (*big.Int, error) {
+func (r *SmartContractRepositoryBesu) GetValue(ctx context.Context) (*big.Int, error) {
+	ctx = tracingConfig.Join(*r.ctx, ctx)
 	caller := bind.CallOpts{
 		Pending: false,
-		Context: *r.ctx,
 	}
 	var output []any
-	err := r.boundContract.Call(&caller, &output, "get")
+	err := call(ctx, r.boundContract, r.address, &caller, &output, "get")
 	if err != nil {
-		slog.Error("Error calling contract (bound contract)", "options", caller, "error", err.Error())
-		return new(big.Int), domain.ErrBoundContractCall
+		slog.ErrorContext(ctx, "Error calling contract (bound contract)", "block", caller.BlockNumber, "error", err.Error())
+		return new(big.Int), besuError(err, domain.ErrBoundContractCall)
 	}
 	result := *abi.ConvertType(output[0], new(*big.Int)).(**big.Int)
 	return result, nil
 }
 
-// SetValue sets a new value in the smart contract.
+// GetLatestValue retrieves the value stored in the smart contract at the latest block,
+// along with that block, so the value can be ordered against other reads.
+// The block is read through the rpc (not the go-ethereum header) because the hash
+// of a QBFT block computed by go-ethereum doesn't match Besu's.
 // Parameters:
+//   - ctx: The context of the caller, holding its span.
+//
+// Returns:
+//   - A pointer to the BlockValue.
+//   - An error if the block can't be read or the call to the bound contract fails.
+func (r *SmartContractRepositoryBesu) GetLatestValue(ctx context.Context) (*BlockValue, error) {
+	ctx = tracingConfig.Join(*r.ctx, ctx)
+	var block struct {
+		Number hexutil.Uint64 `json:"number"`
+		Hash   common.Hash    `json:"hash"`
+	}
+	batch := []rpc.BatchElem{{Method: "eth_getBlockByNumber", Args: []any{"latest", false}, Result: &block}}
+	err := r.client.BatchCallContext(ctx, batch)
+	if err == nil {
+		err = batch[0].Error
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting latest block from eth client", "error", err.Error())
+		return nil, besuError(err, domain.ErrBoundContractCall)
+	}
+
+	caller := bind.CallOpts{
+		Pending:     false,
+		BlockNumber: new(big.Int).SetUint64(uint64(block.Number)),
+	}
+	var output []any
+	err = call(ctx, r.boundContract, r.address, &caller, &output, "get")
+	if err != nil {
+		slog.ErrorContext(ctx, "Error calling contract (bound contract)", "block", caller.BlockNumber, "error", err.Error())
+		return nil, besuError(err, domain.ErrBoundContractCall)
+	}
+	return &BlockValue{
+		Value:       *abi.ConvertType(output[0], new(*big.Int)).(**big.Int),
+		BlockNumber: uint64(block.Number),
+		BlockHash:   block.Hash.Hex(),
+	}, nil
+}
+
+// SignerAddress derives the address of the account that signs with the given private key.
+// Parameters:
+//   - privateKey: A string representing the private key (hex) of the signer.
+//
+// Returns:
+//   - The address of the signer.
+//   - domain.ErrUnauthorized if the private key is invalid.
+func SignerAddress(privateKey string) (common.Address, error) {
+	privateKeyECDSA, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		slog.Error("Error converting private key hex format to ECDSA format", "error", err.Error())
+		return common.Address{}, domain.ErrUnauthorized
+	}
+	return crypto.PubkeyToAddress(privateKeyECDSA.PublicKey), nil
+}
+
+// SendValue sends a transaction setting a new value in the smart contract, without
+// waiting for it to be mined (see WaitTransaction).
+// Parameters:
+//   - ctx: The context of the caller, holding its span.
 //   - value: A pointer to a big.Int containing the value to set.
 //   - privateKey: A string representing the private key for transaction authorization.
 //
 // Returns:
+//   - The SentTransaction, pending.
 //   - An error if the chain ID retrieval, private key parsing, or transaction execution fails.
-func (r *SmartContractRepositoryBesu) SetValue(value *big.Int, privateKey string) error {
-	chainId, err := r.client.ChainID(*r.ctx)
+func (r *SmartContractRepositoryBesu) SendValue(ctx context.Context, value *big.Int, privateKey string) (*SentTransaction, error) {
+	ctx = tracingConfig.Join(*r.ctx, ctx)
+	chainId, err := r.client.ChainID(ctx)
 	if err != nil {
-		slog.Error("Error getting chain from eth client", "error", err.Error())
-		return domain.ErrInvalidChain
+		slog.ErrorContext(ctx, "Error getting chain from eth client", "error", err.Error())
+		return nil, besuError(err, domain.ErrInvalidChain)
 	}
 
 	privateKeyECDSA, err := crypto.HexToECDSA(privateKey)
 	if err != nil {
-		slog.Error("Error converting private key hex format to ECDSA format", "error", err.Error())
-		return domain.ErrUnauthorized
+		slog.ErrorContext(ctx, "Error converting private key hex format to ECDSA format", "error", err.Error())
+		return nil, domain.ErrUnauthorized
 	}
 
 	auth, err := bind.NewKeyedTransactorWithChainID(privateKeyECDSA, chainId)
 	if err != nil {
-		slog.Error("Error getting auth opts to transact bound contract", "error", err.Error())
-		return domain.ErrUnauthorized
+		slog.ErrorContext(ctx, "Error getting auth opts to transact bound contract", "error", err.Error())
+		return nil, domain.ErrUnauthorized
 	}
 
+	// the nonce, gas and fee lookups of the transactor are traced under the span
+	ctx, span := tracingConfig.Start(ctx, "BoundContract.Transact set",
+		attribute.String("contract.address", r.address.Hex()), attribute.String("tx.signer", auth.From.Hex()))
+	auth.Context = ctx
 	tx, err := r.boundContract.Transact(auth, "set", value)
+	if err == nil {
+		span.SetAttributes(attribute.String("tx.hash", tx.Hash().Hex()), attribute.Int64("tx.nonce", int64(tx.Nonce())))
+	}
+	tracingConfig.End(span, err)
 	if err != nil {
-		slog.Error("Error executing transaction in contract (bound contract)", "options", auth, "error", err.Error())
-		return domain.ErrBoundContractTransact
+		slog.ErrorContext(ctx, "Error executing transaction in contract (bound contract)", "signer", auth.From.Hex(), "error", err.Error())
+		return nil, besuError(err, domain.ErrBoundContractTransact)
 	}
 
-	tx.Hash().Hex()
+	return &SentTransaction{
+		Hash:     tx.Hash().Hex(),
+		Signer:   auth.From.Hex(),
+		Contract: r.address.Hex(),
+		Nonce:    tx.Nonce(),
+		Method:   fmt.Sprintf("set(%s)", value),
+		State:    TransactionPending,
+	}, nil
+}
 
-	if _, err = bind.WaitMined(*r.ctx, r.client, tx); err != nil {
-		slog.Error("Error waiting to be mined", "error", err.Error())
-		return domain.ErrBoundContractTransact
+// WaitTransaction waits for a transaction to be mined, polling its receipt until the
+// context of the repository is canceled, in a WaitMined span.
+// Parameters:
+//   - ctx: The context of the caller, holding its span.
+//   - hash: The hash of the transaction.
+//
+// Returns:
+//   - TransactionMined, or TransactionReverted when it was mined but failed, and its block;
+//     TransactionDropped when the nodes no longer know the transaction.
+//   - An error if the wait is canceled; the transaction may still be mined.
+func (r *SmartContractRepositoryBesu) WaitTransaction(ctx context.Context, hash string) (state TransactionState, block uint64, err error) {
+	ctx, span := tracingConfig.Start(tracingConfig.Join(*r.ctx, ctx), "WaitMined", attribute.String("tx.hash", hash))
+	defer func() {
+		span.SetAttributes(attribute.String("tx.state", string(state)), attribute.Int64("block.number", int64(block)))
+		tracingConfig.End(span, err)
+	}()
+	return r.waitTransaction(ctx, hash)
+}
+
+func (r *SmartContractRepositoryBesu) waitTransaction(ctx context.Context, hash string) (TransactionState, uint64, error) {
+	txHash := common.HexToHash(hash)
+	ticker := time.NewTicker(r.waitInterval)
+	defer ticker.Stop()
+	misses := 0
+	for {
+		receipt, err := r.client.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			block := receipt.BlockNumber.Uint64()
+			// a mined transaction may still have failed, the value is only set on success
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				slog.ErrorContext(ctx, "Transaction reverted in contract", "hash", hash, "block", block)
+				return TransactionReverted, block, nil
+			}
+			return TransactionMined, block, nil
+		}
+		if errors.Is(err, ethereum.NotFound) {
+			// not mined yet, but still known as long as it is in the pool of a node
+			if _, _, err = r.client.TransactionByHash(ctx, txHash); errors.Is(err, ethereum.NotFound) {
+				if misses++; misses >= dropAfter {
+					slog.ErrorContext(ctx, "Transaction dropped by the nodes", "hash", hash)
+					return TransactionDropped, 0, nil
+				}
+			} else if err == nil {
+				misses = 0
+			}
+		}
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			slog.WarnContext(ctx, "Error reading transaction receipt, retrying", "hash", hash, "error", err.Error())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			slog.ErrorContext(ctx, "Error waiting to be mined", "hash", hash, "error", ctx.Err().Error())
+			return TransactionPending, 0, besuError(ctx.Err(), domain.ErrBoundContractTransact)
+		}
 	}
+}
 
+// SetValue sets a new value in the smart contract, sending the transaction and
+// waiting for it to be mined.
+// Parameters:
+//   - ctx: The context of the caller, holding its span.
+//   - value: A pointer to a big.Int containing the value to set.
+//   - privateKey: A string representing the private key for transaction authorization.
+//
+// Returns:
+//   - An error if the chain ID retrieval, private key parsing, or transaction execution fails,
+//     domain.ErrTransactionReverted if the transaction was mined but reverted.
+func (r *SmartContractRepositoryBesu) SetValue(ctx context.Context, value *big.Int, privateKey string) error {
+	tx, err := r.SendValue(ctx, value, privateKey)
+	if err != nil {
+		return err
+	}
+	state, _, err := r.WaitTransaction(ctx, tx.Hash)
+	if err != nil {
+		return err
+	}
+	switch state {
+	case TransactionReverted:
+		return domain.ErrTransactionReverted
+	case TransactionDropped:
+		return domain.ErrTransactionDropped
+	}
 	return nil
 }
 
 // CheckValue verifies if the given value matches the value stored in the smart contract.
 // Parameters:
+//   - ctx: The context of the caller, holding its span.
 //   - value: A pointer to a big.Int containing the value to check.
 //
 // Returns:
 //   - A boolean indicating if the values match.
 //   - An error if retrieving the current value from the smart contract fails.
-func (r *SmartContractRepositoryBesu) CheckValue(value *big.Int) (bool, error) {
-	correctValue, err := r.GetValue()
+func (r *SmartContractRepositoryBesu) CheckValue(ctx context.Context, value *big.Int) (bool, error) {
+	correctValue, err := r.GetValue(ctx)
 	if err != nil {
-		slog.Error("Error contract value in SmartContractRepositoryBesu.CheckValue", "error", err.Error())
+		slog.ErrorContext(ctx, "Error contract value in SmartContractRepositoryBesu.CheckValue", "error", err.Error())
 		return false, err
 	}
 	return correctValue.Cmp(value) == 0, nil
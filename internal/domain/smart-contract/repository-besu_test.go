@@ -0,0 +1,328 @@
+package smartContractDomain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/configs/besu"
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	abiPath = "testdata/SimpleStorage.json"
+
+	// genesis accounts of scripts/besu/config/qbftConfigFile.json
+	aliceKey = "8f2a55949038a9610f50fb23b5883af3b4ecb3c3bb792cbcefbd1542c692be63"
+	bobKey   = "ae6ae8e5ccbfb04590405997ee2d52d2b330726137b875053c36d94e974d162f"
+
+	// revertingBytecode deploys a contract whose runtime always reverts:
+	// PUSH1 0 PUSH1 0 REVERT.
+	revertingBytecode = "0x600580600b6000396000f360006000fd"
+
+	// oddBlockBytecode deploys SimpleStorage with set(uint256) reverting when
+	// block.number is odd (NUMBER PUSH1 1 AND PUSH1 <revert> JUMPI before the SSTORE),
+	// so a transaction estimated on an even block is mined, and reverts, on the next one.
+	oddBlockBytecode = "0x605080600b6000396000f36004361060215760003560e01c806360fe47b11460265780636d4ce63c146040575b600080fd5b346021576024361060215743600116602157600435600055005b3460215760005460005260206000f3"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// simulatedClient adapts the go-ethereum simulated backend to Backend. Every sent
+// transaction is sealed right away in its own block, like the instant sealing of a
+// dev node, so bind.WaitMined returns without polling. chainID, when set, replaces
+// the chain ID reported to the repository.
+type simulatedClient struct {
+	simulated.Client
+	backend *simulated.Backend
+	rpc     *rpc.Client
+	chainID *big.Int
+}
+
+func (c *simulatedClient) ChainID(ctx context.Context) (*big.Int, error) {
+	if c.chainID != nil {
+		return c.chainID, nil
+	}
+	return c.Client.ChainID(ctx)
+}
+
+func (c *simulatedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := c.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	c.backend.Commit()
+	return nil
+}
+
+func (c *simulatedClient) BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error {
+	return c.rpc.BatchCallContext(ctx, batch)
+}
+
+// newSimulatedClient starts an offline chain (chain ID 1337) funding Alice and Bob.
+func newSimulatedClient(t *testing.T) *simulatedClient {
+	t.Helper()
+	alloc := types.GenesisAlloc{}
+	for _, key := range []string{aliceKey, bobKey} {
+		alloc[address(t, key)] = types.Account{Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))}
+	}
+	backend := simulated.NewBackend(alloc)
+	t.Cleanup(func() { backend.Close() })
+
+	client := backend.Client()
+	// the simulated client wraps an ethclient.Client in its exported Client field,
+	// which shadows the method exposing the raw rpc client needed by the batches
+	rpcClient := reflect.ValueOf(client).FieldByName("Client").Interface().(*ethclient.Client).Client()
+	return &simulatedClient{Client: client, backend: backend, rpc: rpcClient}
+}
+
+func address(t *testing.T, key string) common.Address {
+	t.Helper()
+	signer, err := SignerAddress(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// deploy deploys the bytecode with Alice's key and returns the contract address.
+func deploy(t *testing.T, client *simulatedClient, bytecode string) common.Address {
+	t.Helper()
+	ctx := context.Background()
+	privateKey, _ := crypto.HexToECDSA(aliceKey)
+	chainID, _ := client.Client.ChainID(ctx)
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractABI, err := besuConfig.ReadABI(abiPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractAddress, tx, _, err := bind.DeployContract(auth, *contractABI, hexutil.MustDecode(bytecode), client)
+	if err != nil {
+		t.Fatalf("deploying contract: %v", err)
+	}
+	if _, err = bind.WaitDeployed(ctx, client, tx); err != nil {
+		t.Fatalf("waiting contract deployment: %v", err)
+	}
+	return contractAddress
+}
+
+// simpleStorageBytecode reads the creation bytecode of the SimpleStorage artifact.
+func simpleStorageBytecode(t *testing.T) string {
+	t.Helper()
+	artifact, err := os.ReadFile(abiPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const key = `"bytecode": "`
+	start := strings.Index(string(artifact), key) + len(key)
+	end := strings.Index(string(artifact)[start:], `"`)
+	return string(artifact)[start : start+end]
+}
+
+func newRepository(t *testing.T, client Backend, contractAddress common.Address) *SmartContractRepositoryBesu {
+	t.Helper()
+	t.Setenv("SMART_CONTRACT_ABI_PATH", abiPath)
+	t.Setenv("SMART_CONTRACT_ADDR", contractAddress.Hex())
+	t.Setenv("MULTICALL_ADDR", "") // not deployed on the simulated chain, reads fall back to rpc batches
+	ctx := context.Background()
+	repository, err := NewRepositoryBesu(&ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository
+}
+
+func TestRepositoryBesuGetSetCheck(t *testing.T) {
+	client := newSimulatedClient(t)
+	repository := newRepository(t, client, deploy(t, client, simpleStorageBytecode(t)))
+
+	value, err := repository.GetValue(context.Background())
+	if err != nil || value.Sign() != 0 {
+		t.Fatalf("GetValue() = %v, %v, want 0", value, err)
+	}
+
+	maxUint64PlusOne := new(big.Int).Add(new(big.Int).SetUint64(^uint64(0)), big.NewInt(1))
+	for _, want := range []*big.Int{big.NewInt(42), maxUint64PlusOne, math.MaxBig256, big.NewInt(0)} {
+		if err = repository.SetValue(context.Background(), want, aliceKey); err != nil {
+			t.Fatalf("SetValue(%v) error = %v", want, err)
+		}
+		value, err = repository.GetValue(context.Background())
+		if err != nil || value.Cmp(want) != 0 {
+			t.Fatalf("GetValue() = %v, %v, want %v", value, err, want)
+		}
+		if equal, err := repository.CheckValue(context.Background(), want); err != nil || !equal {
+			t.Errorf("CheckValue(%v) = %v, %v, want true", want, equal, err)
+		}
+		if equal, err := repository.CheckValue(context.Background(), new(big.Int).Add(want, big.NewInt(1))); err != nil || equal {
+			t.Errorf("CheckValue(%v + 1) = %v, %v, want false", want, equal, err)
+		}
+	}
+
+	// any funded key can set the value
+	if err = repository.SetValue(context.Background(), big.NewInt(7), bobKey); err != nil {
+		t.Fatalf("SetValue() with Bob's key error = %v", err)
+	}
+	if value, _ = repository.GetValue(context.Background()); value.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("GetValue() = %v, want 7", value)
+	}
+}
+
+func TestRepositoryBesuGetLatestValue(t *testing.T) {
+	client := newSimulatedClient(t)
+	repository := newRepository(t, client, deploy(t, client, simpleStorageBytecode(t)))
+
+	for _, want := range []*big.Int{big.NewInt(42), math.MaxBig256} {
+		if err := repository.SetValue(context.Background(), want, aliceKey); err != nil {
+			t.Fatalf("SetValue(%v) error = %v", want, err)
+		}
+		header, err := client.HeaderByNumber(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := repository.GetLatestValue(context.Background())
+		if err != nil {
+			t.Fatalf("GetLatestValue() error = %v", err)
+		}
+		if value.Value.Cmp(want) != 0 || value.BlockNumber != header.Number.Uint64() || value.BlockHash != header.Hash().Hex() {
+			t.Errorf("GetLatestValue() = %v at %d %s, want %v at %d %s", value.Value, value.BlockNumber, value.BlockHash, want, header.Number, header.Hash().Hex())
+		}
+	}
+}
+
+func TestRepositoryBesuGetValues(t *testing.T) {
+	client := newSimulatedClient(t)
+	first := deploy(t, client, simpleStorageBytecode(t))
+	second := deploy(t, client, simpleStorageBytecode(t))
+	if err := newRepository(t, client, second).SetValue(context.Background(), big.NewInt(99), aliceKey); err != nil {
+		t.Fatal(err)
+	}
+	setBlock, _ := client.BlockNumber(context.Background())
+	if err := newRepository(t, client, second).SetValue(context.Background(), big.NewInt(100), aliceKey); err != nil {
+		t.Fatal(err)
+	}
+	repository := newRepository(t, client, first)
+	eoa := address(t, bobKey)
+
+	values, err := repository.GetValues(context.Background(), []common.Address{first, second, eoa}, rpc.LatestBlockNumber)
+	if err != nil {
+		t.Fatalf("GetValues() error = %v", err)
+	}
+	if values[0].Value.Sign() != 0 || values[1].Value.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("GetValues() = %+v, want 0 and 100", values)
+	}
+	if values[2].Error == "" || values[2].Value != nil {
+		t.Errorf("GetValues() of an account without code = %+v, want an error", values[2])
+	}
+
+	values, err = repository.GetValues(context.Background(), []common.Address{second}, rpc.BlockNumber(setBlock))
+	if err != nil || values[0].Value.Cmp(big.NewInt(99)) != 0 {
+		t.Errorf("GetValues() at block %d = %+v, %v, want 99", setBlock, values, err)
+	}
+
+	// Multicall3 deployed after the block read, which has no code at its address
+	t.Setenv("MULTICALL_ADDR", deploy(t, client, simpleStorageBytecode(t)).Hex())
+	if repository.multicall, err = newMulticall(client); err != nil {
+		t.Fatal(err)
+	}
+	values, err = repository.GetValues(context.Background(), []common.Address{second}, rpc.BlockNumber(setBlock))
+	if err != nil || values[0].Value.Cmp(big.NewInt(99)) != 0 {
+		t.Errorf("GetValues() at block %d, before Multicall3 = %+v, %v, want 99", setBlock, values, err)
+	}
+}
+
+func TestRepositoryBesuSetValueFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		bytecode   string
+		privateKey string
+		chainID    *big.Int
+		evenBlock  bool // seal blocks until the latest one is even before sending
+		wantErr    error
+	}{
+		{name: "invalid private key", privateKey: "zz", wantErr: domain.ErrUnauthorized},
+		{name: "unfunded signer", privateKey: "0000000000000000000000000000000000000000000000000000000000000001", wantErr: domain.ErrBoundContractTransact},
+		{name: "wrong chain ID", privateKey: aliceKey, chainID: big.NewInt(1338), wantErr: domain.ErrBoundContractTransact},
+		{name: "reverted on gas estimation", bytecode: revertingBytecode, privateKey: aliceKey, wantErr: domain.ErrBoundContractTransact},
+		{name: "reverted once mined", bytecode: oddBlockBytecode, privateKey: aliceKey, evenBlock: true, wantErr: domain.ErrTransactionReverted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newSimulatedClient(t)
+			bytecode := tt.bytecode
+			if bytecode == "" {
+				bytecode = simpleStorageBytecode(t)
+			}
+			contractAddress := deploy(t, client, bytecode)
+			for tt.evenBlock {
+				head, _ := client.BlockNumber(context.Background())
+				if head%2 == 0 {
+					break
+				}
+				client.backend.Commit()
+			}
+			client.chainID = tt.chainID
+			repository := newRepository(t, client, contractAddress)
+
+			err := repository.SetValue(context.Background(), big.NewInt(42), tt.privateKey)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetValue() error = %v, want %v", err, tt.wantErr)
+			}
+			client.chainID = nil
+			if tt.bytecode == revertingBytecode {
+				return
+			}
+			if value, err := repository.GetValue(context.Background()); err != nil || value.Sign() != 0 {
+				t.Errorf("GetValue() = %v, %v, want the value unchanged", value, err)
+			}
+		})
+	}
+}
+
+func TestRepositoryBesuGetValueReverted(t *testing.T) {
+	client := newSimulatedClient(t)
+	repository := newRepository(t, client, deploy(t, client, revertingBytecode))
+
+	if _, err := repository.GetValue(context.Background()); !errors.Is(err, domain.ErrBoundContractCall) {
+		t.Errorf("GetValue() error = %v, want %v", err, domain.ErrBoundContractCall)
+	}
+	if _, err := repository.CheckValue(context.Background(), big.NewInt(0)); !errors.Is(err, domain.ErrBoundContractCall) {
+		t.Errorf("CheckValue() error = %v, want %v", err, domain.ErrBoundContractCall)
+	}
+}
+
+func TestRepositoryBesuWaitTransactionDropped(t *testing.T) {
+	client := newSimulatedClient(t)
+	repository := newRepository(t, client, deploy(t, client, simpleStorageBytecode(t)))
+	repository.waitInterval = time.Millisecond
+
+	// a hash unknown to the node, as the one of a transaction evicted from its pool
+	state, block, err := repository.WaitTransaction(context.Background(), common.HexToHash("0x01").Hex())
+
+	if err != nil || state != TransactionDropped || block != 0 {
+		t.Errorf("WaitTransaction() = %v, %v, %v, want %v", state, block, err, TransactionDropped)
+	}
+}
@@ -4,24 +4,31 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"math/big"
 	"os"
-	"time"
+	"strings"
 
 	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/configs/tracing"
 	"goledger-challenge-besu/internal/domain"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// SmartContractRepositoryDB stores the smart contract values and the transactions
+// sent in the database. Like SmartContractRepositoryBesu, its statements are canceled
+// by the context of the repository only, and traced under the span of the caller.
 type SmartContractRepositoryDB struct {
-	ctx           *context.Context
-	db            *dbConfig.DB
-	SmartContract *SmartContractDB // 'singleton' instantiated here because in this scope the contract is a fixed instance
+	ctx     *context.Context
+	db      *dbConfig.DB
+	address string // the contract is a fixed instance in this scope
 }
 
 // NewRepositoryDB initializes a new instance of SmartContractRepositoryDB.
+// The repository holds no mutable state, it is safe for concurrent use.
 // Parameters:
 //   - ctx: The context for database operations.
 //   - db: The database configuration to use.
@@ -37,71 +44,218 @@ func NewRepositoryDB(ctx *context.Context, db *dbConfig.DB) (*SmartContractRepos
 	}
 
 	return &SmartContractRepositoryDB{
-		ctx: ctx,
-		db:  db,
-		SmartContract: &SmartContractDB{
-			Address:   contractHexAddress,
-			Value:     *new(uint64),
-			CreatedAt: *new(time.Time),
-			UpdatedAt: *new(time.Time),
-		},
+		ctx:     ctx,
+		db:      db,
+		address: contractHexAddress,
 	}, nil
 }
 
-// SyncValue synchronizes the smart contract's data with the database.
-// It checks whether the smart contract's address already exists in the database.
-// If it does, the value is updated; otherwise, a new entry is created.
+// numeric converts an integer to the NUMERIC(78, 0) of the value column,
+// which holds any uint256 (pgx has no direct big.Int mapping).
+func numeric(value *big.Int) pgtype.Numeric {
+	return pgtype.Numeric{Int: new(big.Int).Set(value), Valid: true}
+}
+
+// numericInt converts a NUMERIC without fractional part back to an integer.
+func numericInt(value pgtype.Numeric) *big.Int {
+	result := new(big.Int).Set(value.Int)
+	if value.Exp > 0 {
+		result.Mul(result, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(value.Exp)), nil))
+	}
+	return result
+}
+
+// smartContractColumns are the columns returned by the sync statements.
+var smartContractColumns = []string{"smart_contract_id", "address", "value", "block_number", "block_hash", "created_at", "updated_at"}
+
+// SyncValue stores the value of the smart contract read at a block, with a single
+// INSERT ... ON CONFLICT (address) DO UPDATE statement, so concurrent syncs don't
+// race between a read and a write. The update only applies when the block is newer
+// than the stored one: a stale sync never overwrites a newer value.
+// Parameters:
+//   - ctx: The context of the caller, holding its span.
+//   - value: The BlockValue read from the chain.
+//
 // Returns:
+//   - The SyncResult with the stored row, and whether this sync changed it.
 //   - An error if any database operation fails or if there is conflicting data.
-func (r *SmartContractRepositoryDB) SyncValue() error {
-	alreadyExists := true
-
-	getQuery := r.db.QueryBuilder.Select("smart_contract_id").From("smart_contracts").Where(sq.Eq{"address": r.SmartContract.Address}).Limit(1)
-	sql, args, err := getQuery.ToSql()
+func (r *SmartContractRepositoryDB) SyncValue(ctx context.Context, value BlockValue) (*SyncResult, error) {
+	ctx = tracingConfig.Join(*r.ctx, ctx)
+	query := r.db.QueryBuilder.Insert("smart_contracts").
+		Columns("address", "value", "block_number", "block_hash").
+		Values(r.address, numeric(value.Value), value.BlockNumber, value.BlockHash).
+		Suffix("ON CONFLICT (address) DO UPDATE SET value = EXCLUDED.value, block_number = EXCLUDED.block_number, block_hash = EXCLUDED.block_hash, updated_at = CURRENT_TIMESTAMP " +
+			"WHERE smart_contracts.block_number < EXCLUDED.block_number RETURNING " + strings.Join(smartContractColumns, ", "))
+	sql, args, err := query.ToSql()
 	if err != nil {
-		slog.Error("Error generating query sql to get smart contract from db", "error", err.Error())
-		return domain.ErrInvalidSQL
-	}
-	err = r.db.QueryRow(*r.ctx, sql, args...).Scan(
-		&r.SmartContract.SmartContractId,
-	)
-	if err == pgx.ErrNoRows {
-		alreadyExists = false
-	} else if err != nil {
-		slog.Error("Error getting smart contract from db", "sql", sql, "error", err.Error())
-		return domain.ErrInternal
+		slog.ErrorContext(ctx, "Error generating query sql to upsert smart contract on db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
 	}
 
-	if alreadyExists {
-		query := r.db.QueryBuilder.Update("smart_contracts").Set("value", r.SmartContract.Value).Where(sq.Eq{"smart_contract_id": r.SmartContract.SmartContractId}).Suffix("RETURNING *")
-		sql, args, err = query.ToSql()
+	changed := true
+	smartContract, err := r.scanSmartContract(r.db.QueryRow(ctx, sql, args...))
+	if err == pgx.ErrNoRows {
+		// the guard skipped the update, the stored block is the same or newer
+		changed = false
+		getQuery := r.db.QueryBuilder.Select(smartContractColumns...).From("smart_contracts").Where(sq.Eq{"address": r.address})
+		sql, args, err = getQuery.ToSql()
 		if err != nil {
-			slog.Error("Error generating query sql to update smart contract in db", "error", err.Error())
-			return domain.ErrInvalidSQL
+			slog.ErrorContext(ctx, "Error generating query sql to get smart contract from db", "error", err.Error())
+			return nil, domain.ErrInvalidSQL
 		}
-	} else {
-		query := r.db.QueryBuilder.Insert("smart_contracts").Columns("address", "value").Values(r.SmartContract.Address, r.SmartContract.Value).Suffix("RETURNING *")
-		sql, args, err = query.ToSql()
-		if err != nil {
-			slog.Error("Error generating query sql to insert smart contract on db", "error", err.Error())
-			return domain.ErrInvalidSQL
+		smartContract, err = r.scanSmartContract(r.db.QueryRow(ctx, sql, args...))
+	}
+	if err != nil {
+		if errCode := r.db.ErrorCode(err); errCode == "23505" {
+			slog.ErrorContext(ctx, "Error creating or updating smart contract on db. Conflicts with columns requirements", "sql", sql, "error", err.Error())
+			return nil, domain.ErrConflictingData
 		}
+		slog.ErrorContext(ctx, "Error creating or updating smart contract on db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
 	}
+	if !changed {
+		slog.InfoContext(ctx, "Smart contract sync skipped, the db holds the same or a newer block", "block", value.BlockNumber, "stored_block", smartContract.BlockNumber)
+	}
+
+	return &SyncResult{
+		Address:     smartContract.Address,
+		Value:       new(big.Int).Set(smartContract.Value),
+		BlockNumber: smartContract.BlockNumber,
+		BlockHash:   smartContract.BlockHash,
+		Changed:     changed,
+		UpdatedAt:   smartContract.UpdatedAt,
+	}, nil
+}
 
-	err = r.db.QueryRow(*r.ctx, sql, args...).Scan(
-		&r.SmartContract.SmartContractId,
-		&r.SmartContract.Address,
-		&r.SmartContract.Value,
-		&r.SmartContract.CreatedAt,
-		&r.SmartContract.UpdatedAt,
+// scanSmartContract scans a row of the smartContractColumns.
+func (r *SmartContractRepositoryDB) scanSmartContract(row pgx.Row) (*SmartContractDB, error) {
+	var smartContract SmartContractDB
+	var value pgtype.Numeric
+	err := row.Scan(
+		&smartContract.SmartContractId,
+		&smartContract.Address,
+		&value,
+		&smartContract.BlockNumber,
+		&smartContract.BlockHash,
+		&smartContract.CreatedAt,
+		&smartContract.UpdatedAt,
 	)
 	if err != nil {
+		return nil, err
+	}
+	smartContract.Value = numericInt(value)
+	return &smartContract, nil
+}
+
+// SaveTransaction stores a transaction sent by the service.
+// Parameters:
+//   - ctx: The context of the caller, holding its span.
+//   - tx: The SentTransaction, pending.
+//
+// Returns:
+//   - domain.ErrConflictingData if the hash is already stored, or another error if the insert fails.
+func (r *SmartContractRepositoryDB) SaveTransaction(ctx context.Context, tx SentTransaction) error {
+	ctx = tracingConfig.Join(*r.ctx, ctx)
+	query := r.db.QueryBuilder.Insert("sent_transactions").
+		Columns("hash", "signer", "nonce", "method", "state").
+		Values(tx.Hash, tx.Signer, tx.Nonce, tx.Method, string(tx.State))
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.ErrorContext(ctx, "Error generating query sql to insert sent transaction on db", "error", err.Error())
+		return domain.ErrInvalidSQL
+	}
+	if _, err = r.db.Exec(ctx, sql, args...); err != nil {
 		if errCode := r.db.ErrorCode(err); errCode == "23505" {
-			slog.Error("Error creating or updating smart contract on db. Conflicts with columns requirements", "sql", sql, "error", err.Error())
+			slog.ErrorContext(ctx, "Error inserting sent transaction on db. Conflicts with columns requirements", "error", err.Error())
 			return domain.ErrConflictingData
 		}
-		slog.Error("Error creating or updating smart contract on db", "sql", sql, "error", err.Error())
+		slog.ErrorContext(ctx, "Error inserting sent transaction on db", "sql", sql, "error", err.Error())
+		return domain.ErrInternal
+	}
+	return nil
+}
+
+// UpdateTransactionState records the outcome of a transaction once mined or dropped.
+// Parameters:
+//   - ctx: The context of the caller, holding its span.
+//   - hash: The hash of the transaction.
+//   - state: TransactionMined, TransactionReverted or TransactionDropped.
+//   - blockNumber: The block the transaction was mined in, ignored when dropped.
+//
+// Returns:
+//   - domain.ErrDataNotFound if the transaction isn't stored, or another error if the update fails.
+func (r *SmartContractRepositoryDB) UpdateTransactionState(ctx context.Context, hash string, state TransactionState, blockNumber uint64) error {
+	ctx = tracingConfig.Join(*r.ctx, ctx)
+	var block any = blockNumber
+	if state == TransactionDropped {
+		block = nil
+	}
+	query := r.db.QueryBuilder.Update("sent_transactions").
+		Set("state", string(state)).
+		Set("block_number", block).
+		Set("updated_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"hash": hash})
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.ErrorContext(ctx, "Error generating query sql to update sent transaction on db", "error", err.Error())
+		return domain.ErrInvalidSQL
+	}
+	tag, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error updating sent transaction on db", "sql", sql, "error", err.Error())
 		return domain.ErrInternal
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDataNotFound
+	}
 	return nil
 }
+
+// ListPendingTransactions returns the transactions not known to be mined, oldest first.
+// Parameters:
+//   - ctx: The context of the caller, holding its span.
+//
+// Returns:
+//   - The pending SentTransaction list.
+//   - An error if the query fails.
+func (r *SmartContractRepositoryDB) ListPendingTransactions(ctx context.Context) ([]SentTransaction, error) {
+	ctx = tracingConfig.Join(*r.ctx, ctx)
+	query := r.db.QueryBuilder.
+		Select("hash", "signer", "nonce", "method", "state", "block_number", "created_at", "updated_at").
+		From("sent_transactions").
+		Where(sq.Eq{"state": string(TransactionPending)}).
+		OrderBy("created_at", "hash")
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.ErrorContext(ctx, "Error generating query sql to list pending transactions from db", "error", err.Error())
+		return nil, domain.ErrInvalidSQL
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error listing pending transactions from db", "sql", sql, "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	defer rows.Close()
+	txs := []SentTransaction{}
+	for rows.Next() {
+		var tx SentTransaction
+		var state string
+		var blockNumber *int64
+		if err = rows.Scan(&tx.Hash, &tx.Signer, &tx.Nonce, &tx.Method, &state, &blockNumber, &tx.CreatedAt, &tx.UpdatedAt); err != nil {
+			slog.ErrorContext(ctx, "Error scanning pending transaction from db", "error", err.Error())
+			return nil, domain.ErrInternal
+		}
+		tx.State = TransactionState(state)
+		if blockNumber != nil {
+			block := uint64(*blockNumber)
+			tx.BlockNumber = &block
+		}
+		txs = append(txs, tx)
+	}
+	if err = rows.Err(); err != nil {
+		slog.ErrorContext(ctx, "Error listing pending transactions from db", "error", err.Error())
+		return nil, domain.ErrInternal
+	}
+	return txs, nil
+}
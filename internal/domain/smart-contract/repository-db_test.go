@@ -0,0 +1,302 @@
+package smartContractDomain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"goledger-challenge-besu/configs/db"
+	"goledger-challenge-besu/configs/db/dbmock"
+	"goledger-challenge-besu/internal/domain"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const contractAddress = "0x42699A7612A82f1d9C36148af9C77354759b210b"
+
+var (
+	upsertSQL = "^" + regexp.QuoteMeta("INSERT INTO smart_contracts (address,value,block_number,block_hash) VALUES ($1,$2,$3,$4) "+
+		"ON CONFLICT (address) DO UPDATE SET value = EXCLUDED.value, block_number = EXCLUDED.block_number, block_hash = EXCLUDED.block_hash, updated_at = CURRENT_TIMESTAMP "+
+		"WHERE smart_contracts.block_number < EXCLUDED.block_number RETURNING smart_contract_id, address, value, block_number, block_hash, created_at, updated_at") + "$"
+	selectSQL = "^" + regexp.QuoteMeta("SELECT smart_contract_id, address, value, block_number, block_hash, created_at, updated_at FROM smart_contracts WHERE address = $1") + "$"
+	columns   = []string{"smart_contract_id", "address", "value", "block_number", "block_hash", "created_at", "updated_at"}
+	blockHash = "0x8a4a2b1f1e5bc0e8b8e1b1c35c9ac8a1a6ba0b7c0e1e8e0c2e5d3f8a9c0b1d2e"
+)
+
+// numericArg matches a NUMERIC argument holding value.
+func numericArg(value *big.Int) dbMock.Arg {
+	return func(actual any) bool {
+		numeric, ok := actual.(pgtype.Numeric)
+		return ok && numeric.Valid && numericInt(numeric).Cmp(value) == 0
+	}
+}
+
+func newRepositoryDB(t *testing.T, pool *dbMock.Pool) *SmartContractRepositoryDB {
+	t.Helper()
+	t.Setenv("SMART_CONTRACT_ADDR", contractAddress)
+	ctx := context.Background()
+	repository, err := NewRepositoryDB(&ctx, dbMock.NewDB(pool))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository
+}
+
+func TestRepositoryDBSyncValue(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := createdAt.Add(time.Hour)
+	uniqueViolation := &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+
+	tests := []struct {
+		name        string
+		value       *big.Int
+		expect      func(pool *dbMock.Pool, value *big.Int)
+		wantErr     error
+		wantValue   *big.Int
+		wantBlock   uint64
+		wantChanged bool
+	}{
+		{
+			name:  "inserts or updates the contract",
+			value: big.NewInt(42),
+			expect: func(pool *dbMock.Pool, value *big.Int) {
+				pool.ExpectQuery(upsertSQL).WithArgs(contractAddress, numericArg(value), uint64(10), blockHash).
+					WillReturnRows(columns, []any{int64(1), contractAddress, pgtype.Numeric{Int: big.NewInt(42), Valid: true}, int64(10), blockHash, createdAt, updatedAt})
+			},
+			wantValue: big.NewInt(42), wantBlock: 10, wantChanged: true,
+		},
+		{
+			name:  "max uint256",
+			value: math.MaxBig256,
+			expect: func(pool *dbMock.Pool, value *big.Int) {
+				pool.ExpectQuery(upsertSQL).WithArgs(contractAddress, numericArg(value), uint64(10), blockHash).
+					WillReturnRows(columns, []any{int64(7), contractAddress, pgtype.Numeric{Int: math.MaxBig256, Valid: true}, int64(10), blockHash, createdAt, updatedAt})
+			},
+			wantValue: math.MaxBig256, wantBlock: 10, wantChanged: true,
+		},
+		{
+			name:  "normalizes the returned numeric",
+			value: big.NewInt(42000),
+			expect: func(pool *dbMock.Pool, value *big.Int) {
+				pool.ExpectQuery(upsertSQL).WithArgs(contractAddress, numericArg(value), uint64(10), blockHash).
+					WillReturnRows(columns, []any{int64(7), contractAddress, pgtype.Numeric{Int: big.NewInt(42), Exp: 3, Valid: true}, int64(10), blockHash, createdAt, updatedAt})
+			},
+			wantValue: big.NewInt(42000), wantBlock: 10, wantChanged: true,
+		},
+		{
+			name:  "stale sync returns the newer stored value",
+			value: big.NewInt(1),
+			expect: func(pool *dbMock.Pool, value *big.Int) {
+				pool.ExpectQuery(upsertSQL).WithArgs(contractAddress, numericArg(value), uint64(10), blockHash)
+				pool.ExpectQuery(selectSQL).WithArgs(contractAddress).
+					WillReturnRows(columns, []any{int64(7), contractAddress, pgtype.Numeric{Int: big.NewInt(2), Valid: true}, int64(12), blockHash, createdAt, updatedAt})
+			},
+			wantValue: big.NewInt(2), wantBlock: 12,
+		},
+		{
+			name:  "unique violation",
+			value: big.NewInt(1),
+			expect: func(pool *dbMock.Pool, value *big.Int) {
+				pool.ExpectQuery(upsertSQL).WithArgs(contractAddress, numericArg(value), uint64(10), blockHash).WillReturnError(uniqueViolation)
+			},
+			wantErr: domain.ErrConflictingData,
+		},
+		{
+			name:  "other postgres error",
+			value: big.NewInt(1),
+			expect: func(pool *dbMock.Pool, value *big.Int) {
+				pool.ExpectQuery(upsertSQL).WithArgs(contractAddress, numericArg(value), uint64(10), blockHash).WillReturnError(&pgconn.PgError{Code: "23514"})
+			},
+			wantErr: domain.ErrInternal,
+		},
+		{
+			name:  "connection error",
+			value: big.NewInt(1),
+			expect: func(pool *dbMock.Pool, value *big.Int) {
+				pool.ExpectQuery(upsertSQL).WithArgs(contractAddress, numericArg(value), uint64(10), blockHash).WillReturnError(errors.New("connection reset by peer"))
+			},
+			wantErr: domain.ErrInternal,
+		},
+		{
+			name:  "select error after a stale sync",
+			value: big.NewInt(1),
+			expect: func(pool *dbMock.Pool, value *big.Int) {
+				pool.ExpectQuery(upsertSQL).WithArgs(contractAddress, numericArg(value), uint64(10), blockHash)
+				pool.ExpectQuery(selectSQL).WithArgs(contractAddress).WillReturnError(errors.New("connection refused"))
+			},
+			wantErr: domain.ErrInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := dbMock.New()
+			tt.expect(pool, tt.value)
+			repository := newRepositoryDB(t, pool)
+
+			result, err := repository.SyncValue(context.Background(), BlockValue{Value: tt.value, BlockNumber: 10, BlockHash: blockHash})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SyncValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := pool.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != nil {
+				if result != nil {
+					t.Errorf("SyncValue() = %+v after a failure, want nil", result)
+				}
+				return
+			}
+			if result.Value.Cmp(tt.wantValue) != 0 || result.BlockNumber != tt.wantBlock || result.BlockHash != blockHash || result.Changed != tt.wantChanged {
+				t.Errorf("SyncValue() = %+v, want value %v block %d changed %v", result, tt.wantValue, tt.wantBlock, tt.wantChanged)
+			}
+			if !result.UpdatedAt.Equal(updatedAt) {
+				t.Errorf("UpdatedAt = %v, want %v", result.UpdatedAt, updatedAt)
+			}
+		})
+	}
+}
+
+func TestNewRepositoryDBInvalidAddress(t *testing.T) {
+	t.Setenv("SMART_CONTRACT_ADDR", "0x42")
+	ctx := context.Background()
+	if _, err := NewRepositoryDB(&ctx, dbMock.NewDB(dbMock.New())); err == nil {
+		t.Error("NewRepositoryDB() with an invalid address, want an error")
+	}
+}
+
+// TestRepositoryDBSyncValueSQLite runs the queries of the repository on the
+// embedded SQLite backend, so their SQL is checked beyond the Postgres mock.
+func TestRepositoryDBSyncValueSQLite(t *testing.T) {
+	t.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "app.db"))
+	t.Setenv("SMART_CONTRACT_ADDR", contractAddress)
+	ctx := context.Background()
+	db, err := dbConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err = db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	repository, err := NewRepositoryDB(&ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syncs := []struct {
+		value       BlockValue
+		wantValue   *big.Int
+		wantBlock   uint64
+		wantChanged bool
+	}{
+		{BlockValue{big.NewInt(42), 10, blockHash}, big.NewInt(42), 10, true},
+		{BlockValue{math.MaxBig256, 11, blockHash}, math.MaxBig256, 11, true},
+		{BlockValue{big.NewInt(1), 5, blockHash}, math.MaxBig256, 11, false},  // stale
+		{BlockValue{big.NewInt(2), 11, blockHash}, math.MaxBig256, 11, false}, // same block
+		{BlockValue{big.NewInt(0), 12, blockHash}, big.NewInt(0), 12, true},
+	}
+	for _, tt := range syncs {
+		result, err := repository.SyncValue(context.Background(), tt.value)
+		if err != nil {
+			t.Fatalf("SyncValue(%v) error = %v", tt.value, err)
+		}
+		if result.Value.Cmp(tt.wantValue) != 0 || result.BlockNumber != tt.wantBlock || result.Changed != tt.wantChanged {
+			t.Errorf("SyncValue(%v at %d) = %v at %d changed %v, want %v at %d changed %v", tt.value.Value, tt.value.BlockNumber,
+				result.Value, result.BlockNumber, result.Changed, tt.wantValue, tt.wantBlock, tt.wantChanged)
+		}
+		if result.Address != contractAddress || result.UpdatedAt.IsZero() {
+			t.Errorf("SyncValue() = %+v, want the row of %s", result, contractAddress)
+		}
+	}
+
+	// concurrent syncs of different blocks end with the newest one
+	var wg sync.WaitGroup
+	for block := uint64(13); block < 33; block++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repository.SyncValue(context.Background(), BlockValue{new(big.Int).SetUint64(block), block, blockHash}); err != nil {
+				t.Errorf("SyncValue(block %d) error = %v", block, err)
+			}
+		}()
+	}
+	wg.Wait()
+	result, err := repository.SyncValue(context.Background(), BlockValue{big.NewInt(0), 0, blockHash})
+	if err != nil || result.Changed || result.BlockNumber != 32 || result.Value.Cmp(big.NewInt(32)) != 0 {
+		t.Errorf("after concurrent syncs = %+v, %v, want block 32 unchanged", result, err)
+	}
+}
+
+func TestRepositoryDBTransactionsSQLite(t *testing.T) {
+	t.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(t.TempDir(), "app.db"))
+	t.Setenv("SMART_CONTRACT_ADDR", contractAddress)
+	ctx := context.Background()
+	db, err := dbConfig.New(&ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err = db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	repository, err := NewRepositoryDB(&ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := SentTransaction{Hash: "0x01", Signer: "0xfe3b557e8fb62b89f4916b721be55ceb828dbd73", Nonce: 1, Method: "set(1)", State: TransactionPending}
+	second := SentTransaction{Hash: "0x02", Signer: first.Signer, Nonce: 2, Method: "set(2)", State: TransactionPending}
+	third := SentTransaction{Hash: "0x03", Signer: first.Signer, Nonce: 3, Method: "set(3)", State: TransactionPending}
+	for _, tx := range []SentTransaction{first, second, third} {
+		if err = repository.SaveTransaction(context.Background(), tx); err != nil {
+			t.Fatalf("SaveTransaction(%s) error = %v", tx.Hash, err)
+		}
+	}
+	// the statements are canceled by the context of the repository only: a transaction
+	// sent by a request that timed out meanwhile is still stored
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	fourth := SentTransaction{Hash: "0x04", Signer: first.Signer, Nonce: 4, Method: "set(4)", State: TransactionPending}
+	if err = repository.SaveTransaction(canceled, fourth); err != nil {
+		t.Fatalf("SaveTransaction() with the context of the caller canceled error = %v", err)
+	}
+	if err = repository.UpdateTransactionState(canceled, fourth.Hash, TransactionMined, 10); err != nil {
+		t.Fatalf("UpdateTransactionState() with the context of the caller canceled error = %v", err)
+	}
+	if err = repository.SaveTransaction(context.Background(), first); err != domain.ErrConflictingData {
+		t.Errorf("SaveTransaction() of a stored hash error = %v, want %v", err, domain.ErrConflictingData)
+	}
+
+	if err = repository.UpdateTransactionState(context.Background(), second.Hash, TransactionReverted, 12); err != nil {
+		t.Fatalf("UpdateTransactionState() error = %v", err)
+	}
+	if err = repository.UpdateTransactionState(context.Background(), third.Hash, TransactionDropped, 0); err != nil {
+		t.Fatalf("UpdateTransactionState() of a dropped transaction error = %v", err)
+	}
+	if err = repository.UpdateTransactionState(context.Background(), "0x05", TransactionMined, 12); err != domain.ErrDataNotFound {
+		t.Errorf("UpdateTransactionState() of a missing hash error = %v, want %v", err, domain.ErrDataNotFound)
+	}
+
+	pending, err := repository.ListPendingTransactions(context.Background())
+	if err != nil {
+		t.Fatalf("ListPendingTransactions() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].Hash != first.Hash || pending[0].Nonce != first.Nonce || pending[0].BlockNumber != nil || pending[0].CreatedAt.IsZero() {
+		t.Errorf("ListPendingTransactions() = %+v, want %s", pending, first.Hash)
+	}
+
+	if err = repository.UpdateTransactionState(context.Background(), first.Hash, TransactionMined, 11); err != nil {
+		t.Fatalf("UpdateTransactionState() error = %v", err)
+	}
+	if pending, err = repository.ListPendingTransactions(context.Background()); err != nil || len(pending) != 0 {
+		t.Errorf("ListPendingTransactions() = %+v, %v, want none", pending, err)
+	}
+}
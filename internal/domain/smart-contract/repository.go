@@ -0,0 +1,65 @@
+package smartContractDomain
+
+import (
+	"context"
+	"math/big"
+
+	"goledger-challenge-besu/configs/besu"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Backend is the part of the Besu client used by SmartContractRepositoryBesu: the
+// backend of the bound contracts plus the chain ID, the transactions lookup and the
+// raw rpc batches.
+// It is implemented by besuConfig.EthClient, and by the simulated backend in the tests.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	BatchCallContext(ctx context.Context, batch []rpc.BatchElem) error
+}
+
+// ValueReader reads the value stored in the smart contract on the chain.
+// The context of each method holds the span of the caller, the calls are traced under it.
+// It is implemented by SmartContractRepositoryBesu.
+type ValueReader interface {
+	GetValue(ctx context.Context) (*big.Int, error)
+	GetLatestValue(ctx context.Context) (*BlockValue, error)
+	GetValues(ctx context.Context, addresses []common.Address, block rpc.BlockNumber) ([]SmartContractValue, error)
+	CheckValue(ctx context.Context, value *big.Int) (bool, error)
+}
+
+// ValueWriter sends the transactions that change the value stored in the smart contract,
+// and waits for them to be mined.
+// It is implemented by SmartContractRepositoryBesu.
+type ValueWriter interface {
+	SendValue(ctx context.Context, value *big.Int, privateKey string) (*SentTransaction, error)
+	WaitTransaction(ctx context.Context, hash string) (TransactionState, uint64, error)
+}
+
+// ValueStore persists the values of the smart contract read at a block in the database.
+// It is implemented by SmartContractRepositoryDB.
+type ValueStore interface {
+	SyncValue(ctx context.Context, value BlockValue) (*SyncResult, error)
+}
+
+// TransactionStore persists the transactions sent by the service, until they are mined or dropped.
+// It is implemented by SmartContractRepositoryDB.
+type TransactionStore interface {
+	SaveTransaction(ctx context.Context, tx SentTransaction) error
+	UpdateTransactionState(ctx context.Context, hash string, state TransactionState, blockNumber uint64) error
+	ListPendingTransactions(ctx context.Context) ([]SentTransaction, error)
+}
+
+var (
+	_ Backend          = (*besuConfig.EthClient)(nil)
+	_ ValueReader      = (*SmartContractRepositoryBesu)(nil)
+	_ ValueWriter      = (*SmartContractRepositoryBesu)(nil)
+	_ ValueStore       = (*SmartContractRepositoryDB)(nil)
+	_ TransactionStore = (*SmartContractRepositoryDB)(nil)
+)